@@ -3,6 +3,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 
@@ -12,10 +13,14 @@ import (
 func main() {
 	if err := cli.Execute(); err != nil {
 		// Exit code 1 for audit/CI failures (expected failures)
+		// Exit code 130 when interrupted (Ctrl-C or SIGTERM)
 		// Exit code 2 for unexpected errors
 		if errors.Is(err, cli.ErrAuditFailed) || errors.Is(err, cli.ErrCICheckFailed) {
 			os.Exit(1)
 		}
+		if errors.Is(err, context.Canceled) {
+			os.Exit(130)
+		}
 		os.Exit(2)
 	}
 }
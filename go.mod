@@ -8,6 +8,8 @@ require (
 	github.com/pgvector/pgvector-go v0.2.2
 	github.com/qdrant/go-client v1.12.0
 	github.com/spf13/cobra v1.8.1
+	golang.org/x/net v0.28.0
+	golang.org/x/sync v0.10.0
 	google.golang.org/grpc v1.66.0
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -20,8 +22,6 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	golang.org/x/crypto v0.31.0 // indirect
-	golang.org/x/net v0.28.0 // indirect
-	golang.org/x/sync v0.10.0 // indirect
 	golang.org/x/sys v0.28.0 // indirect
 	golang.org/x/text v0.21.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240827150818-7e3bb234dfed // indirect
@@ -1,4 +1,5 @@
-// Package chunker provides simple text chunking for document ingestion.
+// Package chunker splits documents into chunks for ingestion. Each splitting
+// approach implements Strategy; Chunker is the original fixed-size splitter.
 package chunker
 
 import (
@@ -22,13 +23,51 @@ var (
 
 // Chunk represents a text chunk with metadata.
 type Chunk struct {
-	ID     string // Unique identifier (hash-based)
-	Text   string // The chunk text
-	Source string // Source document path
-	Index  int    // Chunk index within the document
+	ID      string // Unique identifier (hash-based)
+	Text    string // The chunk text
+	Source  string // Source document path
+	Index   int    // Chunk index within the document
+	Heading string // Heading path, e.g. "Auth > API Keys > Rotation" (markdown only)
+	Context string // Optional prefix embedded with the text but not stored as text
+	Symbol  string // Declared name, e.g. "(*Chunker).Chunk" (code only)
+
+	Enriched string                 // Full text to embed, set by Enrich; overrides Context
+	Metadata map[string]interface{} // Document metadata stored with the chunk
+
+	// Duplicates lists the sources of duplicate chunks merged into this one
+	Duplicates []string
+
+	// Parent-child hierarchy, set by Nest
+	ParentID   string // ID of the enclosing parent chunk
+	ParentText string // Text of the parent, returned instead of Text in parent mode
+
+	// Provenance, filled in by Annotate (the code strategy sets lines itself)
+	StartOffset int    // Byte offset of Text in the source document
+	EndOffset   int    // Byte offset just past Text
+	StartLine   int    // First line, 1-based (0 = unknown)
+	EndLine     int    // Last line, inclusive
+	Title       string // Document title
+	DocHash     string // SHA-256 of the document content
+	Params      Params // Chunker settings that produced the chunk
 }
 
-// Chunker splits text into overlapping chunks.
+// EmbedText returns the text to embed: the enriched text if set, otherwise
+// Context, if set, followed by Text.
+func (c Chunk) EmbedText() string {
+	if c.Enriched != "" {
+		return c.Enriched
+	}
+	if c.Context == "" {
+		return c.Text
+	}
+	return c.Context + "\n\n" + c.Text
+}
+
+// Compile-time interface compliance check.
+var _ Strategy = (*Chunker)(nil)
+
+// Chunker splits text into overlapping fixed-size character windows,
+// breaking at the last space in the window when possible.
 type Chunker struct {
 	size    int
 	overlap int
@@ -38,14 +77,9 @@ type Chunker struct {
 // Returns an error if size <= 0 or overlap < 0.
 // If overlap >= size, it is automatically clamped to size/4.
 func New(size, overlap int) (*Chunker, error) {
-	if size <= 0 {
-		return nil, ErrInvalidChunkSize
-	}
-	if overlap < 0 {
-		return nil, ErrNegativeOverlap
-	}
-	if overlap >= size {
-		overlap = size / 4
+	overlap, err := validateSize(size, overlap)
+	if err != nil {
+		return nil, err
 	}
 	return &Chunker{
 		size:    size,
@@ -63,18 +97,20 @@ func MustNew(size, overlap int) *Chunker {
 	return c
 }
 
+// Name returns the strategy name.
+func (c *Chunker) Name() string {
+	return StrategyFixed
+}
+
 // Chunk splits text into chunks and returns them with metadata.
 func (c *Chunker) Chunk(text, source string) []Chunk {
-	// Sanitize and normalize
-	text = sanitizeUTF8(text)
-	text = strings.TrimSpace(text)
+	text = normalizeText(text)
 	if len(text) == 0 {
 		return nil
 	}
 
-	var chunks []Chunk
+	var spans []span
 	start := 0
-	index := 0
 
 	for start < len(text) {
 		end := start + c.size
@@ -90,16 +126,7 @@ func (c *Chunker) Chunk(text, source string) []Chunk {
 			}
 		}
 
-		chunkText := strings.TrimSpace(text[start:end])
-		if len(chunkText) > 0 {
-			chunks = append(chunks, Chunk{
-				ID:     GenerateChunkID(source, index, chunkText),
-				Text:   chunkText,
-				Source: source,
-				Index:  index,
-			})
-			index++
-		}
+		spans = append(spans, span{start: start, end: end})
 
 		// Break if we've reached the end
 		if end >= len(text) {
@@ -115,7 +142,27 @@ func (c *Chunker) Chunk(text, source string) []Chunk {
 		start = start + step
 	}
 
-	return chunks
+	return makeChunks(text, source, spans)
+}
+
+// validateSize checks size and overlap and returns the overlap to use.
+// Overlap >= size is clamped to size/4.
+func validateSize(size, overlap int) (int, error) {
+	if size <= 0 {
+		return 0, ErrInvalidChunkSize
+	}
+	if overlap < 0 {
+		return 0, ErrNegativeOverlap
+	}
+	if overlap >= size {
+		overlap = size / 4
+	}
+	return overlap, nil
+}
+
+// normalizeText sanitizes text and trims surrounding whitespace.
+func normalizeText(text string) string {
+	return strings.TrimSpace(sanitizeUTF8(text))
 }
 
 // GenerateChunkID creates a deterministic UUID for a chunk based on source and content.
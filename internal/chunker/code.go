@@ -0,0 +1,494 @@
+package chunker
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Compile-time interface compliance check.
+var _ Strategy = (*CodeChunker)(nil)
+
+// blockStyle is how a language delimits top-level blocks.
+type blockStyle int
+
+const (
+	styleGo     blockStyle = iota // go/parser declarations
+	styleBraces                   // { ... } at depth 0
+	styleIndent                   // Lines starting at column 0
+)
+
+// codeLanguages maps source file extensions to their block style.
+var codeLanguages = map[string]blockStyle{
+	".go":    styleGo,
+	".c":     styleBraces,
+	".h":     styleBraces,
+	".cc":    styleBraces,
+	".cpp":   styleBraces,
+	".hpp":   styleBraces,
+	".cs":    styleBraces,
+	".java":  styleBraces,
+	".kt":    styleBraces,
+	".scala": styleBraces,
+	".swift": styleBraces,
+	".rs":    styleBraces,
+	".php":   styleBraces,
+	".dart":  styleBraces,
+	".js":    styleBraces,
+	".jsx":   styleBraces,
+	".mjs":   styleBraces,
+	".ts":    styleBraces,
+	".tsx":   styleBraces,
+	".py":    styleIndent,
+	".rb":    styleIndent,
+}
+
+// CodeExtensions lists the source file extensions the code chunker understands.
+func CodeExtensions() []string {
+	exts := make([]string, 0, len(codeLanguages))
+	for ext := range codeLanguages {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// IsCodeFile reports whether path has a source extension the code chunker understands.
+func IsCodeFile(path string) bool {
+	_, ok := codeLanguages[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// CodeChunker splits source files on top-level declarations. Go files are
+// parsed with go/parser; other languages use brace depth or indentation.
+// Each chunk records the declared symbol and its line range. A declaration
+// larger than size is split at line boundaries, with overlap, and each piece
+// keeps the symbol. Files in other formats are split like the recursive
+// strategy.
+type CodeChunker struct {
+	size    int
+	overlap int
+}
+
+// NewCodeChunker creates a CodeChunker.
+func NewCodeChunker(size, overlap int) (*CodeChunker, error) {
+	overlap, err := validateSize(size, overlap)
+	if err != nil {
+		return nil, err
+	}
+	return &CodeChunker{size: size, overlap: overlap}, nil
+}
+
+// Name returns the strategy name.
+func (c *CodeChunker) Name() string {
+	return StrategyCode
+}
+
+// codeBlock is a top-level declaration, or a run of code between declarations.
+type codeBlock struct {
+	span
+	symbol string
+}
+
+// Chunk splits text into chunks and returns them with metadata.
+func (c *CodeChunker) Chunk(text, source string) []Chunk {
+	// Keep leading whitespace so line numbers match the file
+	text = sanitizeUTF8(text)
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+
+	var blocks []codeBlock
+	style, ok := codeLanguages[strings.ToLower(filepath.Ext(source))]
+	switch {
+	case !ok:
+		units := splitRecursive(text, span{start: 0, end: len(text)}, c.size, []splitFunc{splitParagraphs, splitLines, splitWords})
+		for _, s := range packUnits(units, byteWeights(units), c.size, c.overlap) {
+			blocks = append(blocks, codeBlock{span: s})
+		}
+		return c.makeChunks(text, source, blocks)
+	case style == styleGo:
+		var err error
+		if blocks, err = goBlocks(text, source); err != nil {
+			// Fall back to braces for files that don't parse
+			blocks = braceBlocks(text)
+		}
+	case style == styleBraces:
+		blocks = braceBlocks(text)
+	default:
+		blocks = indentBlocks(text)
+	}
+
+	return c.makeChunks(text, source, c.fitBlocks(text, blocks))
+}
+
+// fitBlocks packs consecutive anonymous blocks (imports, statements) together
+// and splits blocks larger than size at line boundaries.
+func (c *CodeChunker) fitBlocks(text string, blocks []codeBlock) []codeBlock {
+	var out []codeBlock
+	for _, b := range blocks {
+		if n := len(out); n > 0 && b.symbol == "" && out[n-1].symbol == "" && b.end-out[n-1].start <= c.size {
+			out[n-1].end = b.end
+			continue
+		}
+		if b.end-b.start <= c.size {
+			out = append(out, b)
+			continue
+		}
+		lines := splitRecursive(text, b.span, c.size, []splitFunc{rawLines, splitWords})
+		for _, s := range packUnits(lines, byteWeights(lines), c.size, c.overlap) {
+			out = append(out, codeBlock{span: s, symbol: b.symbol})
+		}
+	}
+	return out
+}
+
+// makeChunks turns blocks into chunks with 1-based line ranges, trimming
+// blank lines at either end but keeping indentation.
+func (c *CodeChunker) makeChunks(text, source string, blocks []codeBlock) []Chunk {
+	var lineStarts []int
+	lineStarts = append(lineStarts, 0)
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	}
+
+	var chunks []Chunk
+	for _, b := range blocks {
+		start, end := b.start, b.end
+		for start < end && isSpaceByte(text[start]) {
+			start++
+		}
+		for end > start && isSpaceByte(text[end-1]) {
+			end--
+		}
+		if start == end {
+			continue
+		}
+		// Back up to the start of the line to keep indentation
+		for start > b.start && text[start-1] != '\n' {
+			start--
+		}
+
+		chunkText := text[start:end]
+		index := len(chunks)
+		chunks = append(chunks, Chunk{
+			ID:        GenerateChunkID(source, index, chunkText),
+			Text:      chunkText,
+			Source:    source,
+			Index:     index,
+			Symbol:    b.symbol,
+			StartLine: lineOf(start),
+			EndLine:   lineOf(end - 1),
+		})
+	}
+	return chunks
+}
+
+// goBlocks splits a Go file into its top-level declarations. Doc comments
+// and any code since the previous declaration belong to the declaration;
+// the package clause and imports form the first block.
+func goBlocks(text, source string) ([]codeBlock, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, source, text, parser.ParseComments|parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	file := fset.File(f.Pos())
+
+	var blocks []codeBlock
+	prevEnd := 0
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			prevEnd = file.Offset(gd.End())
+			continue
+		}
+		start := prevEnd
+		if len(blocks) == 0 {
+			start = file.Offset(declStart(decl))
+			blocks = append(blocks, codeBlock{span: span{start: 0, end: start}, symbol: "package " + f.Name.Name})
+		}
+		prevEnd = file.Offset(decl.End())
+		blocks = append(blocks, codeBlock{span: span{start: start, end: prevEnd}, symbol: declSymbol(decl)})
+	}
+	if len(blocks) == 0 {
+		return []codeBlock{{span: span{start: 0, end: len(text)}, symbol: "package " + f.Name.Name}}, nil
+	}
+	// Trailing comments belong to the last declaration
+	blocks[len(blocks)-1].end = len(text)
+	return blocks, nil
+}
+
+// declStart returns where a declaration begins, including its doc comment.
+func declStart(decl ast.Decl) token.Pos {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	case *ast.GenDecl:
+		if d.Doc != nil {
+			return d.Doc.Pos()
+		}
+	}
+	return decl.Pos()
+}
+
+// declSymbol names a Go declaration: "Name", "(*Recv).Name", or the names
+// declared by a type, var or const group.
+func declSymbol(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil || len(d.Recv.List) == 0 {
+			return d.Name.Name
+		}
+		return fmt.Sprintf("(%s).%s", recvType(d.Recv.List[0].Type), d.Name.Name)
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, n := range s.Names {
+					names = append(names, n.Name)
+				}
+			}
+		}
+		if len(names) > 3 {
+			names = append(names[:3], "...")
+		}
+		return strings.Join(names, ", ")
+	}
+	return ""
+}
+
+// recvType renders a method receiver type such as "*Chunker" or "List[T]".
+func recvType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return "*" + recvType(t.X)
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return recvType(t.X) + "[" + recvType(t.Index) + "]"
+	case *ast.IndexListExpr:
+		var params []string
+		for _, p := range t.Indices {
+			params = append(params, recvType(p))
+		}
+		return recvType(t.X) + "[" + strings.Join(params, ", ") + "]"
+	}
+	return "?"
+}
+
+// braceBlocks splits brace-delimited code into top-level blocks. A block
+// ends where brace depth returns to zero, or at a blank line at depth zero,
+// so comments directly above a declaration stay with it. Braces inside
+// strings and comments are ignored.
+func braceBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	lines := rawLines(text, span{start: 0, end: len(text)})
+
+	depth := 0
+	inBlockComment := false
+	start := 0
+	for _, l := range lines {
+		line := text[l.start:l.end]
+		opened := false
+		var quote byte
+		for i := 0; i < len(line); i++ {
+			ch := line[i]
+			switch {
+			case inBlockComment:
+				if ch == '*' && i+1 < len(line) && line[i+1] == '/' {
+					inBlockComment = false
+					i++
+				}
+			case quote != 0:
+				if ch == '\\' {
+					i++
+				} else if ch == quote {
+					quote = 0
+				}
+			case ch == '/' && i+1 < len(line) && line[i+1] == '/':
+				i = len(line)
+			case ch == '/' && i+1 < len(line) && line[i+1] == '*':
+				inBlockComment = true
+				i++
+			case ch == '"' || ch == '`':
+				quote = ch
+			case ch == '\'':
+				// Character literal, unless it is a Rust lifetime like 'a
+				if end := strings.IndexByte(line[i+1:min(len(line), i+5)], '\''); end > 0 {
+					i += end + 1
+				}
+			case ch == '{':
+				depth++
+				opened = true
+			case ch == '}':
+				depth = max(0, depth-1)
+				opened = true
+			}
+		}
+		if depth == 0 && !inBlockComment && (opened || strings.TrimSpace(line) == "") {
+			blocks = append(blocks, codeBlock{span: span{start: start, end: l.end}})
+			start = l.end
+		}
+	}
+	if start < len(text) {
+		blocks = append(blocks, codeBlock{span: span{start: start, end: len(text)}})
+	}
+	return nameBlocks(text, blocks)
+}
+
+// indentBlocks splits indentation-structured code (Python, Ruby) into blocks
+// that start at column zero. Comments and decorators directly above a
+// definition stay with it, as do closing lines like "end" or ")".
+func indentBlocks(text string) []codeBlock {
+	var blocks []codeBlock
+	lines := rawLines(text, span{start: 0, end: len(text)})
+
+	start := 0
+	header := true // Block so far holds only comments, decorators and blank lines
+	for _, l := range lines {
+		line := text[l.start:l.end]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		topLevel := !isSpaceByte(line[0])
+		isHeader := strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "@")
+		closing := trimmed == "end" || strings.HasPrefix(trimmed, ")") || strings.HasPrefix(trimmed, "]") || strings.HasPrefix(trimmed, "}")
+
+		if topLevel && !closing && !header && l.start > start {
+			blocks = append(blocks, codeBlock{span: span{start: start, end: l.start}})
+			start = l.start
+			header = true
+		}
+		if !isHeader {
+			header = false
+		}
+	}
+	if start < len(text) {
+		blocks = append(blocks, codeBlock{span: span{start: start, end: len(text)}})
+	}
+	return nameBlocks(text, blocks)
+}
+
+// rawLines splits s into lines, each ending after its newline. Unlike
+// splitLines it leaves indentation with the line it belongs to.
+func rawLines(text string, s span) []span {
+	var out []span
+	start := s.start
+	for i := s.start; i < s.end; i++ {
+		if text[i] == '\n' {
+			out = append(out, span{start: start, end: i + 1})
+			start = i + 1
+		}
+	}
+	if start < s.end {
+		out = append(out, span{start: start, end: s.end})
+	}
+	return out
+}
+
+// symbolKeywords introduce a named definition in common languages.
+var symbolKeywords = map[string]bool{
+	"func": true, "function": true, "def": true, "fn": true, "class": true,
+	"struct": true, "interface": true, "enum": true, "trait": true, "impl": true,
+	"type": true, "module": true, "object": true, "record": true, "namespace": true,
+	"union": true, "protocol": true, "extension": true,
+	"const": true, "let": true, "var": true, "val": true,
+}
+
+// bindingKeywords name a definition only when followed by "name =".
+var bindingKeywords = map[string]bool{"const": true, "let": true, "var": true, "val": true}
+
+// controlKeywords look like calls ("if (") but never name a definition.
+var controlKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "return": true,
+	"catch": true, "else": true, "do": true, "sizeof": true, "new": true,
+}
+
+// nameBlocks sets each block's symbol from its first code line.
+func nameBlocks(text string, blocks []codeBlock) []codeBlock {
+	for i := range blocks {
+		for _, l := range rawLines(text, blocks[i].span) {
+			trimmed := strings.TrimSpace(text[l.start:l.end])
+			if trimmed == "" || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, "#") ||
+				strings.HasPrefix(trimmed, "/*") || strings.HasPrefix(trimmed, "*") || strings.HasPrefix(trimmed, "@") {
+				continue
+			}
+			blocks[i].symbol = lineSymbol(trimmed)
+			break
+		}
+	}
+	return blocks
+}
+
+// lineSymbol guesses the name defined on a line of code: the identifier
+// after a definition keyword, or the identifier before the first "(".
+func lineSymbol(line string) string {
+	words := identifiers(line)
+	for i, w := range words {
+		if !symbolKeywords[w.name] || i+1 >= len(words) {
+			continue
+		}
+		name := words[i+1]
+		if bindingKeywords[w.name] && !strings.HasPrefix(strings.TrimSpace(line[name.end:]), "=") {
+			// "const char *f(" is a C function, not a binding
+			continue
+		}
+		return name.name
+	}
+	paren := strings.IndexByte(line, '(')
+	if paren < 0 || strings.Contains(line[:paren], "=") {
+		return ""
+	}
+	var last string
+	for _, w := range words {
+		if w.end > paren {
+			break
+		}
+		if controlKeywords[w.name] {
+			return ""
+		}
+		last = w.name
+	}
+	if strings.TrimSpace(line[:paren]) == "" || !strings.HasSuffix(strings.TrimSpace(line[:paren]), last) {
+		return ""
+	}
+	return last
+}
+
+// ident is an identifier and the byte offset just past it.
+type ident struct {
+	name string
+	end  int
+}
+
+// identifiers returns the identifiers on a line, in order.
+func identifiers(line string) []ident {
+	var out []ident
+	start := -1
+	for i, r := range line + " " {
+		isIdent := r == '_' || r == '$' || unicode.IsLetter(r) || (start >= 0 && unicode.IsDigit(r))
+		if isIdent && start < 0 {
+			start = i
+		} else if !isIdent && start >= 0 {
+			out = append(out, ident{name: line[start:i], end: i})
+			start = -1
+		}
+	}
+	return out
+}
@@ -0,0 +1,182 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+const goSource = `// Package demo is a test fixture.
+package demo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Greeter says hello.
+type Greeter struct {
+	name string
+}
+
+// Greet returns a greeting.
+func (g *Greeter) Greet() string {
+	return fmt.Sprintf("hello, %s", strings.ToUpper(g.name))
+}
+
+const (
+	A = 1
+	B = 2
+)
+
+func helper() {}
+`
+
+func TestCodeChunker_GoDeclarations(t *testing.T) {
+	c, _ := NewCodeChunker(1000, 0)
+	chunks := c.Chunk(goSource, "demo/greeter.go")
+
+	want := []struct {
+		symbol     string
+		start, end int
+		prefix     string
+	}{
+		{"package demo", 1, 7, "// Package demo"},
+		{"Greeter", 9, 12, "// Greeter says hello."},
+		{"(*Greeter).Greet", 14, 17, "// Greet returns"},
+		{"A, B", 19, 22, "const ("},
+		{"helper", 24, 24, "func helper"},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %+v", len(want), len(chunks), chunks)
+	}
+	for i, w := range want {
+		ch := chunks[i]
+		if ch.Symbol != w.symbol || ch.StartLine != w.start || ch.EndLine != w.end {
+			t.Errorf("chunk %d = %q lines %d-%d, want %q lines %d-%d", i, ch.Symbol, ch.StartLine, ch.EndLine, w.symbol, w.start, w.end)
+		}
+		if !strings.HasPrefix(ch.Text, w.prefix) {
+			t.Errorf("chunk %d text = %q, want prefix %q", i, ch.Text, w.prefix)
+		}
+	}
+}
+
+func TestCodeChunker_LargeDeclarationSplitsAtLines(t *testing.T) {
+	var body strings.Builder
+	body.WriteString("package big\n\nfunc Long() {\n")
+	for range 20 {
+		body.WriteString("\tprintln(\"a line of code\")\n")
+	}
+	body.WriteString("}\n")
+
+	c, _ := NewCodeChunker(120, 0)
+	chunks := c.Chunk(body.String(), "big.go")
+	if len(chunks) < 3 {
+		t.Fatalf("expected the function to be split, got %d chunks", len(chunks))
+	}
+	prevEnd := 0
+	for i, ch := range chunks[1:] {
+		if ch.Symbol != "Long" {
+			t.Errorf("piece %d symbol = %q, want Long", i, ch.Symbol)
+		}
+		if len(ch.Text) > 120 {
+			t.Errorf("piece %d has %d chars", i, len(ch.Text))
+		}
+		if ch.StartLine <= prevEnd {
+			t.Errorf("piece %d starts at line %d, previous ended at %d", i, ch.StartLine, prevEnd)
+		}
+		prevEnd = ch.EndLine
+	}
+}
+
+func TestCodeChunker_Braces(t *testing.T) {
+	src := `import { x } from "./x";
+
+// Adds numbers.
+export function add(a, b) {
+  if (a) { return a + b; }
+  return "}" + b;
+}
+
+class Stack {
+  push(v) { this.items.push(v); }
+}
+
+const double = (n) => {
+  return n * 2;
+};
+`
+	c, _ := NewCodeChunker(500, 0)
+	chunks := c.Chunk(src, "math.js")
+
+	var symbols []string
+	for _, ch := range chunks {
+		symbols = append(symbols, ch.Symbol)
+	}
+	want := []string{"", "add", "Stack", "double"}
+	if strings.Join(symbols, "|") != strings.Join(want, "|") {
+		t.Fatalf("symbols = %q, want %q", symbols, want)
+	}
+	if chunks[1].StartLine != 3 || chunks[1].EndLine != 7 {
+		t.Errorf("add spans lines %d-%d, want 3-7", chunks[1].StartLine, chunks[1].EndLine)
+	}
+}
+
+func TestCodeChunker_Indent(t *testing.T) {
+	src := `import os
+
+# Loads config.
+@cache
+def load(path):
+    with open(path) as f:
+
+        return f.read()
+
+class Config:
+    def get(self, key):
+        return key
+`
+	c, _ := NewCodeChunker(500, 0)
+	chunks := c.Chunk(src, "config.py")
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[1].Symbol != "load" || !strings.HasPrefix(chunks[1].Text, "# Loads config.") {
+		t.Errorf("chunk 1 = %q %q, want load with its comment and decorator", chunks[1].Symbol, chunks[1].Text)
+	}
+	if !strings.Contains(chunks[1].Text, "return f.read()") {
+		t.Error("blank line inside a function should not end the block")
+	}
+	if chunks[2].Symbol != "Config" || chunks[2].StartLine != 10 {
+		t.Errorf("chunk 2 = %q at line %d, want Config at line 10", chunks[2].Symbol, chunks[2].StartLine)
+	}
+}
+
+func TestCodeChunker_NonCodeFallsBack(t *testing.T) {
+	c, _ := NewCodeChunker(120, 0)
+	chunks := c.Chunk(sampleDoc, "keys.md")
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	if chunks[0].StartLine != 1 || chunks[0].Symbol != "" {
+		t.Errorf("unexpected first chunk: %+v", chunks[0])
+	}
+}
+
+func TestLineSymbol(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"fn parse<'a>(input: &'a str) -> Result {", "parse"},
+		{"static int count_words(const char *s) {", "count_words"},
+		{"const char *name(void) {", "name"},
+		{"public class Router {", "Router"},
+		{"if (ready) {", ""},
+		{"x = compute(1);", ""},
+	}
+	for _, tt := range tests {
+		if got := lineSymbol(tt.line); got != tt.want {
+			t.Errorf("lineSymbol(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
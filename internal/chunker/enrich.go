@@ -0,0 +1,99 @@
+package chunker
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Template fields available to enrichment templates.
+var templateFields = map[string]func(Chunk) string{
+	"title":        func(c Chunk) string { return c.Title },
+	"heading_path": func(c Chunk) string { return c.Heading },
+	"source":       func(c Chunk) string { return c.Source },
+	"symbol":       func(c Chunk) string { return c.Symbol },
+	"text":         func(c Chunk) string { return c.Text },
+}
+
+// ErrTemplateNoText is returned for enrichment templates without {{text}}.
+var ErrTemplateNoText = errors.New("enrichment template must contain {{text}}")
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-z_]+)\s*\}\}`)
+
+// separatorCutset is trimmed from header lines whose fields rendered empty,
+// so "{{title}} — {{heading_path}}" without a heading becomes just the title.
+const separatorCutset = " \t—–-|:>/"
+
+// Template renders the text embedded for a chunk from its document context,
+// e.g. "{{title}} — {{heading_path}}\n{{text}}". The stored text is unchanged.
+type Template struct {
+	raw   string
+	lines []string
+}
+
+// ParseTemplate parses an enrichment template. Fields are {{title}},
+// {{heading_path}}, {{source}}, {{symbol}} and {{text}}; {{text}} is
+// required. The two characters \n are read as a newline, so templates can
+// be passed as a single command-line argument.
+func ParseTemplate(s string) (*Template, error) {
+	s = strings.ReplaceAll(s, `\n`, "\n")
+	hasText := false
+	for _, m := range placeholderRe.FindAllStringSubmatch(s, -1) {
+		if _, ok := templateFields[m[1]]; !ok {
+			return nil, fmt.Errorf("unknown template field {{%s}}", m[1])
+		}
+		if m[1] == "text" {
+			hasText = true
+		}
+	}
+	if !hasText {
+		return nil, ErrTemplateNoText
+	}
+	return &Template{raw: s, lines: strings.Split(s, "\n")}, nil
+}
+
+// String returns the template source.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// Render returns the text to embed for c. A header line (one without
+// {{text}}) whose fields are all empty is dropped, and separators left
+// dangling by an empty field are trimmed.
+func (t *Template) Render(c Chunk) string {
+	var out []string
+	for _, line := range t.lines {
+		hasText, filled, fields := false, 0, 0
+		rendered := placeholderRe.ReplaceAllStringFunc(line, func(m string) string {
+			name := placeholderRe.FindStringSubmatch(m)[1]
+			value := templateFields[name](c)
+			if name == "text" {
+				hasText = true
+			}
+			fields++
+			if value != "" {
+				filled++
+			}
+			return value
+		})
+		if !hasText && fields > 0 {
+			if filled == 0 {
+				continue
+			}
+			if filled < fields {
+				rendered = strings.Trim(rendered, separatorCutset)
+			}
+		}
+		out = append(out, rendered)
+	}
+	return strings.Join(out, "\n")
+}
+
+// Enrich sets the embedded text of each chunk to t rendered for it. Chunks
+// must already carry their title (see Annotate).
+func Enrich(chunks []Chunk, t *Template) {
+	for i := range chunks {
+		chunks[i].Enriched = t.Render(chunks[i])
+	}
+}
@@ -0,0 +1,56 @@
+package chunker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{title}} — {{heading_path}}\n{{text}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		chunk Chunk
+		want  string
+	}{
+		{"all fields", Chunk{Title: "Auth", Heading: "Keys > Rotation", Text: "Rotate keys."}, "Auth — Keys > Rotation\nRotate keys."},
+		{"no heading", Chunk{Title: "Auth", Text: "Rotate keys."}, "Auth\nRotate keys."},
+		{"no title", Chunk{Heading: "Keys", Text: "Rotate keys."}, "Keys\nRotate keys."},
+		{"no header", Chunk{Text: "Rotate keys."}, "Rotate keys."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tmpl.Render(tt.chunk); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	if _, err := ParseTemplate("{{title}}"); !errors.Is(err, ErrTemplateNoText) {
+		t.Errorf("expected ErrTemplateNoText, got %v", err)
+	}
+	if _, err := ParseTemplate("{{author}}\n{{text}}"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestEnrich(t *testing.T) {
+	tmpl, err := ParseTemplate("File: {{source}}\n{{ text }}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := []Chunk{{Text: "body", Source: "a.md", Context: "Heading"}}
+	Enrich(chunks, tmpl)
+
+	if got := chunks[0].EmbedText(); got != "File: a.md\nbody" {
+		t.Errorf("EmbedText() = %q", got)
+	}
+	if chunks[0].Text != "body" {
+		t.Errorf("Enrich should not change the stored text, got %q", chunks[0].Text)
+	}
+}
@@ -0,0 +1,245 @@
+package chunker
+
+import "strings"
+
+// Compile-time interface compliance check.
+var _ Strategy = (*MarkdownChunker)(nil)
+
+// headingSeparator joins heading titles into a breadcrumb.
+const headingSeparator = " > "
+
+// MarkdownChunker splits markdown along its heading hierarchy. Chunks never
+// span two sections, and fenced code blocks and tables are never split
+// (an oversized block becomes a chunk of its own). Other text is packed up
+// to size characters, falling back to line, sentence and word boundaries.
+type MarkdownChunker struct {
+	size       int
+	overlap    int
+	breadcrumb bool
+}
+
+// NewMarkdownChunker creates a MarkdownChunker. With breadcrumb set, each
+// chunk's heading path is prepended to the text that gets embedded.
+func NewMarkdownChunker(size, overlap int, breadcrumb bool) (*MarkdownChunker, error) {
+	overlap, err := validateSize(size, overlap)
+	if err != nil {
+		return nil, err
+	}
+	return &MarkdownChunker{size: size, overlap: overlap, breadcrumb: breadcrumb}, nil
+}
+
+// Name returns the strategy name.
+func (c *MarkdownChunker) Name() string {
+	return StrategyMarkdown
+}
+
+// Chunk splits text into chunks and returns them with metadata.
+func (c *MarkdownChunker) Chunk(text, source string) []Chunk {
+	text = normalizeText(text)
+	if len(text) == 0 {
+		return nil
+	}
+
+	var spans []span
+	var headings []string
+	for _, sec := range parseMarkdown(text) {
+		var units []span
+		for _, b := range sec.blocks {
+			if b.atomic {
+				units = append(units, b.span)
+				continue
+			}
+			units = append(units, splitRecursive(text, b.span, c.size, []splitFunc{splitLines, splitSentences, splitWords})...)
+		}
+		if len(units) == 0 {
+			continue
+		}
+		for _, s := range packUnits(units, byteWeights(units), c.size, c.overlap) {
+			spans = append(spans, s)
+			headings = append(headings, sec.heading)
+		}
+	}
+
+	var chunks []Chunk
+	for i, s := range spans {
+		chunkText := strings.TrimSpace(text[s.start:s.end])
+		if len(chunkText) == 0 {
+			continue
+		}
+		index := len(chunks)
+		ch := Chunk{
+			ID:      GenerateChunkID(source, index, chunkText),
+			Text:    chunkText,
+			Source:  source,
+			Index:   index,
+			Heading: headings[i],
+		}
+		if c.breadcrumb {
+			ch.Context = ch.Heading
+		}
+		chunks = append(chunks, ch)
+	}
+	return chunks
+}
+
+// mdSection is the text under one heading, up to the next heading of any level.
+type mdSection struct {
+	heading string // Breadcrumb of the enclosing headings, including this one
+	blocks  []mdBlock
+}
+
+// mdBlock is a run of lines; atomic blocks (code fences, tables) are never split.
+type mdBlock struct {
+	span
+	atomic bool
+}
+
+// parseMarkdown splits text into sections at ATX headings ("# Title") outside
+// code fences, and each section into paragraph, code fence and table blocks.
+func parseMarkdown(text string) []mdSection {
+	lines := splitLines(text, span{start: 0, end: len(text)})
+
+	var sections []mdSection
+	var stack []string // heading titles by level - 1
+	cur := mdSection{}
+
+	var para *mdBlock // open paragraph block
+	flushPara := func() {
+		if para != nil {
+			cur.blocks = append(cur.blocks, *para)
+			para = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := text[lines[i].start:lines[i].end]
+		trimmed := strings.TrimSpace(line)
+
+		if marker := fenceMarker(line); marker != "" {
+			// Consume through the closing fence (or end of document)
+			flushPara()
+			start := lines[i].start
+			j := i + 1
+			for j < len(lines) && !closesFence(text[lines[j].start:lines[j].end], marker) {
+				j++
+			}
+			j = min(j, len(lines)-1)
+			cur.blocks = append(cur.blocks, mdBlock{span: span{start: start, end: lines[j].end}, atomic: true})
+			i = j
+			continue
+		}
+
+		if level, title := parseHeading(trimmed); level > 0 {
+			flushPara()
+			if len(cur.blocks) > 0 {
+				sections = append(sections, cur)
+			}
+			if level > len(stack) {
+				for len(stack) < level-1 {
+					stack = append(stack, "")
+				}
+				stack = append(stack, title)
+			} else {
+				stack = append(stack[:level-1], title)
+			}
+			cur = mdSection{heading: joinHeadings(stack)}
+			// The heading line leads the section's first chunk
+			para = &mdBlock{span: lines[i]}
+			continue
+		}
+
+		if strings.Contains(trimmed, "|") && i+1 < len(lines) && isTableSeparator(text[lines[i+1].start:lines[i+1].end]) {
+			flushPara()
+			start := lines[i].start
+			j := i + 1
+			for j+1 < len(lines) && strings.Contains(text[lines[j+1].start:lines[j+1].end], "|") &&
+				strings.TrimSpace(text[lines[j+1].start:lines[j+1].end]) != "" {
+				j++
+			}
+			cur.blocks = append(cur.blocks, mdBlock{span: span{start: start, end: lines[j].end}, atomic: true})
+			i = j
+			continue
+		}
+
+		if para == nil {
+			para = &mdBlock{span: lines[i]}
+		} else {
+			para.end = lines[i].end
+		}
+		// splitLines absorbs blank lines into the preceding line, so a
+		// blank-line run ends the paragraph
+		if strings.Count(line, "\n") > 1 {
+			flushPara()
+		}
+	}
+	flushPara()
+	if len(cur.blocks) > 0 {
+		sections = append(sections, cur)
+	}
+	return sections
+}
+
+// parseHeading returns the level and title of an ATX heading line, or 0.
+func parseHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, ""
+	}
+	title := strings.TrimSpace(line[level:])
+	title = strings.TrimSpace(strings.TrimRight(title, "#"))
+	return level, title
+}
+
+// joinHeadings joins non-empty heading titles into a breadcrumb.
+func joinHeadings(stack []string) string {
+	var parts []string
+	for _, h := range stack {
+		if h != "" {
+			parts = append(parts, h)
+		}
+	}
+	return strings.Join(parts, headingSeparator)
+}
+
+// fenceMarker returns the opening fence ("```", "~~~~", ...) if line opens a code fence.
+func fenceMarker(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return ""
+	}
+	ch := trimmed[0]
+	if ch != '`' && ch != '~' {
+		return ""
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == ch {
+		n++
+	}
+	if n < 3 {
+		return ""
+	}
+	return trimmed[:n]
+}
+
+// closesFence reports whether line closes a fence opened with marker.
+func closesFence(line, marker string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, marker) && strings.Trim(trimmed, marker[:1]) == ""
+}
+
+// isTableSeparator reports whether line is a table header separator like "|---|:--:|".
+func isTableSeparator(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if !strings.Contains(trimmed, "-") {
+		return false
+	}
+	for _, r := range trimmed {
+		if r != '|' && r != '-' && r != ':' && r != ' ' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+const markdownDoc = "# Auth\n\n" +
+	"Authentication overview for the API.\n\n" +
+	"## API Keys\n\n" +
+	"Keys identify a project.\n\n" +
+	"### Rotation\n\n" +
+	"Rotate keys every 90 days. Old keys stay valid for a day.\n\n" +
+	"```bash\n" +
+	"# not a heading\n" +
+	"ragtune keys rotate --project demo\n" +
+	"ragtune keys list --project demo\n" +
+	"```\n\n" +
+	"## Limits\n\n" +
+	"| Plan | Requests |\n" +
+	"|------|----------|\n" +
+	"| Free | 100 |\n" +
+	"| Pro | 10000 |\n"
+
+func TestMarkdownChunker_HeadingPaths(t *testing.T) {
+	c, _ := NewMarkdownChunker(200, 0, false)
+	chunks := c.Chunk(markdownDoc, "auth.md")
+
+	want := []string{"Auth", "Auth > API Keys", "Auth > API Keys > Rotation", "Auth > Limits"}
+	if len(chunks) != len(want) {
+		t.Fatalf("expected %d chunks, got %d: %+v", len(want), len(chunks), chunks)
+	}
+	for i, ch := range chunks {
+		if ch.Heading != want[i] {
+			t.Errorf("chunk %d heading = %q, want %q", i, ch.Heading, want[i])
+		}
+		if ch.Index != i {
+			t.Errorf("chunk %d index = %d", i, ch.Index)
+		}
+		if ch.Context != "" {
+			t.Errorf("chunk %d has context %q without breadcrumb", i, ch.Context)
+		}
+	}
+}
+
+func TestMarkdownChunker_NeverSplitsCodeOrTables(t *testing.T) {
+	// Size smaller than the code fence and table forces them into their own chunks
+	c, _ := NewMarkdownChunker(40, 0, false)
+	chunks := c.Chunk(markdownDoc, "auth.md")
+
+	var fence, table *Chunk
+	for i := range chunks {
+		text := chunks[i].Text
+		if strings.Contains(text, "```") {
+			if strings.Count(text, "```") != 2 {
+				t.Errorf("code fence split across chunks: %q", text)
+			}
+			fence = &chunks[i]
+		}
+		if strings.Contains(text, "|") {
+			if !strings.Contains(text, "| Plan") || !strings.Contains(text, "| Pro") {
+				t.Errorf("table split across chunks: %q", text)
+			}
+			table = &chunks[i]
+		}
+	}
+	if fence == nil || table == nil {
+		t.Fatalf("expected a code chunk and a table chunk, got %+v", chunks)
+	}
+	if fence.Heading != "Auth > API Keys > Rotation" {
+		t.Errorf("'# not a heading' inside the fence changed the heading path: %q", fence.Heading)
+	}
+}
+
+func TestMarkdownChunker_Breadcrumb(t *testing.T) {
+	c, _ := NewMarkdownChunker(200, 0, true)
+	chunks := c.Chunk(markdownDoc, "auth.md")
+
+	rotation := chunks[2]
+	if rotation.Context != "Auth > API Keys > Rotation" {
+		t.Errorf("Context = %q, want heading path", rotation.Context)
+	}
+	if !strings.HasPrefix(rotation.EmbedText(), "Auth > API Keys > Rotation\n\n### Rotation") {
+		t.Errorf("EmbedText() = %q, want breadcrumb prefix", rotation.EmbedText())
+	}
+	if strings.Contains(rotation.Text, "Auth > API Keys") {
+		t.Error("breadcrumb should not be stored in Text")
+	}
+}
+
+func TestMarkdownChunker_TextBeforeFirstHeading(t *testing.T) {
+	c, _ := NewMarkdownChunker(200, 0, false)
+	chunks := c.Chunk("Intro text.\n\n# Title\n\nBody.", "doc.md")
+
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+	if chunks[0].Heading != "" || chunks[1].Heading != "Title" {
+		t.Errorf("headings = %q, %q; want \"\", \"Title\"", chunks[0].Heading, chunks[1].Heading)
+	}
+}
+
+func TestParseHeading(t *testing.T) {
+	tests := []struct {
+		line  string
+		level int
+		title string
+	}{
+		{"# Title", 1, "Title"},
+		{"### Deep ###", 3, "Deep"},
+		{"#hashtag", 0, ""},
+		{"####### seven", 0, ""},
+		{"plain", 0, ""},
+	}
+
+	for _, tt := range tests {
+		level, title := parseHeading(tt.line)
+		if level != tt.level || title != tt.title {
+			t.Errorf("parseHeading(%q) = %d, %q; want %d, %q", tt.line, level, title, tt.level, tt.title)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package chunker
+
+import "strings"
+
+// Nest makes children, split from parent's text, the embedded chunks of a
+// two-level hierarchy. Children are renumbered from firstIndex (their
+// position among the document's chunks), get matching IDs, and record the
+// parent's ID and text. Children without a heading inherit the parent's
+// heading and breadcrumb, since the child text rarely contains the heading;
+// a child's own heading is nested under the parent's.
+func Nest(parent Chunk, children []Chunk, firstIndex int) []Chunk {
+	parentID := ParentID(parent.Source, parent.Index, parent.Text)
+	out := make([]Chunk, len(children))
+	for i, ch := range children {
+		ch.Index = firstIndex + i
+		ch.ID = GenerateChunkID(ch.Source, ch.Index, ch.Text)
+		ch.ParentID = parentID
+		ch.ParentText = parent.Text
+		if ch.Heading == "" {
+			ch.Heading = parent.Heading
+			ch.Context = parent.Context
+		} else if parent.Heading != "" {
+			ch.Heading = nestHeading(parent.Heading, ch.Heading)
+			if parent.Context != "" {
+				ch.Context = ch.Heading
+			}
+		}
+		if ch.Symbol == "" {
+			ch.Symbol = parent.Symbol
+		}
+		out[i] = ch
+	}
+	return out
+}
+
+// nestHeading returns the heading path of a child headed inner within a
+// parent headed outer. The child parses only the parent's text, so its path
+// is relative and may repeat the parent's own heading.
+func nestHeading(outer, inner string) string {
+	if outer == inner || strings.HasSuffix(outer, headingSeparator+inner) {
+		return outer
+	}
+	return outer + headingSeparator + inner
+}
+
+// ParentID returns the ID of a parent chunk. It is derived like
+// GenerateChunkID but never collides with the ID of a child that has the
+// same index and text.
+func ParentID(source string, index int, text string) string {
+	return GenerateChunkID(source+"#parent", index, text)
+}
@@ -0,0 +1,58 @@
+package chunker
+
+import "testing"
+
+func TestNest(t *testing.T) {
+	parents, _ := NewMarkdownChunker(200, 0, true)
+	children, _ := NewSentenceChunker(30, 0)
+
+	var all []Chunk
+	for _, p := range parents.Chunk(markdownDoc, "auth.md") {
+		all = append(all, Nest(p, children.Chunk(p.Text, p.Source), len(all))...)
+	}
+
+	if len(all) < 6 {
+		t.Fatalf("expected several children, got %d", len(all))
+	}
+	ids := make(map[string]bool)
+	for i, ch := range all {
+		if ch.Index != i {
+			t.Errorf("child %d has index %d", i, ch.Index)
+		}
+		if ids[ch.ID] {
+			t.Errorf("duplicate child ID %s", ch.ID)
+		}
+		ids[ch.ID] = true
+		if ch.ParentID == "" || ch.ParentID == ch.ID {
+			t.Errorf("child %d has parent ID %q", i, ch.ParentID)
+		}
+		if len(ch.ParentText) < len(ch.Text) {
+			t.Errorf("child %d parent text %q is shorter than its text %q", i, ch.ParentText, ch.Text)
+		}
+		if ch.Heading == "" || ch.Context != ch.Heading {
+			t.Errorf("child %d did not inherit heading and breadcrumb: %+v", i, ch)
+		}
+	}
+}
+
+func TestNest_ChildHeading(t *testing.T) {
+	parent := Chunk{Source: "auth.md", Text: "...", Heading: "Auth > Keys", Context: "Auth > Keys"}
+	children := []Chunk{
+		{Source: "auth.md", Text: "## Keys\nKeys sign requests.", Heading: "Keys", Context: "Keys"},
+		{Source: "auth.md", Text: "### Rotation\nRotate keys yearly.", Heading: "Rotation", Context: "Rotation"},
+		{Source: "auth.md", Text: "Rotation is automatic."},
+	}
+	want := []string{"Auth > Keys", "Auth > Keys > Rotation", "Auth > Keys"}
+
+	for i, ch := range Nest(parent, children, 0) {
+		if ch.Heading != want[i] || ch.Context != want[i] {
+			t.Errorf("child %d heading %q, breadcrumb %q, want %q", i, ch.Heading, ch.Context, want[i])
+		}
+	}
+}
+
+func TestParentID_DistinctFromChildID(t *testing.T) {
+	if ParentID("a.md", 0, "same") == GenerateChunkID("a.md", 0, "same") {
+		t.Error("parent and child IDs collide")
+	}
+}
@@ -0,0 +1,89 @@
+package chunker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Params records how a chunk was produced.
+type Params struct {
+	Strategy string // Strategy name, e.g. "recursive"
+	Size     int    // Chunk size passed to the strategy
+	Overlap  int    // Overlap passed to the strategy
+
+	ParentSize int    // Size of parent chunks (0 = no hierarchy)
+	Template   string // Enrichment template applied before embedding
+}
+
+// HashDocument returns the hex SHA-256 of a document's content.
+func HashDocument(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// DocumentTitle returns the first level-1 markdown heading outside code
+// fences, else the file name without its extension. Front matter titles are
+// read by the loader, which strips the block before text reaches chunking.
+func DocumentTitle(text, source string) string {
+	fence := ""
+	for _, line := range strings.Split(text, "\n") {
+		if fence != "" {
+			if closesFence(line, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(line); marker != "" {
+			fence = marker
+			continue
+		}
+		if level, title := parseHeading(strings.TrimSpace(line)); level == 1 && title != "" {
+			return title
+		}
+	}
+	base := filepath.Base(source)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Annotate records where each chunk came from: its byte offsets and line
+// range in doc (the unmodified document text), the document's title and
+// hash, and the chunker parameters. Chunks must be in document order, as
+// strategies return them. Offsets stay zero for a chunk whose text does not
+// appear verbatim in doc, e.g. after control characters were stripped.
+func Annotate(chunks []Chunk, doc, title string, params Params) {
+	hash := HashDocument(doc)
+	lineStarts := []int{0}
+	for i := 0; i < len(doc); i++ {
+		if doc[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(offset int) int {
+		return sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset })
+	}
+
+	// Overlapping chunks start after the previous chunk's start, never before
+	from := 0
+	for i := range chunks {
+		ch := &chunks[i]
+		ch.Title = title
+		ch.DocHash = hash
+		ch.Params = params
+
+		if ch.Text == "" || from > len(doc) {
+			continue
+		}
+		pos := strings.Index(doc[from:], ch.Text)
+		if pos < 0 {
+			continue
+		}
+		ch.StartOffset = from + pos
+		ch.EndOffset = ch.StartOffset + len(ch.Text)
+		ch.StartLine = lineOf(ch.StartOffset)
+		ch.EndLine = lineOf(ch.EndOffset - 1)
+		from = ch.StartOffset + 1
+	}
+}
@@ -0,0 +1,69 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnnotate_OffsetsAndLines(t *testing.T) {
+	doc := "\n\n# Keys\n\nRotate keys often. Rotate keys often.\nOld keys expire.\n"
+	c, _ := NewSentenceChunker(24, 20)
+	chunks := c.Chunk(doc, "keys.md")
+	params := Params{Strategy: c.Name(), Size: 24, Overlap: 20}
+	Annotate(chunks, doc, "Keys", params)
+
+	if len(chunks) < 3 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	prev := -1
+	for i, ch := range chunks {
+		if got := doc[ch.StartOffset:ch.EndOffset]; got != ch.Text {
+			t.Errorf("chunk %d: doc[%d:%d] = %q, want %q", i, ch.StartOffset, ch.EndOffset, got, ch.Text)
+		}
+		if ch.StartOffset <= prev {
+			t.Errorf("chunk %d starts at %d, not after previous start %d", i, ch.StartOffset, prev)
+		}
+		prev = ch.StartOffset
+
+		wantStart := strings.Count(doc[:ch.StartOffset], "\n") + 1
+		wantEnd := wantStart + strings.Count(ch.Text, "\n")
+		if ch.StartLine != wantStart || ch.EndLine != wantEnd {
+			t.Errorf("chunk %d lines %d-%d, want %d-%d", i, ch.StartLine, ch.EndLine, wantStart, wantEnd)
+		}
+		if ch.Title != "Keys" || ch.Params != params || ch.DocHash != HashDocument(doc) {
+			t.Errorf("chunk %d metadata not set: %+v", i, ch)
+		}
+	}
+	// Leading blank lines count toward line numbers
+	if chunks[0].StartLine != 3 {
+		t.Errorf("first chunk starts at line %d, want 3", chunks[0].StartLine)
+	}
+}
+
+func TestAnnotate_TextNotInDocument(t *testing.T) {
+	chunks := []Chunk{{Text: "missing"}}
+	Annotate(chunks, "some other text", "", Params{})
+	if chunks[0].StartOffset != 0 || chunks[0].EndOffset != 0 || chunks[0].StartLine != 0 {
+		t.Errorf("expected no location, got %+v", chunks[0])
+	}
+	if chunks[0].DocHash == "" {
+		t.Error("document metadata should be set even without a location")
+	}
+}
+
+func TestDocumentTitle(t *testing.T) {
+	tests := []struct {
+		text, source, want string
+	}{
+		{"Intro\n\n# API Keys\n\n## Rotation", "docs/keys.md", "API Keys"},
+		{"```\n# not a title\n```\n# Real", "a.md", "Real"},
+		{"## Only level two", "docs/rate-limits.md", "rate-limits"},
+		{"plain", "notes.txt", "notes"},
+		{"---\ntags: [auth]\n---\n# Heading", "a.md", "Heading"},
+	}
+	for _, tt := range tests {
+		if got := DocumentTitle(tt.text, tt.source); got != tt.want {
+			t.Errorf("DocumentTitle(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
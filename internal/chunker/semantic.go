@@ -0,0 +1,226 @@
+package chunker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/metawake/ragtune/internal/embedder"
+)
+
+const (
+	// DefaultBreakpointPercentile cuts at the top 5% of adjacent distances.
+	DefaultBreakpointPercentile = 95.0
+
+	// semanticWindow is how many extra sentences on each side of a boundary
+	// are embedded with its neighbors, smoothing out very short sentences.
+	semanticWindow = 1
+)
+
+// ErrNoEmbedder is returned when the semantic strategy is built without an embedder.
+var ErrNoEmbedder = errors.New("semantic chunker requires an embedder")
+
+// Compile-time interface compliance check.
+var _ ContextStrategy = (*SemanticChunker)(nil)
+
+// ContextStrategy is implemented by strategies that call external services
+// while chunking, and can therefore fail or be cancelled.
+type ContextStrategy interface {
+	Strategy
+	ChunkContext(ctx context.Context, text, source string) ([]Chunk, error)
+}
+
+// SemanticChunker splits text where the topic shifts. At every sentence
+// boundary it embeds the sentences just before and just after, measures the
+// cosine distance between the two windows, and cuts wherever the distance
+// exceeds the given percentile of all distances in the document. Chunks
+// shorter than minSize are merged into the next one; chunks longer than
+// maxSize are split at sentence boundaries.
+type SemanticChunker struct {
+	emb        embedder.Embedder
+	percentile float64
+	minSize    int
+	maxSize    int
+}
+
+// NewSemanticChunker creates a SemanticChunker. percentile is in (0, 100];
+// minSize and maxSize are in characters, with 0 <= minSize <= maxSize.
+func NewSemanticChunker(emb embedder.Embedder, percentile float64, minSize, maxSize int) (*SemanticChunker, error) {
+	if emb == nil {
+		return nil, ErrNoEmbedder
+	}
+	if maxSize <= 0 {
+		return nil, ErrInvalidChunkSize
+	}
+	if percentile <= 0 || percentile > 100 {
+		return nil, fmt.Errorf("breakpoint percentile must be in (0, 100], got %g", percentile)
+	}
+	if minSize < 0 || minSize > maxSize {
+		return nil, fmt.Errorf("min chunk size must be between 0 and %d, got %d", maxSize, minSize)
+	}
+	return &SemanticChunker{emb: emb, percentile: percentile, minSize: minSize, maxSize: maxSize}, nil
+}
+
+// Name returns the strategy name.
+func (c *SemanticChunker) Name() string {
+	return StrategySemantic
+}
+
+// Chunk splits text with a background context. If embedding fails, it falls
+// back to packing whole sentences up to maxSize. Use ChunkContext to observe
+// the error instead.
+func (c *SemanticChunker) Chunk(text, source string) []Chunk {
+	chunks, err := c.ChunkContext(context.Background(), text, source)
+	if err != nil {
+		text = normalizeText(text)
+		units := c.sentences(text)
+		return makeChunks(text, source, packUnits(units, byteWeights(units), c.maxSize, 0))
+	}
+	return chunks
+}
+
+// ChunkContext splits text at semantic breakpoints.
+func (c *SemanticChunker) ChunkContext(ctx context.Context, text, source string) ([]Chunk, error) {
+	text = normalizeText(text)
+	if len(text) == 0 {
+		return nil, nil
+	}
+
+	sentences := c.sentences(text)
+	if len(sentences) < 3 {
+		return makeChunks(text, source, packUnits(sentences, byteWeights(sentences), c.maxSize, 0)), nil
+	}
+
+	// Compare the sentences before each boundary with the sentences after it.
+	// Most windows serve on both sides of some boundary, so embed each once.
+	n := len(sentences)
+	left := make([]int, n-1)
+	right := make([]int, n-1)
+	var windows []string
+	seen := make(map[span]int)
+	window := func(from, to int) int {
+		s := span{start: sentences[max(0, from)].start, end: sentences[min(n-1, to)].end}
+		if idx, ok := seen[s]; ok {
+			return idx
+		}
+		seen[s] = len(windows)
+		windows = append(windows, text[s.start:s.end])
+		return len(windows) - 1
+	}
+	for i := range n - 1 {
+		left[i] = window(i-semanticWindow, i)
+		right[i] = window(i+1, i+1+semanticWindow)
+	}
+
+	vectors := make([][]float32, 0, len(windows))
+	for _, b := range embedder.PlanBatches(c.emb, windows) {
+		batch, err := embedder.EmbedSplitting(ctx, c.emb, windows[b.Start:b.End])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed sentences of %s: %w", source, err)
+		}
+		vectors = append(vectors, batch...)
+	}
+	if len(vectors) != len(windows) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d windows", len(vectors), len(windows))
+	}
+
+	distances := make([]float64, n-1)
+	for i := range distances {
+		distances[i] = 1 - cosine(vectors[left[i]], vectors[right[i]])
+	}
+	threshold := percentileOf(distances, c.percentile)
+
+	// Group sentences between breakpoints
+	var groups []span
+	start := 0
+	for i, d := range distances {
+		if d > threshold {
+			groups = append(groups, span{start: sentences[start].start, end: sentences[i].end})
+			start = i + 1
+		}
+	}
+	groups = append(groups, span{start: sentences[start].start, end: sentences[len(sentences)-1].end})
+
+	groups = mergeShort(groups, c.minSize)
+
+	// Split groups over maxSize at sentence boundaries
+	var spans []span
+	si := 0
+	for _, g := range groups {
+		if g.end-g.start <= c.maxSize {
+			spans = append(spans, g)
+			continue
+		}
+		var units []span
+		for si < len(sentences) && sentences[si].start < g.end {
+			if sentences[si].start >= g.start {
+				units = append(units, sentences[si])
+			}
+			si++
+		}
+		spans = append(spans, packUnits(units, byteWeights(units), c.maxSize, 0)...)
+	}
+	return makeChunks(text, source, spans), nil
+}
+
+// sentences splits text into sentences, breaking any longer than maxSize into words.
+func (c *SemanticChunker) sentences(text string) []span {
+	var units []span
+	for _, s := range splitSentences(text, span{start: 0, end: len(text)}) {
+		units = append(units, splitRecursive(text, s, c.maxSize, []splitFunc{splitWords})...)
+	}
+	return units
+}
+
+// mergeShort merges each group shorter than minSize into the following group
+// (the last one into its predecessor).
+func mergeShort(groups []span, minSize int) []span {
+	var out []span
+	for _, g := range groups {
+		if n := len(out); n > 0 && out[n-1].end-out[n-1].start < minSize {
+			out[n-1].end = g.end
+			continue
+		}
+		out = append(out, g)
+	}
+	if n := len(out); n > 1 && out[n-1].end-out[n-1].start < minSize {
+		out[n-2].end = out[n-1].end
+		out = out[:n-1]
+	}
+	return out
+}
+
+// cosine returns the cosine similarity of a and b (0 if either is zero).
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		if i >= len(b) {
+			break
+		}
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// percentileOf returns the p-th percentile of values using linear interpolation.
+func percentileOf(values []float64, p float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[lower+1]*weight
+}
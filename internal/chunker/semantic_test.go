@@ -0,0 +1,158 @@
+package chunker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// topicEmbedder maps text to keyword counts per topic, so sentences about the
+// same topic are close and a topic change is a large distance.
+type topicEmbedder struct {
+	err error
+}
+
+var topics = [][]string{
+	{"key", "keys", "rotate", "token"},
+	{"cake", "flour", "oven", "bake"},
+	{"river", "boat", "water", "fish"},
+}
+
+func (e topicEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vec := make([]float32, len(topics)+1)
+	vec[len(topics)] = 0.1 // Keep topic-free text off the zero vector
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		w = strings.Trim(w, ".,!?")
+		for i, words := range topics {
+			for _, kw := range words {
+				if w == kw {
+					vec[i]++
+				}
+			}
+		}
+	}
+	return vec, nil
+}
+
+func (e topicEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, t := range texts {
+		v, err := e.Embed(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func (e topicEmbedder) Dim() int { return len(topics) + 1 }
+
+const topicDoc = "Every project has keys. Rotate a key monthly. Old keys keep a token alive. " +
+	"Keys are secret. " +
+	"To bake a cake, mix flour. Heat the oven first. Bake the cake slowly. " +
+	"Cake needs flour. " +
+	"The river is wide. A boat drifts on the water. Fish swim in the river. " +
+	"The boat is slow."
+
+func TestSemanticChunker_CutsAtTopicShifts(t *testing.T) {
+	c, err := NewSemanticChunker(topicEmbedder{}, 80, 0, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := c.ChunkContext(context.Background(), topicDoc, "mixed.txt")
+	if err != nil {
+		t.Fatalf("ChunkContext error = %v", err)
+	}
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks (one per topic), got %d: %q", len(chunks), chunks)
+	}
+	for i, prefix := range []string{"Every project", "To bake", "The river"} {
+		if !strings.HasPrefix(chunks[i].Text, prefix) {
+			t.Errorf("chunk %d = %q, want prefix %q", i, chunks[i].Text, prefix)
+		}
+		if chunks[i].Index != i || chunks[i].Source != "mixed.txt" {
+			t.Errorf("chunk %d has bad metadata: %+v", i, chunks[i])
+		}
+	}
+}
+
+func TestSemanticChunker_MinAndMaxSize(t *testing.T) {
+	// A high min size merges topics together
+	c, _ := NewSemanticChunker(topicEmbedder{}, 80, 150, 500)
+	chunks, err := c.ChunkContext(context.Background(), topicDoc, "mixed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ch := range chunks[:len(chunks)-1] {
+		if len(ch.Text) < 150 {
+			t.Errorf("chunk %d has %d chars, want >= 150", i, len(ch.Text))
+		}
+	}
+
+	// A small max size splits topics at sentence boundaries
+	c, _ = NewSemanticChunker(topicEmbedder{}, 80, 0, 60)
+	chunks, err = c.ChunkContext(context.Background(), topicDoc, "mixed.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ch := range chunks {
+		if len(ch.Text) > 60 {
+			t.Errorf("chunk %d has %d chars, want <= 60", i, len(ch.Text))
+		}
+		if !strings.HasSuffix(ch.Text, ".") {
+			t.Errorf("chunk %d does not end at a sentence: %q", i, ch.Text)
+		}
+	}
+}
+
+func TestSemanticChunker_EmbedError(t *testing.T) {
+	boom := errors.New("boom")
+	c, _ := NewSemanticChunker(topicEmbedder{err: boom}, 95, 0, 100)
+
+	if _, err := c.ChunkContext(context.Background(), topicDoc, "mixed.txt"); !errors.Is(err, boom) {
+		t.Errorf("ChunkContext error = %v, want %v", err, boom)
+	}
+	// Chunk falls back to sentence packing
+	chunks := c.Chunk(topicDoc, "mixed.txt")
+	if len(chunks) < 2 {
+		t.Fatalf("expected fallback chunks, got %d", len(chunks))
+	}
+	for i, ch := range chunks {
+		if len(ch.Text) > 100 {
+			t.Errorf("chunk %d has %d chars, want <= 100", i, len(ch.Text))
+		}
+	}
+}
+
+func TestNewSemanticChunker_Validation(t *testing.T) {
+	if _, err := NewSemanticChunker(topicEmbedder{}, 0, 0, 100); err == nil {
+		t.Error("expected error for percentile 0")
+	}
+	if _, err := NewSemanticChunker(topicEmbedder{}, 95, 200, 100); err == nil {
+		t.Error("expected error for min size above max size")
+	}
+}
+
+func TestPercentileOf(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{50, 3},
+		{100, 5},
+		{75, 4},
+		{90, 4.6},
+	}
+	for _, tt := range tests {
+		if got := percentileOf(values, tt.p); got < tt.want-1e-9 || got > tt.want+1e-9 {
+			t.Errorf("percentileOf(p%g) = %g, want %g", tt.p, got, tt.want)
+		}
+	}
+}
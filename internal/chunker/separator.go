@@ -0,0 +1,217 @@
+package chunker
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Compile-time interface compliance check.
+var _ Strategy = (*SeparatorChunker)(nil)
+
+// splitFunc splits s into contiguous units at one kind of boundary.
+// Trailing whitespace stays with the unit before it.
+type splitFunc func(text string, s span) []span
+
+// SeparatorChunker splits text at natural boundaries (paragraphs, lines,
+// sentences, words) and packs the resulting units into chunks of at most
+// size characters. Units larger than size are split at the next finer
+// boundary, down to fixed-size pieces for very long words.
+type SeparatorChunker struct {
+	name    string
+	size    int
+	overlap int
+	levels  []splitFunc
+}
+
+// NewSentenceChunker packs whole sentences into chunks of up to size characters.
+// Overlap repeats trailing sentences of the previous chunk.
+func NewSentenceChunker(size, overlap int) (*SeparatorChunker, error) {
+	return newSeparatorChunker(StrategySentence, size, overlap, splitSentences, splitWords)
+}
+
+// NewParagraphChunker packs whole paragraphs (separated by blank lines) into
+// chunks of up to size characters. Oversized paragraphs are split by sentence.
+func NewParagraphChunker(size, overlap int) (*SeparatorChunker, error) {
+	return newSeparatorChunker(StrategyParagraph, size, overlap, splitParagraphs, splitSentences, splitWords)
+}
+
+// NewRecursiveChunker splits by paragraph, then line, then sentence, then word,
+// descending only into pieces still larger than size.
+func NewRecursiveChunker(size, overlap int) (*SeparatorChunker, error) {
+	return newSeparatorChunker(StrategyRecursive, size, overlap, splitParagraphs, splitLines, splitSentences, splitWords)
+}
+
+func newSeparatorChunker(name string, size, overlap int, levels ...splitFunc) (*SeparatorChunker, error) {
+	overlap, err := validateSize(size, overlap)
+	if err != nil {
+		return nil, err
+	}
+	return &SeparatorChunker{name: name, size: size, overlap: overlap, levels: levels}, nil
+}
+
+// Name returns the strategy name.
+func (c *SeparatorChunker) Name() string {
+	return c.name
+}
+
+// Chunk splits text into chunks and returns them with metadata.
+func (c *SeparatorChunker) Chunk(text, source string) []Chunk {
+	text = normalizeText(text)
+	if len(text) == 0 {
+		return nil
+	}
+
+	units := splitRecursive(text, span{start: 0, end: len(text)}, c.size, c.levels)
+	spans := packUnits(units, byteWeights(units), c.size, c.overlap)
+	return makeChunks(text, source, spans)
+}
+
+// splitRecursive splits s with the first level, then recursively splits any
+// unit still larger than size with the remaining levels.
+func splitRecursive(text string, s span, size int, levels []splitFunc) []span {
+	if s.end-s.start <= size {
+		return []span{s}
+	}
+	if len(levels) == 0 {
+		return splitFixed(text, s, size)
+	}
+
+	parts := levels[0](text, s)
+	if len(parts) <= 1 {
+		return splitRecursive(text, s, size, levels[1:])
+	}
+
+	var out []span
+	for _, p := range parts {
+		out = append(out, splitRecursive(text, p, size, levels[1:])...)
+	}
+	return out
+}
+
+// splitParagraphs splits at blank lines.
+func splitParagraphs(text string, s span) []span {
+	return splitAfter(text, s, func(i int) int {
+		if text[i] != '\n' {
+			return -1
+		}
+		j := i + 1
+		for j < s.end && (text[j] == ' ' || text[j] == '\t' || text[j] == '\r') {
+			j++
+		}
+		if j < s.end && text[j] == '\n' {
+			return j + 1
+		}
+		return -1
+	})
+}
+
+// splitLines splits after each line break.
+func splitLines(text string, s span) []span {
+	return splitAfter(text, s, func(i int) int {
+		if text[i] == '\n' {
+			return i + 1
+		}
+		return -1
+	})
+}
+
+// splitWords splits after each run of whitespace.
+func splitWords(text string, s span) []span {
+	return splitAfter(text, s, func(i int) int {
+		if isSpaceByte(text[i]) {
+			return i + 1
+		}
+		return -1
+	})
+}
+
+// splitSentences splits after sentence-ending punctuation followed by
+// whitespace, and at blank lines. Common abbreviations and initials
+// ("e.g.", "Dr.", "J.") do not end a sentence.
+func splitSentences(text string, s span) []span {
+	sentenceStart := s.start
+	return splitAfter(text, s, func(i int) int {
+		switch text[i] {
+		case '.', '!', '?':
+			j := i + 1
+			for j < s.end && strings.IndexByte(`"')]`, text[j]) >= 0 {
+				j++
+			}
+			if j < s.end && !isSpaceByte(text[j]) {
+				return -1
+			}
+			if text[i] == '.' && isAbbreviation(text[sentenceStart:i]) {
+				return -1
+			}
+			sentenceStart = j
+			return j
+		case '\n':
+			if i+1 < s.end && text[i+1] == '\n' {
+				sentenceStart = i + 1
+				return i + 1
+			}
+		}
+		return -1
+	})
+}
+
+// splitAfter scans s and cuts wherever boundary returns a cut position > i.
+// Whitespace following a cut is absorbed into the preceding unit, so units
+// are contiguous and cover s exactly.
+func splitAfter(text string, s span, boundary func(i int) int) []span {
+	var out []span
+	start := s.start
+	for i := s.start; i < s.end; {
+		cut := boundary(i)
+		if cut <= i {
+			i++
+			continue
+		}
+		for cut < s.end && isSpaceByte(text[cut]) {
+			cut++
+		}
+		out = append(out, span{start: start, end: cut})
+		start, i = cut, cut
+	}
+	if start < s.end {
+		out = append(out, span{start: start, end: s.end})
+	}
+	return out
+}
+
+// splitFixed cuts s into pieces of at most size bytes on rune boundaries.
+func splitFixed(text string, s span, size int) []span {
+	var out []span
+	for start := s.start; start < s.end; {
+		end := min(start+size, s.end)
+		for end > start+1 && end < s.end && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		out = append(out, span{start: start, end: end})
+		start = end
+	}
+	return out
+}
+
+// abbreviations are lowercase words that end in a period without ending a sentence.
+var abbreviations = map[string]bool{
+	"e.g": true, "i.e": true, "vs": true, "mr": true, "mrs": true, "ms": true,
+	"dr": true, "prof": true, "st": true, "fig": true, "no": true, "approx": true,
+}
+
+// isAbbreviation reports whether the text before a period ends with an
+// abbreviation or a single-letter initial.
+func isAbbreviation(before string) bool {
+	word := before[strings.LastIndexFunc(before, unicode.IsSpace)+1:]
+	word = strings.TrimLeft(word, `"'([`)
+	if utf8.RuneCountInString(word) == 1 {
+		r, _ := utf8.DecodeRuneInString(word)
+		return unicode.IsUpper(r)
+	}
+	return abbreviations[strings.ToLower(word)]
+}
+
+func isSpaceByte(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r'
+}
@@ -0,0 +1,173 @@
+package chunker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/embedder"
+)
+
+// Strategy names accepted by NewStrategy.
+const (
+	StrategyFixed     = "fixed"
+	StrategySentence  = "sentence"
+	StrategyParagraph = "paragraph"
+	StrategyRecursive = "recursive"
+	StrategyToken     = "token"
+	StrategyMarkdown  = "markdown"
+	StrategySemantic  = "semantic"
+	StrategyCode      = "code"
+)
+
+// Strategy splits a document into chunks.
+type Strategy interface {
+	// Chunk splits text from source into chunks with sequential indexes.
+	Chunk(text, source string) []Chunk
+
+	// Name returns the strategy name, e.g. "sentence".
+	Name() string
+}
+
+// StrategyNames lists the strategies NewStrategy can build.
+func StrategyNames() []string {
+	return []string{StrategyFixed, StrategySentence, StrategyParagraph, StrategyRecursive, StrategyToken, StrategyMarkdown, StrategySemantic, StrategyCode}
+}
+
+// Option configures optional strategy behavior in NewStrategy.
+type Option func(*options)
+
+type options struct {
+	breadcrumb bool
+	embedder   embedder.Embedder
+	percentile float64
+	minSize    int
+}
+
+// WithBreadcrumb prepends each chunk's heading path to its embedded text.
+// Only strategies that track headings (markdown) use it.
+func WithBreadcrumb(enabled bool) Option {
+	return func(o *options) {
+		o.breadcrumb = enabled
+	}
+}
+
+// WithEmbedder sets the embedder the semantic strategy uses to find breakpoints.
+func WithEmbedder(e embedder.Embedder) Option {
+	return func(o *options) {
+		o.embedder = e
+	}
+}
+
+// WithBreakpointPercentile sets the distance percentile above which the
+// semantic strategy starts a new chunk. Zero keeps the default (95).
+func WithBreakpointPercentile(p float64) Option {
+	return func(o *options) {
+		o.percentile = p
+	}
+}
+
+// WithMinChunkSize sets the smallest chunk, in characters, the semantic
+// strategy emits before merging with the next one.
+func WithMinChunkSize(n int) Option {
+	return func(o *options) {
+		o.minSize = n
+	}
+}
+
+// NewStrategy creates a chunking strategy by name. Size and overlap are in
+// characters, except for the token strategy where they count tokens.
+// For the semantic strategy, size is the maximum chunk size and overlap is
+// ignored. An empty name selects the fixed-size chunker.
+func NewStrategy(name string, size, overlap int, opts ...Option) (Strategy, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", StrategyFixed:
+		return New(size, overlap)
+	case StrategySentence:
+		return NewSentenceChunker(size, overlap)
+	case StrategyParagraph:
+		return NewParagraphChunker(size, overlap)
+	case StrategyRecursive:
+		return NewRecursiveChunker(size, overlap)
+	case StrategyToken:
+		return NewTokenChunker(size, overlap)
+	case StrategyMarkdown:
+		return NewMarkdownChunker(size, overlap, o.breadcrumb)
+	case StrategyCode:
+		return NewCodeChunker(size, overlap)
+	case StrategySemantic:
+		if o.percentile == 0 {
+			o.percentile = DefaultBreakpointPercentile
+		}
+		return NewSemanticChunker(o.embedder, o.percentile, o.minSize, size)
+	default:
+		return nil, fmt.Errorf("unsupported chunker: %s (supported: %s)", name, strings.Join(StrategyNames(), ", "))
+	}
+}
+
+// span is a byte range [start, end) of the normalized document text.
+type span struct {
+	start int
+	end   int
+}
+
+// makeChunks turns spans of text into chunks, trimming whitespace and
+// skipping spans that are empty after trimming.
+func makeChunks(text, source string, spans []span) []Chunk {
+	var chunks []Chunk
+	for _, s := range spans {
+		chunkText := strings.TrimSpace(text[s.start:s.end])
+		if len(chunkText) == 0 {
+			continue
+		}
+		index := len(chunks)
+		chunks = append(chunks, Chunk{
+			ID:     GenerateChunkID(source, index, chunkText),
+			Text:   chunkText,
+			Source: source,
+			Index:  index,
+		})
+	}
+	return chunks
+}
+
+// packUnits greedily merges consecutive units into chunks whose total weight
+// stays within size. Each chunk after the first starts with trailing units of
+// the previous one, up to overlap weight, as long as at least one new unit
+// still fits. A unit heavier than size forms a chunk on its own.
+func packUnits(units []span, weights []int, size, overlap int) []span {
+	var out []span
+	for i := 0; i < len(units); {
+		j, w := i, weights[i]
+		for j+1 < len(units) && w+weights[j+1] <= size {
+			j++
+			w += weights[j]
+		}
+		out = append(out, span{start: units[i].start, end: units[j].end})
+		if j == len(units)-1 {
+			break
+		}
+
+		// Step back over up to overlap weight, leaving room for the next new unit
+		next, back := j+1, 0
+		for next-1 > i && back+weights[next-1] <= overlap && back+weights[next-1]+weights[j+1] <= size {
+			next--
+			back += weights[next]
+		}
+		i = next
+	}
+	return out
+}
+
+// byteWeights returns the byte length of each unit.
+func byteWeights(units []span) []int {
+	weights := make([]int, len(units))
+	for i, u := range units {
+		weights[i] = u.end - u.start
+	}
+	return weights
+}
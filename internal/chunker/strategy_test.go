@@ -0,0 +1,171 @@
+package chunker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/tokenizer"
+)
+
+const sampleDoc = `API keys authenticate requests. Each project can have up to ten keys.
+
+To rotate a key, open the dashboard and select the project. Click Regenerate, e.g. after a leak. The old key keeps working for 24 hours.
+
+Rate limits apply per key. Dr. Smith's team found that bursts above 100 requests per second are throttled!
+Retries should use exponential backoff.`
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range StrategyNames() {
+		s, err := NewStrategy(name, 200, 20, WithEmbedder(topicEmbedder{}))
+		if err != nil {
+			t.Errorf("NewStrategy(%q) error = %v", name, err)
+			continue
+		}
+		if s.Name() != name {
+			t.Errorf("NewStrategy(%q).Name() = %q", name, s.Name())
+		}
+	}
+
+	if s, err := NewStrategy("", 200, 20); err != nil || s.Name() != StrategyFixed {
+		t.Errorf("empty name should select fixed, got %v, %v", s, err)
+	}
+	if _, err := NewStrategy("bogus", 200, 20); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+	if _, err := NewStrategy(StrategySentence, 0, 0); err != ErrInvalidChunkSize {
+		t.Errorf("expected ErrInvalidChunkSize, got %v", err)
+	}
+	if _, err := NewStrategy(StrategySemantic, 200, 0); err != ErrNoEmbedder {
+		t.Errorf("expected ErrNoEmbedder, got %v", err)
+	}
+}
+
+func TestStrategies_RespectSizeAndCoverText(t *testing.T) {
+	for _, name := range []string{StrategyFixed, StrategySentence, StrategyParagraph, StrategyRecursive} {
+		t.Run(name, func(t *testing.T) {
+			s, _ := NewStrategy(name, 120, 0)
+			chunks := s.Chunk(sampleDoc, "keys.md")
+			if len(chunks) < 2 {
+				t.Fatalf("expected multiple chunks, got %d", len(chunks))
+			}
+
+			var joined strings.Builder
+			for i, c := range chunks {
+				if len(c.Text) > 120 {
+					t.Errorf("chunk %d has %d chars, want <= 120", i, len(c.Text))
+				}
+				if c.Index != i || c.Source != "keys.md" || c.ID == "" {
+					t.Errorf("chunk %d has bad metadata: %+v", i, c)
+				}
+				joined.WriteString(c.Text + " ")
+			}
+			// Without overlap, every word appears exactly once across chunks
+			if got, want := strings.Fields(joined.String()), strings.Fields(sampleDoc); len(got) != len(want) {
+				t.Errorf("chunks contain %d words, document has %d", len(got), len(want))
+			}
+		})
+	}
+}
+
+func TestSentenceChunker_KeepsSentencesWhole(t *testing.T) {
+	c, _ := NewSentenceChunker(80, 0)
+	chunks := c.Chunk(sampleDoc, "keys.md")
+
+	for i, ch := range chunks {
+		last := ch.Text[len(ch.Text)-1]
+		if !strings.ContainsRune(".!?", rune(last)) {
+			t.Errorf("chunk %d does not end at a sentence boundary: %q", i, ch.Text)
+		}
+	}
+}
+
+func TestSplitSentences_Abbreviations(t *testing.T) {
+	text := "Use a key, e.g. a service key. Ask Dr. Smith or J. Doe. Done!"
+	units := splitSentences(text, span{0, len(text)})
+
+	var got []string
+	for _, u := range units {
+		got = append(got, strings.TrimSpace(text[u.start:u.end]))
+	}
+	want := []string{"Use a key, e.g. a service key.", "Ask Dr. Smith or J. Doe.", "Done!"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sentences = %q, want %q", got, want)
+	}
+}
+
+func TestParagraphChunker_OneParagraphPerChunk(t *testing.T) {
+	c, _ := NewParagraphChunker(180, 0)
+	chunks := c.Chunk(sampleDoc, "keys.md")
+
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks (one per paragraph), got %d: %q", len(chunks), chunks)
+	}
+	if !strings.HasPrefix(chunks[1].Text, "To rotate a key") {
+		t.Errorf("second chunk should start the second paragraph, got %q", chunks[1].Text)
+	}
+}
+
+func TestSeparatorChunker_Overlap(t *testing.T) {
+	c, _ := NewSentenceChunker(70, 40)
+	chunks := c.Chunk(sampleDoc, "keys.md")
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	// "Rate limits apply per key." ends one chunk and is repeated at the start of the next
+	repeated := false
+	for i := 1; i < len(chunks); i++ {
+		if strings.HasSuffix(chunks[i-1].Text, "per key.") && strings.HasPrefix(chunks[i].Text, "Rate limits apply per key.") {
+			repeated = true
+		}
+	}
+	if !repeated {
+		t.Errorf("expected a sentence repeated across chunks, got %q", chunks)
+	}
+}
+
+func TestSeparatorChunker_LongWord(t *testing.T) {
+	c, _ := NewRecursiveChunker(10, 0)
+	chunks := c.Chunk(strings.Repeat("x", 35), "long.txt")
+
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 fixed-size pieces, got %d", len(chunks))
+	}
+}
+
+func TestTokenChunker(t *testing.T) {
+	c, _ := NewTokenChunker(10, 2)
+	chunks := c.Chunk(sampleDoc, "keys.md")
+
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	for i, ch := range chunks {
+		if n := tokenizer.Count(ch.Text); n > 10 {
+			t.Errorf("chunk %d has %d tokens, want <= 10", i, n)
+		}
+	}
+	// Consecutive chunks share two tokens
+	first := tokenizer.Tokenize(chunks[0].Text)
+	tail := chunks[0].Text[first[len(first)-2].Start:]
+	if !strings.HasPrefix(chunks[1].Text, tail) {
+		t.Errorf("chunk 1 %q should start with overlap %q", chunks[1].Text, tail)
+	}
+}
+
+func TestPackUnits(t *testing.T) {
+	units := []span{{0, 1}, {1, 2}, {2, 3}, {3, 4}, {4, 5}}
+	weights := []int{1, 1, 1, 1, 1}
+
+	got := packUnits(units, weights, 2, 1)
+	want := []span{{0, 2}, {1, 3}, {2, 4}, {3, 5}}
+	if len(got) != len(want) {
+		t.Fatalf("packUnits = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("packUnits = %v, want %v", got, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package chunker
+
+import "github.com/metawake/ragtune/internal/tokenizer"
+
+// Compile-time interface compliance check.
+var _ Strategy = (*TokenChunker)(nil)
+
+// TokenChunker splits text into windows of a fixed number of tokens, as
+// counted by the approximate tokenizer. Use it to align chunks with an
+// embedding model's input limit.
+type TokenChunker struct {
+	size    int // tokens per chunk
+	overlap int // tokens repeated between chunks
+}
+
+// NewTokenChunker creates a TokenChunker; size and overlap count tokens.
+func NewTokenChunker(size, overlap int) (*TokenChunker, error) {
+	overlap, err := validateSize(size, overlap)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenChunker{size: size, overlap: overlap}, nil
+}
+
+// Name returns the strategy name.
+func (c *TokenChunker) Name() string {
+	return StrategyToken
+}
+
+// Chunk splits text into chunks and returns them with metadata.
+func (c *TokenChunker) Chunk(text, source string) []Chunk {
+	text = normalizeText(text)
+	tokens := tokenizer.Tokenize(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	// Each unit runs from a token's start to the next token's start, so
+	// whitespace between tokens stays with the preceding unit.
+	units := make([]span, len(tokens))
+	weights := make([]int, len(tokens))
+	for i, tok := range tokens {
+		end := len(text)
+		if i+1 < len(tokens) {
+			end = tokens[i+1].Start
+		}
+		units[i] = span{start: tok.Start, end: end}
+		weights[i] = 1
+	}
+
+	spans := packUnits(units, weights, c.size, c.overlap)
+	return makeChunks(text, source, spans)
+}
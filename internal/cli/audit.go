@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"fmt"
 	"path/filepath"
 	"time"
@@ -65,7 +64,7 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("--collection is required")
 	}
 
-	ctx := context.Background()
+	ctx := commandContext(cmd)
 
 	// Initialize store
 	store, err := initVectorStore(ctx)
@@ -111,6 +110,7 @@ func runAudit(cmd *cobra.Command, args []string) error {
 		for _, r := range results {
 			source := getPayloadString(r.Payload, "source")
 			source = filepath.Base(source)
+			source = mergedSource(r.Payload, source, q.RelevantDocs)
 			retrievedIDs = append(retrievedIDs, source)
 			scores = append(scores, r.Score)
 		}
@@ -122,6 +122,8 @@ func runAudit(cmd *cobra.Command, args []string) error {
 			RelevantIDs:  q.RelevantDocs,
 			Scores:       scores,
 			LatencyMs:    latencyMs,
+			Grades:       q.Grades(),
+			MaxGrade:     float64(q.GradeScale()),
 		})
 	}
 
@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/chunker"
+)
+
+// checkpointVersion is bumped when the checkpoint format changes incompatibly.
+const checkpointVersion = 1
+
+// defaultCheckpointDir holds checkpoints when --checkpoint is not given.
+const defaultCheckpointDir = ".ragtune/checkpoints"
+
+// checkpointHeader identifies the run a checkpoint belongs to. A run can
+// only be resumed with the same settings, or its chunk IDs would not match.
+type checkpointHeader struct {
+	Version    int            `json:"version"`
+	Store      string         `json:"store"`
+	Collection string         `json:"collection"`
+	Docs       string         `json:"docs"`
+	Config     manifestConfig `json:"config"`
+}
+
+// ingestCheckpoint records the IDs of chunks an ingest has committed to the
+// store, so a failed run can be resumed without embedding them again. The
+// file is a JSON header line followed by one chunk ID per line, appended
+// after each upserted batch.
+type ingestCheckpoint struct {
+	path string
+	file *os.File
+	w    *bufio.Writer
+	done map[string]bool
+}
+
+// checkpointPath returns the checkpoint file for the collection: path if
+// set, else <defaultCheckpointDir>/<store>-<collection>.ckpt.
+func checkpointPath(path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(defaultCheckpointDir, storeName+"-"+collectionName+".ckpt")
+}
+
+// openCheckpoint starts the checkpoint at path for the run described by
+// header. With resume, the IDs committed by an earlier run with the same
+// header are loaded and new ones appended; otherwise any existing file is
+// replaced. resumed reports whether an earlier run was found.
+func openCheckpoint(path string, header checkpointHeader, resume bool) (cp *ingestCheckpoint, resumed bool, err error) {
+	header.Version = checkpointVersion
+	cp = &ingestCheckpoint{path: path, done: map[string]bool{}}
+
+	if resume {
+		resumed, err = cp.load(header)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, false, fmt.Errorf("failed to create checkpoint directory: %w", err)
+		}
+	}
+
+	if resumed {
+		cp.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	} else {
+		cp.file, err = os.Create(path)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	cp.w = bufio.NewWriter(cp.file)
+	if !resumed {
+		data, err := json.Marshal(header)
+		if err != nil {
+			cp.file.Close()
+			return nil, false, fmt.Errorf("failed to encode checkpoint header: %w", err)
+		}
+		cp.w.Write(append(data, '\n'))
+		if err := cp.w.Flush(); err != nil {
+			cp.file.Close()
+			return nil, false, fmt.Errorf("failed to write checkpoint: %w", err)
+		}
+	}
+	return cp, resumed, nil
+}
+
+// load reads the committed chunk IDs of an earlier run. It returns false if
+// there is no checkpoint, and an error if it belongs to a different run.
+func (c *ingestCheckpoint) load(header checkpointHeader) (bool, error) {
+	f, err := os.Open(c.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	if !sc.Scan() {
+		return false, nil // empty: the earlier run died before writing anything
+	}
+	var saved checkpointHeader
+	if err := json.Unmarshal(sc.Bytes(), &saved); err != nil {
+		return false, fmt.Errorf("invalid checkpoint %s: %w", c.path, err)
+	}
+	if saved != header {
+		return false, fmt.Errorf("checkpoint %s was written by an ingest with different settings (%s); rerun without --resume to start over", c.path, checkpointDiff(saved, header))
+	}
+	for sc.Scan() {
+		// A torn last line from a crash matches no chunk ID, so it is harmless
+		if id := strings.TrimSpace(sc.Text()); id != "" {
+			c.done[id] = true
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	return true, nil
+}
+
+// committed reports whether the chunk was committed by the resumed run.
+func (c *ingestCheckpoint) committed(id string) bool {
+	return c.done[id]
+}
+
+// count returns the number of chunks the resumed run had committed.
+func (c *ingestCheckpoint) count() int {
+	return len(c.done)
+}
+
+// record appends the IDs of chunks just upserted and flushes them to disk.
+func (c *ingestCheckpoint) record(chunks []chunker.Chunk) error {
+	for _, ch := range chunks {
+		c.w.WriteString(ch.ID)
+		c.w.WriteByte('\n')
+	}
+	if err := c.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the checkpoint, keeping the file for --resume.
+func (c *ingestCheckpoint) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	err := c.w.Flush()
+	if cerr := c.file.Close(); err == nil {
+		err = cerr
+	}
+	c.file = nil
+	return err
+}
+
+// finish closes and deletes the checkpoint after a successful run.
+func (c *ingestCheckpoint) finish() error {
+	if err := c.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint: %w", err)
+	}
+	return nil
+}
+
+// checkpointDiff names the header fields that differ between two runs.
+func checkpointDiff(saved, current checkpointHeader) string {
+	var diffs []string
+	if saved.Version != current.Version {
+		diffs = append(diffs, "version")
+	}
+	if saved.Store != current.Store || saved.Collection != current.Collection {
+		diffs = append(diffs, "store/collection")
+	}
+	if saved.Docs != current.Docs {
+		diffs = append(diffs, "docs path")
+	}
+	if saved.Config != current.Config {
+		diffs = append(diffs, "embedder or chunking flags")
+	}
+	return strings.Join(diffs, ", ")
+}
@@ -0,0 +1,141 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ckpt", "run.ckpt")
+	header := checkpointHeader{Store: "mock", Collection: "docs", Docs: "/data", Config: manifestConfig{Chunker: "fixed"}}
+
+	cp, resumed, err := openCheckpoint(path, header, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed {
+		t.Error("resumed without a checkpoint file")
+	}
+	if err := cp.record([]chunker.Chunk{{ID: "a"}, {ID: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cp.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	cp, resumed, err = openCheckpoint(path, header, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resumed || cp.count() != 2 || !cp.committed("a") || cp.committed("c") {
+		t.Errorf("resumed=%v count=%d, want the 2 recorded IDs", resumed, cp.count())
+	}
+	// New IDs are appended to the resumed file
+	if err := cp.record([]chunker.Chunk{{ID: "c"}}); err != nil {
+		t.Fatal(err)
+	}
+	cp.Close()
+	if cp, _, err = openCheckpoint(path, header, true); err != nil || cp.count() != 3 {
+		t.Fatalf("count after append = %d (err %v), want 3", cp.count(), err)
+	}
+	cp.Close()
+
+	// Different settings cannot resume
+	other := header
+	other.Config.ChunkSize = 256
+	if _, _, err := openCheckpoint(path, other, true); err == nil || !strings.Contains(err.Error(), "chunking flags") {
+		t.Errorf("err = %v, want settings mismatch", err)
+	}
+
+	// Without resume the checkpoint starts over; finish removes it
+	cp, resumed, err = openCheckpoint(path, header, false)
+	if err != nil || resumed || cp.count() != 0 {
+		t.Fatalf("fresh open: resumed=%v count=%d err=%v", resumed, cp.count(), err)
+	}
+	if err := cp.finish(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("checkpoint not removed after finish: %v", err)
+	}
+}
+
+// countingEmbedder counts the texts it embeds.
+type countingEmbedder struct {
+	stubEmbedder
+	mu    sync.Mutex
+	texts int
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mu.Lock()
+	e.texts += len(texts)
+	e.mu.Unlock()
+	return e.stubEmbedder.EmbedBatch(ctx, texts)
+}
+
+func TestIngestPipeline_ResumeAfterFailure(t *testing.T) {
+	dir := writeCorpus(t, 30)
+	path := filepath.Join(t.TempDir(), "run.ckpt")
+	header := checkpointHeader{Store: "mock", Collection: "docs", Docs: dir}
+
+	// The first run dies on its fifth upsert
+	store := mock.New()
+	pipe := newTestPipeline(t, store)
+	pipe.cfg.UpsertWorkers = 1
+	cp, _, err := openCheckpoint(path, header, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipe.upserted = cp.record
+	boom := errors.New("connection reset")
+	upserts := 0
+	store.UpsertFunc = func(ctx context.Context, collection string, points []vectorstore.Point) error {
+		if upserts++; upserts == 5 {
+			return boom
+		}
+		return nil
+	}
+	first, err := pipe.run(context.Background(), dir)
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	cp.Close()
+
+	// The resumed run embeds only what the first run did not commit
+	emb := &countingEmbedder{stubEmbedder: stubEmbedder{dim: 4}}
+	pipe = newTestPipeline(t, mock.New())
+	pipe.emb = emb
+	cp, resumed, err := openCheckpoint(path, header, true)
+	if err != nil || !resumed {
+		t.Fatalf("resume: resumed=%v err=%v", resumed, err)
+	}
+	defer cp.Close()
+	pipe.skip = cp.committed
+	pipe.upserted = cp.record
+	second, err := pipe.run(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Skipped != first.Chunks || first.Chunks == 0 {
+		t.Errorf("skipped %d chunks, want the %d committed before the failure", second.Skipped, first.Chunks)
+	}
+	if emb.texts != second.Chunks {
+		t.Errorf("embedded %d texts, want %d", emb.texts, second.Chunks)
+	}
+	docs, _ := readDocuments(dir)
+	all, _ := chunkDocuments(context.Background(), nil, docs)
+	if first.Chunks+second.Chunks != len(all) || cp.count()+second.Chunks != len(all) {
+		t.Errorf("committed %d + %d chunks, want %d in total", first.Chunks, second.Chunks, len(all))
+	}
+}
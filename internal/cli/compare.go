@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -22,6 +21,7 @@ var (
 	compareEmbedders string
 	compareDocs      string
 	compareChunkSize int
+	compareChunker   string
 	compareKeep      bool
 )
 
@@ -60,6 +60,10 @@ func init() {
 	compareCmd.Flags().StringVar(&compareEmbedders, "embedders", "", "Comma-separated embedder names to compare (openai, ollama)")
 	compareCmd.Flags().StringVar(&compareDocs, "docs", "", "Path to documents (required with --embedders)")
 	compareCmd.Flags().IntVar(&compareChunkSize, "chunk-size", 512, "Chunk size for --embedders mode")
+	compareCmd.Flags().BoolVar(&breadcrumb, "breadcrumb", false, "Prepend the heading path to embedded text (--chunker markdown)")
+	compareCmd.Flags().StringVar(&compareChunker, "chunker", "fixed", "Chunking strategy for --embedders mode (fixed, sentence, paragraph, recursive, token, markdown, semantic, code)")
+	compareCmd.Flags().Float64Var(&breakpointPercentile, "breakpoint-percentile", chunker.DefaultBreakpointPercentile, "Distance percentile that starts a new chunk (--chunker semantic)")
+	compareCmd.Flags().IntVar(&minChunkSize, "min-chunk-size", 0, "Merge chunks shorter than this many characters (--chunker semantic)")
 	compareCmd.Flags().BoolVar(&compareKeep, "keep", false, "Keep auto-created collections (don't delete after comparison)")
 	compareCmd.Flags().StringVar(&queriesPath, "queries", "", "Path to queries JSON file (required)")
 	compareCmd.Flags().StringVar(&outputDir, "output", "runs", "Output directory for run artifacts")
@@ -83,7 +87,7 @@ type CollectionResult struct {
 }
 
 func runCompare(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := commandContext(cmd)
 
 	// Validate flags: need either --collections or --embedders
 	if collections == "" && compareEmbedders == "" {
@@ -130,17 +134,22 @@ func runCompare(cmd *cobra.Command, args []string) error {
 		}
 		fmt.Printf("Found %d documents\n", len(docs))
 
-		// Chunk documents once
-		c, err := chunker.New(compareChunkSize, compareChunkSize/8) // 12.5% overlap
-		if err != nil {
-			return fmt.Errorf("invalid chunker config: %w", err)
-		}
+		// Chunk documents once, unless the chunker depends on the embedder
+		semantic := compareChunker == chunker.StrategySemantic
 		var allChunks []chunker.Chunk
-		for _, doc := range docs {
-			chunks := c.Chunk(doc.Content, doc.Path)
-			allChunks = append(allChunks, chunks...)
+		if semantic {
+			fmt.Printf("Semantic chunking runs per embedder (max size=%d)\n\n", compareChunkSize)
+		} else {
+			c, err := chunker.NewStrategy(compareChunker, compareChunkSize, compareChunkSize/8, chunkerOptions(nil)...) // 12.5% overlap
+			if err != nil {
+				return fmt.Errorf("invalid chunker config: %w", err)
+			}
+			params := chunker.Params{Strategy: c.Name(), Size: compareChunkSize, Overlap: compareChunkSize / 8}
+			if allChunks, err = chunkWith(ctx, c, nil, params, docs); err != nil {
+				return err
+			}
+			fmt.Printf("Created %d chunks (chunker=%s, size=%d)\n\n", len(allChunks), c.Name(), compareChunkSize)
 		}
-		fmt.Printf("Created %d chunks (size=%d)\n\n", len(allChunks), compareChunkSize)
 
 		// Ingest with each embedder
 		for _, embName := range embedderNames {
@@ -156,45 +165,41 @@ func runCompare(cmd *cobra.Command, args []string) error {
 			}
 			embeddersUsed = append(embeddersUsed, emb)
 
+			if semantic {
+				c, err := chunker.NewStrategy(compareChunker, compareChunkSize, 0, chunkerOptions(emb)...)
+				if err != nil {
+					return fmt.Errorf("invalid chunker config: %w", err)
+				}
+				params := chunker.Params{Strategy: c.Name(), Size: compareChunkSize, Overlap: 0}
+				if allChunks, err = chunkWith(ctx, c, nil, params, docs); err != nil {
+					return err
+				}
+				fmt.Printf("  Created %d semantic chunks\n", len(allChunks))
+			}
+
 			// Create collection
 			if err := store.EnsureCollection(ctx, collName, emb.Dim()); err != nil {
 				return fmt.Errorf("failed to create collection %s: %w", collName, err)
 			}
 
-			// Generate embeddings
-			var points []vectorstore.Point
-			batchSize := 64 // Conservative default safe for most providers
-			for i := 0; i < len(allChunks); i += batchSize {
-				end := i + batchSize
-				if end > len(allChunks) {
-					end = len(allChunks)
-				}
-				batch := allChunks[i:end]
-
-				texts := make([]string, len(batch))
-				for j, chunk := range batch {
-					texts[j] = chunk.Text
-				}
-
-				vectors, err := emb.EmbedBatch(ctx, texts)
-				if err != nil {
-					return fmt.Errorf("failed to embed batch with %s: %w", embName, err)
-				}
-
-				for j, chunk := range batch {
-					points = append(points, vectorstore.Point{
-						ID:     chunk.ID,
-						Vector: vectors[j],
-						Payload: map[string]interface{}{
-							"text":     sanitizeString(chunk.Text),
-							"source":   sanitizeString(chunk.Source),
-							"chunk_id": chunk.Index,
-						},
-					})
+			// Generate embeddings in batches sized to the provider's limits
+			lastReported := 0
+			vectors, err := embedChunks(ctx, emb, allChunks, func(done, total int) {
+				if done/200 > lastReported/200 || done == total {
+					fmt.Printf("  Embedded %d/%d chunks\n", done, total)
+					lastReported = done
 				}
+			})
+			if err != nil {
+				return fmt.Errorf("failed to embed chunks with %s: %w", embName, err)
+			}
 
-				if end%200 == 0 || end == len(allChunks) {
-					fmt.Printf("  Embedded %d/%d chunks\n", end, len(allChunks))
+			points := make([]vectorstore.Point, len(allChunks))
+			for j, chunk := range allChunks {
+				points[j] = vectorstore.Point{
+					ID:      chunk.ID,
+					Vector:  vectors[j],
+					Payload: chunkPayload(chunk),
 				}
 			}
 
@@ -272,6 +277,7 @@ func runCompare(cmd *cobra.Command, args []string) error {
 			for _, r := range results {
 				source := getPayloadString(r.Payload, "source")
 				source = filepath.Base(source)
+				source = mergedSource(r.Payload, source, q.RelevantDocs)
 				retrievedIDs = append(retrievedIDs, source)
 				scores = append(scores, r.Score)
 			}
@@ -282,6 +288,8 @@ func runCompare(cmd *cobra.Command, args []string) error {
 				RetrievedIDs: retrievedIDs,
 				RelevantIDs:  q.RelevantDocs,
 				Scores:       scores,
+				Grades:       q.Grades(),
+				MaxGrade:     float64(q.GradeScale()),
 			})
 
 			if (j+1)%50 == 0 {
@@ -381,8 +389,26 @@ func runCompare(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-// createEmbedder creates an embedder by name
+// createEmbedder creates an embedder by name, guarded by the model's input token
+// limit and with each call limited by --request-timeout
 func createEmbedder(name string) (embedder.Embedder, error) {
+	emb, err := createBaseEmbedder(name)
+	if err != nil {
+		return nil, err
+	}
+	emb = embedder.WithTimeout(emb, requestTimeout)
+	policy, err := embedder.ParseTokenPolicy(tokenPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return embedder.NewTokenGuard(emb,
+		embedder.WithTokenPolicy(policy),
+		embedder.WithMaxTokens(maxInputTokens),
+	), nil
+}
+
+// createBaseEmbedder creates the provider embedder by name
+func createBaseEmbedder(name string) (embedder.Embedder, error) {
 	switch name {
 	case "openai":
 		return embedder.NewOpenAIEmbedder(), nil
@@ -0,0 +1,474 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/dedup"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+// What ingest does with duplicate chunks (--dedup-mode).
+const (
+	dedupReport = "report" // count and list duplicates, ingest everything
+	dedupSkip   = "skip"   // drop duplicates
+	dedupMerge  = "merge"  // drop duplicates, storing their sources with the kept chunk
+)
+
+// dedupOff disables text duplicate detection (--dedup).
+const dedupOff = "off"
+
+// maxDedupPairs is the number of duplicate pairs listed after ingest.
+const maxDedupPairs = 10
+
+// maxDedupVectors caps the kept chunks --dedup-cosine holds in memory and
+// compares each new chunk with. Later chunks are still compared, but not
+// kept for matching.
+const maxDedupVectors = 20000
+
+var (
+	dedupMethod    string
+	dedupMode      string
+	dedupThreshold float64
+	dedupDistance  int
+	dedupCosine    float64
+)
+
+// addDedupFlags registers the duplicate detection flags.
+func addDedupFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&dedupMethod, "dedup", dedupOff, "Detect duplicate chunks by text: off, exact, minhash, simhash")
+	cmd.Flags().StringVar(&dedupMode, "dedup-mode", dedupReport, "What to do with duplicate chunks: report, skip, merge")
+	cmd.Flags().Float64Var(&dedupThreshold, "dedup-threshold", dedup.DefaultThreshold, "Jaccard similarity of near-duplicate chunks (--dedup minhash)")
+	cmd.Flags().IntVar(&dedupDistance, "dedup-distance", dedup.DefaultMaxDistance, "Differing fingerprint bits of near-duplicate chunks (--dedup simhash)")
+	cmd.Flags().Float64Var(&dedupCosine, "dedup-cosine", 0, "Also match chunks whose vectors have at least this cosine similarity (0 = off)")
+}
+
+// dedupEnabled reports whether any duplicate detection is requested.
+func dedupEnabled() bool {
+	return dedupMethod != dedupOff || dedupCosine > 0
+}
+
+// validateDedup checks the duplicate detection flags for docsPath.
+func validateDedup(docsPath string) error {
+	switch dedupMode {
+	case dedupReport, dedupSkip, dedupMerge:
+	default:
+		return fmt.Errorf("unsupported --dedup-mode: %s (supported: report, skip, merge)", dedupMode)
+	}
+	if dedupCosine < 0 || dedupCosine > 1 {
+		return fmt.Errorf("--dedup-cosine must be between 0 and 1, got %g", dedupCosine)
+	}
+	if dedupMethod == dedupOff {
+		return nil
+	}
+	method, err := dedup.ParseMethod(dedupMethod)
+	if err != nil {
+		return err
+	}
+	if _, err := dedup.New(method, dedup.WithThreshold(dedupThreshold), dedup.WithMaxDistance(dedupDistance)); err != nil {
+		return err
+	}
+	if docsPath == stdinPath {
+		return fmt.Errorf("--dedup reads the documents twice and cannot read stdin; write the records to a file, or use --dedup-cosine alone")
+	}
+	return nil
+}
+
+// dedupConfig describes the duplicate settings that change what ingest
+// stores, for the manifest: empty when nothing is dropped.
+func dedupConfig() string {
+	if !dedupEnabled() || dedupMode == dedupReport {
+		return ""
+	}
+	cfg := dedupMode
+	switch dedupMethod {
+	case string(dedup.MethodMinHash):
+		cfg += fmt.Sprintf(",minhash=%g", dedupThreshold)
+	case string(dedup.MethodSimHash):
+		cfg += fmt.Sprintf(",simhash=%d", dedupDistance)
+	case dedupOff:
+	default:
+		cfg += "," + dedupMethod
+	}
+	if dedupCosine > 0 {
+		cfg += fmt.Sprintf(",cosine=%g", dedupCosine)
+	}
+	return cfg
+}
+
+// dedupPair is a duplicate chunk and the kept chunk it matched.
+type dedupPair struct {
+	Duplicate  string // source#index of the duplicate
+	Kept       string // source#index of the kept chunk
+	Similarity float64
+}
+
+// dedupPlan tracks which chunks duplicate a kept one.
+//
+// Like the detectors, it does not run in flat memory: dropped and merged
+// grow with the duplicates found, and labels with the chunks kept until
+// maxDedupPairs pairs are listed.
+//
+// Text duplicates are found by scanDuplicates before the pipeline runs, so
+// the first copy in document order is kept however the chunk workers
+// interleave. Vector duplicates can only be found once chunks are embedded,
+// so they are checked as batches leave the embed workers; with several
+// workers, which copy is kept depends on timing.
+type dedupPlan struct {
+	mode string
+
+	Exact, Near, Cosine int         // duplicates found by each test
+	Pairs               []dedupPair // the first maxDedupPairs found
+	Unindexed           int         // chunks kept after the vector index was full
+
+	mu      sync.Mutex
+	dropped map[string]string   // duplicate chunk ID → kept chunk ID
+	merged  map[string][]string // kept chunk ID → sources of its duplicates
+	labels  map[string]string   // kept chunk ID → source#index, until Pairs is full
+
+	// shared holds, by source, the kept chunk IDs that stand in for the
+	// source's dropped duplicates, for the manifest.
+	shared map[string][]string
+
+	// vectors, if set, matches embedded chunks by cosine similarity. In
+	// merge mode the chunks it keeps are also held here with their vectors
+	// until the end, to re-upsert the ones that gained sources after they
+	// were stored.
+	vectors *dedup.VectorIndex
+	kept    map[string][]float32
+	chunks  map[string]chunker.Chunk
+
+	// cache, if set, holds the chunks the scan produced with a chunker that
+	// embeds (semantic), so the pipeline does not embed them again.
+	cache *chunkCache
+}
+
+func newDedupPlan(mode string) *dedupPlan {
+	return &dedupPlan{
+		mode:    mode,
+		dropped: make(map[string]string),
+		merged:  make(map[string][]string),
+		labels:  make(map[string]string),
+		shared:  make(map[string][]string),
+		kept:    make(map[string][]float32),
+		chunks:  make(map[string]chunker.Chunk),
+	}
+}
+
+// Found returns the number of duplicates found.
+func (p *dedupPlan) Found() int {
+	return p.Exact + p.Near + p.Cosine
+}
+
+// close removes the chunk cache, if any.
+func (p *dedupPlan) close() error {
+	if p.cache == nil {
+		return nil
+	}
+	return p.cache.close()
+}
+
+// scanDuplicates chunks every document under dir in order, as the pipeline
+// will, and finds the text duplicates among the chunks. It also sets up
+// vector matching if --dedup-cosine is set. With the semantic chunker the
+// chunks are cached for the pipeline; the caller closes the plan.
+func scanDuplicates(ctx context.Context, dir string, emb embedder.Embedder) (*dedupPlan, error) {
+	plan := newDedupPlan(dedupMode)
+	if dedupCosine > 0 {
+		index, err := dedup.NewVectorIndex(dedupCosine, maxDedupVectors)
+		if err != nil {
+			return nil, err
+		}
+		plan.vectors = index
+	}
+	if dedupMethod == dedupOff {
+		return plan, nil
+	}
+
+	method, err := dedup.ParseMethod(dedupMethod)
+	if err != nil {
+		return nil, err
+	}
+	det, err := dedup.New(method, dedup.WithThreshold(dedupThreshold), dedup.WithMaxDistance(dedupDistance))
+	if err != nil {
+		return nil, err
+	}
+	dc, err := newDocChunker(emb)
+	if err != nil {
+		return nil, err
+	}
+	if chunkerName == chunker.StrategySemantic && !preChunked {
+		if plan.cache, err = newChunkCache(); err != nil {
+			return nil, err
+		}
+	}
+	// Number documents as the pipeline's reader does, so chunk IDs match
+	index := 0
+	err = walkDocuments(dir, func(loaded []Document) error {
+		for i, doc := range loaded {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			chunks, err := dc.chunk(ctx, doc, index+i)
+			if err != nil {
+				return err
+			}
+			if plan.cache != nil {
+				if err := plan.cache.put(index+i, chunks); err != nil {
+					return err
+				}
+			}
+			for _, ch := range chunks {
+				m, dup := det.Check(ch.ID, ch.Text)
+				switch {
+				case dup && m.Exact:
+					plan.Exact++
+					plan.add(ch, m)
+				case dup:
+					plan.Near++
+					plan.add(ch, m)
+				default:
+					plan.label(ch)
+				}
+			}
+		}
+		index += len(loaded)
+		return nil
+	})
+	if err != nil {
+		_ = plan.close()
+		return nil, fmt.Errorf("failed to scan for duplicates: %w", err)
+	}
+	return plan, nil
+}
+
+// chunkCache holds chunk lists by document index in a temporary file, so
+// only their offsets are kept in memory.
+type chunkCache struct {
+	file    *os.File
+	size    int64
+	entries map[int]cacheEntry
+}
+
+// cacheEntry locates the encoded chunks of one document in the file.
+type cacheEntry struct {
+	offset, length int64
+}
+
+func newChunkCache() (*chunkCache, error) {
+	f, err := os.CreateTemp("", "ragtune-chunks-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chunk cache: %w", err)
+	}
+	return &chunkCache{file: f, entries: make(map[int]cacheEntry)}, nil
+}
+
+// put stores the chunks of the document at index. Metadata is not stored;
+// get restores it from the document.
+func (c *chunkCache) put(index int, chunks []chunker.Chunk) error {
+	stripped := make([]chunker.Chunk, len(chunks))
+	for i, ch := range chunks {
+		ch.Metadata = nil
+		stripped[i] = ch
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(stripped); err != nil {
+		return fmt.Errorf("failed to encode chunks: %w", err)
+	}
+	if _, err := c.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write chunk cache: %w", err)
+	}
+	c.entries[index] = cacheEntry{offset: c.size, length: int64(buf.Len())}
+	c.size += int64(buf.Len())
+	return nil
+}
+
+// get returns the cached chunks of doc, the document at index, and whether
+// there are any. Once every put is done, get is safe for concurrent use.
+func (c *chunkCache) get(doc Document, index int) ([]chunker.Chunk, bool, error) {
+	e, ok := c.entries[index]
+	if !ok {
+		return nil, false, nil
+	}
+	data := make([]byte, e.length)
+	if _, err := c.file.ReadAt(data, e.offset); err != nil {
+		return nil, false, fmt.Errorf("failed to read chunk cache: %w", err)
+	}
+	var chunks []chunker.Chunk
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&chunks); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached chunks of %s: %w", doc.Path, err)
+	}
+	for i := range chunks {
+		chunks[i].Metadata = doc.Metadata
+	}
+	return chunks, true, nil
+}
+
+// close removes the cache file.
+func (c *chunkCache) close() error {
+	name := c.file.Name()
+	_ = c.file.Close()
+	return os.Remove(name)
+}
+
+// add records ch as a duplicate of the kept chunk m matched. The caller
+// holds the lock, if needed.
+func (p *dedupPlan) add(ch chunker.Chunk, m dedup.Match) {
+	p.dropped[ch.ID] = m.ID
+	p.merged[m.ID] = append(p.merged[m.ID], ch.Source)
+	p.merged[m.ID] = append(p.merged[m.ID], ch.Duplicates...)
+	if len(p.Pairs) < maxDedupPairs {
+		p.Pairs = append(p.Pairs, dedupPair{Duplicate: chunkLabel(ch), Kept: p.labels[m.ID], Similarity: m.Similarity})
+		if len(p.Pairs) == maxDedupPairs {
+			p.labels = nil // no more pairs to label
+		}
+	}
+}
+
+// label records the label of kept chunk ch for the pairs listed after
+// ingest, unless the list is full. The caller holds the lock, if needed.
+func (p *dedupPlan) label(ch chunker.Chunk) {
+	if _, ok := p.labels[ch.ID]; !ok && p.labels != nil {
+		p.labels[ch.ID] = chunkLabel(ch)
+	}
+}
+
+// apply drops the text duplicates among chunks and, in merge mode, records
+// the sources of its duplicates on each kept chunk.
+func (p *dedupPlan) apply(chunks []chunker.Chunk) []chunker.Chunk {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keep := make([]chunker.Chunk, 0, len(chunks))
+	for _, ch := range chunks {
+		if keptID, dup := p.dropped[ch.ID]; dup {
+			p.shared[ch.Source] = append(p.shared[ch.Source], keptID)
+			continue
+		}
+		if p.mode == dedupMerge {
+			ch.Duplicates = slices.Clone(p.merged[ch.ID])
+		}
+		keep = append(keep, ch)
+	}
+	return keep
+}
+
+// checkVectors matches a batch of embedded chunks against the kept ones by
+// cosine similarity, and drops the duplicates unless in report mode.
+func (p *dedupPlan) checkVectors(chunks []chunker.Chunk, points []vectorstore.Point) ([]chunker.Chunk, []vectorstore.Point) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keepChunks := make([]chunker.Chunk, 0, len(chunks))
+	keepPoints := make([]vectorstore.Point, 0, len(points))
+	for i, ch := range chunks {
+		if _, found := p.dropped[ch.ID]; found {
+			// A text duplicate kept by report mode: already counted
+			keepChunks = append(keepChunks, ch)
+			keepPoints = append(keepPoints, points[i])
+			continue
+		}
+		full := p.vectors.Full()
+		m, dup := p.vectors.Check(ch.ID, points[i].Vector)
+		if dup {
+			p.Cosine++
+			p.add(ch, m)
+			if p.mode != dedupReport {
+				p.shared[ch.Source] = append(p.shared[ch.Source], m.ID)
+				continue
+			}
+		} else {
+			p.label(ch)
+			switch {
+			case full:
+				p.Unindexed++
+			case p.mode == dedupMerge:
+				p.kept[ch.ID] = points[i].Vector
+				p.chunks[ch.ID] = ch
+			}
+		}
+		keepChunks = append(keepChunks, ch)
+		keepPoints = append(keepPoints, points[i])
+	}
+	return keepChunks, keepPoints
+}
+
+// remerged returns the kept chunks that gained duplicate sources after they
+// were embedded, with their payloads updated.
+func (p *dedupPlan) remerged() []vectorstore.Point {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var points []vectorstore.Point
+	for id, ch := range p.chunks {
+		if len(p.merged[id]) == len(ch.Duplicates) {
+			continue
+		}
+		ch.Duplicates = slices.Clone(p.merged[id])
+		points = append(points, vectorstore.Point{ID: id, Vector: p.kept[id], Payload: chunkPayload(ch)})
+	}
+	slices.SortFunc(points, func(a, b vectorstore.Point) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return points
+}
+
+// print reports the duplicates found; dropped is the number the pipeline
+// dropped.
+func (p *dedupPlan) print(dropped int) {
+	fmt.Printf("Duplicates: %d found (%d exact, %d near, %d by vector)", p.Found(), p.Exact, p.Near, p.Cosine)
+	switch p.mode {
+	case dedupReport:
+		fmt.Println("; report only, nothing dropped")
+	case dedupSkip:
+		fmt.Printf("; %d dropped\n", dropped)
+	case dedupMerge:
+		fmt.Printf("; %d merged into the chunks they duplicate\n", dropped)
+	}
+	for _, pair := range p.Pairs {
+		fmt.Printf("  %s ≈ %s (%.2f)\n", pair.Duplicate, pair.Kept, pair.Similarity)
+	}
+	if more := p.Found() - len(p.Pairs); more > 0 {
+		fmt.Printf("  ... and %d more\n", more)
+	}
+	if p.Unindexed > 0 {
+		fmt.Printf("  ⚠ --dedup-cosine holds at most %d chunks; %d later chunks were compared with them but not kept for matching\n",
+			maxDedupVectors, p.Unindexed)
+	}
+}
+
+// summary returns the count and description for the ingest summary.
+func (p *dedupPlan) summary(dropped int) (int, string) {
+	switch p.mode {
+	case dedupSkip:
+		return dropped, "dropped"
+	case dedupMerge:
+		return dropped, "merged into kept chunks"
+	default:
+		return p.Found(), "found (report only)"
+	}
+}
+
+// chunkLabel identifies a chunk in duplicate reports.
+func chunkLabel(ch chunker.Chunk) string {
+	return fmt.Sprintf("%s#%d", ch.Source, ch.Index)
+}
+
+// chunkSources returns the chunk's source followed by the sources of the
+// duplicates merged into it, each once.
+func chunkSources(chunk chunker.Chunk) []string {
+	sources := []string{sanitizeString(chunk.Source)}
+	for _, s := range chunk.Duplicates {
+		if s = sanitizeString(s); !slices.Contains(sources, s) {
+			sources = append(sources, s)
+		}
+	}
+	return sources
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/dedup"
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+// setDedupFlags sets the dedup and chunker flags for a test and restores them after.
+func setDedupFlags(t *testing.T, method, mode string, cosine float64) {
+	t.Helper()
+	oldMethod, oldMode, oldCosine := dedupMethod, dedupMode, dedupCosine
+	oldThreshold, oldDistance := dedupThreshold, dedupDistance
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	t.Cleanup(func() {
+		dedupMethod, dedupMode, dedupCosine = oldMethod, oldMode, oldCosine
+		dedupThreshold, dedupDistance = oldThreshold, oldDistance
+		chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap
+	})
+	dedupMethod, dedupMode, dedupCosine = method, mode, cosine
+	dedupThreshold, dedupDistance = dedup.DefaultThreshold, dedup.DefaultMaxDistance
+	chunkerName, chunkSize, chunkOverlap = "paragraph", 120, 0
+}
+
+func TestScanDuplicates_Merge(t *testing.T) {
+	setDedupFlags(t, "exact", dedupMerge, 0)
+
+	shared := "To rotate an API key, open the console, choose Keys, and create a replacement before revoking the old key."
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.md": shared + "\n\nThe admin guide covers roles and the audit log in detail.",
+		"b.md": strings.ToUpper(shared[:2]) + shared[2:] + "\n\nThe developer guide covers SDK setup and request signing.",
+		"c.md": "Billing is monthly. Invoices are emailed to the account owner on the first day.",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan, err := scanDuplicates(context.Background(), dir, &stubEmbedder{dim: 4})
+	if err != nil {
+		t.Fatalf("scanDuplicates failed: %v", err)
+	}
+	if plan.Exact != 1 || plan.Found() != 1 {
+		t.Fatalf("found %d exact, %d total; want 1 exact", plan.Exact, plan.Found())
+	}
+	pair := plan.Pairs[0]
+	if !strings.HasSuffix(pair.Duplicate, "b.md#0") || !strings.HasSuffix(pair.Kept, "a.md#0") {
+		t.Errorf("pair = %+v, want b.md#0 ≈ a.md#0", pair)
+	}
+
+	store := mock.New()
+	pipe := newTestPipeline(t, store)
+	pipe.dedup = plan.apply
+	stats, err := pipe.run(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if stats.Duplicates != 1 || stats.Chunks != 4 {
+		t.Errorf("stored %d chunks and dropped %d, want 4 and 1", stats.Chunks, stats.Duplicates)
+	}
+
+	points, _ := store.GetPoints("docs")
+	merged := 0
+	for _, p := range points {
+		sources, ok := p.Payload["sources"].([]string)
+		if !ok {
+			continue
+		}
+		merged++
+		if len(sources) != 2 || filepath.Base(sources[0]) != "a.md" || filepath.Base(sources[1]) != "b.md" {
+			t.Errorf("sources = %v, want a.md then b.md", sources)
+		}
+	}
+	if merged != 1 {
+		t.Errorf("%d chunks have merged sources, want 1", merged)
+	}
+}
+
+func TestScanDuplicates_SemanticCache(t *testing.T) {
+	setDedupFlags(t, "exact", dedupSkip, 0)
+	chunkerName, chunkSize = "semantic", 200
+
+	// A copied page chunks identically
+	page := "Keys rotate every ninety days. Old keys stop working a day later. Admins get an email first. The audit log records each rotation."
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"a.md": page,
+		"b.md": page,
+		"c.md": "Invoices are monthly. Refunds take a week. Card updates apply at once. Receipts go to the owner.",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	emb := &countingEmbedder{stubEmbedder: stubEmbedder{dim: 4}}
+	plan, err := scanDuplicates(context.Background(), dir, emb)
+	if err != nil {
+		t.Fatalf("scanDuplicates failed: %v", err)
+	}
+	if plan.cache == nil {
+		t.Fatal("semantic chunks were not cached")
+	}
+	scanned := emb.texts
+
+	store := mock.New()
+	pipe := newTestPipeline(t, store)
+	pipe.emb = emb
+	pipe.cached = plan.cache.get
+	pipe.dedup = plan.apply
+	stats, err := pipe.run(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	// Sentence windows were embedded by the scan only
+	if embedded := emb.texts - scanned; embedded != stats.Chunks {
+		t.Errorf("pipeline embedded %d texts for %d chunks", embedded, stats.Chunks)
+	}
+	if stats.Duplicates != plan.Found() || plan.Found() == 0 {
+		t.Errorf("dropped %d of %d duplicates found", stats.Duplicates, plan.Found())
+	}
+
+	name := plan.cache.file.Name()
+	if err := plan.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("chunk cache not removed: %v", err)
+	}
+}
+
+func TestScanDuplicates_Incremental(t *testing.T) {
+	setDedupFlags(t, "exact", dedupSkip, 0)
+	shared := "To rotate an API key, open the console, choose Keys, and create a replacement before revoking the old key."
+	dir := t.TempDir()
+	write := func(name, text string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// ingest runs one incremental ingest of dir, as runIngest does
+	store := mock.New()
+	m := newManifest()
+	cfg := manifestConfig{Chunker: "paragraph", ChunkSize: 120, Dedup: dedupConfig()}
+	ingest := func() {
+		t.Helper()
+		ctx := context.Background()
+		dups, err := scanDuplicates(ctx, dir, &stubEmbedder{dim: 4})
+		if err != nil {
+			t.Fatal(err)
+		}
+		plan := m.plan(cfg)
+		written := make(map[string][]string)
+		pipe := newTestPipeline(t, store)
+		pipe.filter = plan.check
+		pipe.dedup = dups.apply
+		pipe.upserted = func(chunks []chunker.Chunk) error {
+			for _, ch := range chunks {
+				written[ch.Source] = append(written[ch.Source], ch.ID)
+			}
+			return nil
+		}
+		if _, err := pipe.run(ctx, dir); err != nil {
+			t.Fatal(err)
+		}
+		plan.finish()
+		if err := store.Delete(ctx, "docs", m.apply(plan, written, dups.shared, cfg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// sharedSources returns the sources of the stored copies of the shared paragraph
+	sharedSources := func() []string {
+		t.Helper()
+		points, _ := store.GetPoints("docs")
+		var sources []string
+		for _, p := range points {
+			if p.Payload["text"] == shared {
+				sources = append(sources, filepath.Base(p.Payload["source"].(string)))
+			}
+		}
+		return sources
+	}
+
+	write("a.md", shared+"\n\nThe admin guide covers roles and the audit log in detail.")
+	write("b.md", shared+"\n\nThe developer guide covers SDK setup and request signing.")
+	ingest()
+	if got := sharedSources(); len(got) != 1 || got[0] != "a.md" {
+		t.Fatalf("first run stored the shared paragraph from %v, want a.md once", got)
+	}
+
+	// a.md drops the paragraph: b.md, unchanged, still needs the kept copy
+	write("a.md", "The admin guide covers roles and the audit log in detail.")
+	ingest()
+	if got := sharedSources(); len(got) != 1 {
+		t.Fatalf("after a.md changed the shared paragraph is stored from %v, want one copy", got)
+	}
+
+	// a.md is removed: the kept copy still stands in for b.md
+	if err := os.Remove(filepath.Join(dir, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	ingest()
+	if got := sharedSources(); len(got) != 1 {
+		t.Fatalf("after a.md was removed the shared paragraph is stored from %v, want one copy", got)
+	}
+
+	// b.md changes: its own copy replaces the one kept from a.md
+	write("b.md", shared+"\n\nThe developer guide covers SDK setup, request signing and retries.")
+	ingest()
+	if got := sharedSources(); len(got) != 1 || got[0] != "b.md" {
+		t.Errorf("after b.md changed the shared paragraph is stored from %v, want b.md once", got)
+	}
+}
+
+func TestDedupPlan_CheckVectors(t *testing.T) {
+	setDedupFlags(t, dedupOff, dedupMerge, 0.99)
+	plan, err := scanDuplicates(context.Background(), t.TempDir(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunks := []chunker.Chunk{
+		{ID: "k1", Source: "docs/a.md", Text: "kept"},
+		{ID: "k2", Source: "docs/b.md", Text: "other"},
+		{ID: "d1", Source: "docs/c.md", Text: "kept, reworded"},
+	}
+	points := []vectorstore.Point{
+		{ID: "k1", Vector: []float32{1, 0}, Payload: chunkPayload(chunks[0])},
+		{ID: "k2", Vector: []float32{0, 1}, Payload: chunkPayload(chunks[1])},
+		{ID: "d1", Vector: []float32{2, 0.01}},
+	}
+	keptChunks, keptPoints := plan.checkVectors(chunks, points)
+	if len(keptChunks) != 2 || len(keptPoints) != 2 || plan.Cosine != 1 {
+		t.Fatalf("kept %d chunks, found %d; want 2 kept, 1 found", len(keptChunks), plan.Cosine)
+	}
+
+	// k1 was embedded before its duplicate was found: it is stored again
+	updated := plan.remerged()
+	if len(updated) != 1 || updated[0].ID != "k1" {
+		t.Fatalf("remerged = %v, want k1", updated)
+	}
+	sources, _ := updated[0].Payload["sources"].([]string)
+	if len(sources) != 2 || sources[1] != "docs/c.md" {
+		t.Errorf("sources = %v, want docs/a.md and docs/c.md", sources)
+	}
+
+	// Past the index limit, new chunks are stored but not held for merging
+	plan.vectors, _ = dedup.NewVectorIndex(0.99, 2)
+	plan.vectors.Check("k1", []float32{1, 0})
+	plan.vectors.Check("k2", []float32{0, 1})
+	late := []chunker.Chunk{{ID: "n1", Source: "docs/d.md", Text: "new"}}
+	keptChunks, _ = plan.checkVectors(late, []vectorstore.Point{{ID: "n1", Vector: []float32{1, 1}}})
+	if len(keptChunks) != 1 || plan.Unindexed != 1 {
+		t.Errorf("kept %d, unindexed %d; want 1 and 1", len(keptChunks), plan.Unindexed)
+	}
+	if _, held := plan.chunks["n1"]; held {
+		t.Error("chunk past the vector limit is held for merging")
+	}
+}
+
+func TestDedupPlan_LabelsUntilPairsFull(t *testing.T) {
+	plan := newDedupPlan(dedupSkip)
+	kept := chunker.Chunk{ID: "k", Source: "a.md"}
+	plan.label(kept)
+	for i := 0; i < maxDedupPairs; i++ {
+		plan.add(chunker.Chunk{ID: fmt.Sprint("d", i), Source: "b.md", Index: i}, dedup.Match{ID: "k"})
+	}
+	if plan.Pairs[0].Kept != "a.md#0" {
+		t.Errorf("kept label = %q, want a.md#0", plan.Pairs[0].Kept)
+	}
+	if plan.labels != nil {
+		t.Errorf("labels still held after %d pairs", maxDedupPairs)
+	}
+
+	// Later chunks are neither labeled nor listed
+	plan.label(chunker.Chunk{ID: "k2", Source: "c.md"})
+	plan.add(chunker.Chunk{ID: "d-late", Source: "d.md"}, dedup.Match{ID: "k2"})
+	if len(plan.Pairs) != maxDedupPairs || plan.dropped["d-late"] != "k2" {
+		t.Errorf("late duplicate: %d pairs, dropped %q", len(plan.Pairs), plan.dropped["d-late"])
+	}
+}
+
+func TestValidateDedup(t *testing.T) {
+	setDedupFlags(t, "minhash", dedupSkip, 0)
+	if err := validateDedup("./docs"); err != nil {
+		t.Errorf("valid flags rejected: %v", err)
+	}
+	if err := validateDedup(stdinPath); err == nil {
+		t.Error("expected an error for --dedup with stdin")
+	}
+
+	dedupMode = "drop"
+	if err := validateDedup("./docs"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+	dedupMode, dedupMethod = dedupSkip, "fuzzy"
+	if err := validateDedup("./docs"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+	dedupMethod, dedupCosine = dedupOff, 0.95
+	if err := validateDedup(stdinPath); err != nil {
+		t.Errorf("--dedup-cosine alone should work with stdin: %v", err)
+	}
+}
+
+func TestMergedSource(t *testing.T) {
+	payload := map[string]interface{}{
+		"source":  "docs/a.md",
+		"sources": []interface{}{"docs/a.md", "docs/b.md"},
+	}
+	if got := mergedSource(payload, "a.md", []string{"b.md"}); got != "b.md" {
+		t.Errorf("mergedSource = %q, want b.md", got)
+	}
+	if got := mergedSource(payload, "a.md", []string{"a.md", "b.md"}); got != "a.md" {
+		t.Errorf("mergedSource = %q, want the primary source when it is relevant", got)
+	}
+	if got := mergedSource(payload, "a.md", []string{"c.md"}); got != "a.md" {
+		t.Errorf("mergedSource = %q, want a.md when no source is relevant", got)
+	}
+}
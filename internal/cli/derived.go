@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+// defaultDerivedDir records the collections simulate --docs has built.
+const defaultDerivedDir = ".ragtune/collections"
+
+// derivedRecord marks a collection simulate --docs finished building from
+// a corpus with a config. A collection without one was interrupted, or built
+// from other documents or settings, and is rebuilt.
+type derivedRecord struct {
+	Collection string         `json:"collection"`
+	Store      string         `json:"store"`
+	Docs       string         `json:"docs"`
+	Corpus     string         `json:"corpus_hash"`
+	Config     manifestConfig `json:"config"`
+	Chunks     int            `json:"chunks"`
+	Created    string         `json:"created"`
+}
+
+// derivedBuilder builds one collection per distinct chunking config for
+// simulate --docs. Collections are named <collection>-<hash>, where the hash
+// covers the documents and every setting that changes chunks or vectors, so
+// a later run with the same corpus and config reuses the collection.
+type derivedBuilder struct {
+	store  vectorstore.Store
+	docs   string
+	corpus string // hash of the documents
+	quiet  bool   // no progress output (--json)
+	dir    string // directory of derivedRecords
+
+	built []string // collections used in this run, in order
+}
+
+// newDerivedBuilder hashes the documents under docs.
+func newDerivedBuilder(ctx context.Context, store vectorstore.Store, docs string, quiet bool) (*derivedBuilder, error) {
+	if docs == stdinPath {
+		return nil, fmt.Errorf("--docs cannot read stdin: each chunking config reads the documents again")
+	}
+	corpus, err := corpusHash(ctx, docs)
+	if err != nil {
+		return nil, err
+	}
+	return &derivedBuilder{store: store, docs: docs, corpus: corpus, quiet: quiet, dir: defaultDerivedDir}, nil
+}
+
+// corpusHash returns a hash of the path, content and metadata of every
+// document under dir.
+func corpusHash(ctx context.Context, dir string) (string, error) {
+	h := sha256.New()
+	n := 0
+	err := walkDocuments(dir, func(docs []Document) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		for _, d := range docs {
+			meta, err := json.Marshal(d.Metadata)
+			if err != nil {
+				return fmt.Errorf("failed to hash metadata of %s: %w", d.Path, err)
+			}
+			fmt.Fprintf(h, "%s\x00%d\x00%s\x00%s\x00", d.Path, len(d.Content), d.Content, meta)
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read documents: %w", err)
+	}
+	if n == 0 {
+		return "", fmt.Errorf("no documents found in %s", dir)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveChunkConfig fills in the chunker, chunk_size and overlap cfg
+// leaves unset from the chunking flags, so the run records what was built.
+// A config that sets chunk_size but not overlap has no overlap: --chunk-overlap
+// is sized for --chunk-size, and an unset overlap cannot be told from 0.
+func resolveChunkConfig(cfg config.SimConfig) config.SimConfig {
+	if cfg.Chunker == "" {
+		cfg.Chunker = chunkerName
+	}
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = chunkSize
+		if cfg.Overlap == 0 {
+			cfg.Overlap = chunkOverlap
+		}
+	}
+	return cfg
+}
+
+// applyChunkConfig sets the chunking flags, and the embedder and
+// quantization flags the manifest config records, to cfg's settings and
+// returns a function restoring them. Settings cfg leaves unset keep the flag
+// values.
+func applyChunkConfig(cfg config.SimConfig) (restore func()) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	oldParent, oldEnrich, oldBreadcrumb := parentSize, enrichTemplate, breadcrumb
+	oldBreakpoint, oldMinChunk := breakpointPercentile, minChunkSize
+	oldQuantization, oldEmbedder := quantization, embedderName
+
+	cfg = resolveChunkConfig(cfg)
+	chunkerName, chunkSize, chunkOverlap = cfg.Chunker, cfg.ChunkSize, cfg.Overlap
+	if cfg.ParentSize > 0 {
+		parentSize = cfg.ParentSize
+	}
+	if cfg.Enrich != "" {
+		enrichTemplate = cfg.Enrich
+	}
+	if cfg.Breadcrumb {
+		breadcrumb = true
+	}
+	if cfg.BreakpointPercentile > 0 {
+		breakpointPercentile = cfg.BreakpointPercentile
+	}
+	if cfg.MinChunkSize > 0 {
+		minChunkSize = cfg.MinChunkSize
+	}
+	if cfg.Quantization != "" {
+		quantization = cfg.Quantization
+	}
+	if cfg.Embedder != "" {
+		embedderName = cfg.Embedder
+	}
+
+	return func() {
+		chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap
+		parentSize, enrichTemplate, breadcrumb = oldParent, oldEnrich, oldBreadcrumb
+		breakpointPercentile, minChunkSize = oldBreakpoint, oldMinChunk
+		quantization, embedderName = oldQuantization, oldEmbedder
+	}
+}
+
+// collection returns the derived collection for cfg, building it with emb
+// unless an earlier run already did.
+func (b *derivedBuilder) collection(ctx context.Context, cfg config.SimConfig, emb embedder.Embedder) (string, error) {
+	restore := applyChunkConfig(cfg)
+	defer restore()
+
+	runCfg := currentManifestConfig(emb, emb.Dim())
+	data, err := json.Marshal(struct {
+		Corpus string         `json:"corpus"`
+		Config manifestConfig `json:"config"`
+	}{b.corpus, runCfg})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	name := collectionName + "-" + hex.EncodeToString(sum[:])[:12]
+	for _, built := range b.built {
+		if built == name {
+			return name, nil
+		}
+	}
+
+	record := b.recordPath(name)
+	if rec, err := loadDerivedRecord(record); err != nil {
+		return "", err
+	} else if rec != nil && rec.Corpus == b.corpus && rec.Config == runCfg {
+		if n, err := b.store.Count(ctx, name); err == nil && n > 0 {
+			b.printf("Using cached collection %s (%d chunks)\n", name, n)
+			b.built = append(b.built, name)
+			return name, nil
+		}
+	}
+
+	b.printf("Building collection %s (chunker=%s, chunk_size=%d, overlap=%d)...\n", name, chunkerName, chunkSize, chunkOverlap)
+	start := time.Now()
+	// A collection without a record was left by an interrupted build
+	if n, err := b.store.Count(ctx, name); err == nil && n > 0 {
+		if err := b.store.DeleteCollection(ctx, name); err != nil {
+			return "", fmt.Errorf("failed to delete incomplete collection %s: %w", name, err)
+		}
+	}
+	if err := b.store.EnsureCollection(ctx, name, emb.Dim()); err != nil {
+		return "", fmt.Errorf("failed to create collection %s: %w", name, err)
+	}
+	b.built = append(b.built, name)
+
+	pipe := &ingestPipeline{
+		emb:        emb,
+		store:      b.store,
+		collection: name,
+		cfg: pipelineConfig{
+			ChunkWorkers:  chunkWorkers,
+			EmbedWorkers:  embedWorkers,
+			UpsertWorkers: upsertWorkers,
+			BatchSize:     ingestBatchSize,
+		},
+	}
+	stats, err := pipe.run(ctx, b.docs)
+	if err != nil {
+		return "", fmt.Errorf("failed to build collection %s: %w", name, err)
+	}
+	if stats.Chunks == 0 {
+		return "", fmt.Errorf("no chunks produced from %s", b.docs)
+	}
+	b.printf("  Ingested %d chunks from %d documents in %s\n", stats.Chunks, stats.Documents, time.Since(start).Round(time.Millisecond))
+
+	return name, saveDerivedRecord(record, derivedRecord{
+		Collection: name,
+		Store:      storeName,
+		Docs:       b.docs,
+		Corpus:     b.corpus,
+		Config:     runCfg,
+		Chunks:     stats.Chunks,
+		Created:    time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// cleanup deletes the collections used in this run and their records.
+func (b *derivedBuilder) cleanup(ctx context.Context) error {
+	var errs []error
+	for _, name := range b.built {
+		if err := b.store.DeleteCollection(ctx, name); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete collection %s: %w", name, err))
+			continue
+		}
+		if err := os.Remove(b.recordPath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		b.printf("Deleted collection %s\n", name)
+	}
+	return errors.Join(errs...)
+}
+
+func (b *derivedBuilder) printf(format string, args ...interface{}) {
+	if !b.quiet {
+		fmt.Printf(format, args...)
+	}
+}
+
+// recordPath returns the record file of a derived collection.
+func (b *derivedBuilder) recordPath(name string) string {
+	return filepath.Join(b.dir, storeName+"-"+name+".json")
+}
+
+// loadDerivedRecord reads a record, returning nil if there is none.
+func loadDerivedRecord(path string) (*derivedRecord, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var rec derivedRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		// A corrupt record only costs a rebuild
+		return nil, nil
+	}
+	return &rec, nil
+}
+
+// saveDerivedRecord writes a record, creating its directory.
+func saveDerivedRecord(path string, rec derivedRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+func TestDerivedBuilder(t *testing.T) {
+	oldColl, oldName, oldSize, oldOverlap := collectionName, chunkerName, chunkSize, chunkOverlap
+	defer func() { collectionName, chunkerName, chunkSize, chunkOverlap = oldColl, oldName, oldSize, oldOverlap }()
+	collectionName, chunkerName, chunkSize, chunkOverlap = "sweep", "fixed", 512, 64
+
+	ctx := context.Background()
+	dir := writeCorpus(t, 5)
+	store := &batchRecorder{Store: mock.New()}
+	emb := &stubEmbedder{dim: 4}
+
+	b, err := newDerivedBuilder(ctx, store, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.dir = t.TempDir()
+
+	small := config.SimConfig{Name: "small", TopK: 3, ChunkSize: 40, Overlap: 8}
+	smallK5 := config.SimConfig{Name: "small-k5", TopK: 5, ChunkSize: 40, Overlap: 8}
+	large := config.SimConfig{Name: "large", TopK: 3, ChunkSize: 200}
+
+	c1, err := b.collection(ctx, small, emb)
+	if err != nil {
+		t.Fatalf("collection failed: %v", err)
+	}
+	c2, _ := b.collection(ctx, smallK5, emb)
+	c3, _ := b.collection(ctx, large, emb)
+	if c1 != c2 {
+		t.Errorf("configs differing only in top_k got %s and %s, want one collection", c1, c2)
+	}
+	if c1 == c3 {
+		t.Errorf("configs with different chunk sizes share collection %s", c1)
+	}
+	if chunkSize != 512 || chunkOverlap != 64 {
+		t.Errorf("chunking flags not restored: size=%d overlap=%d", chunkSize, chunkOverlap)
+	}
+	n1, _ := store.Count(ctx, c1)
+	n3, _ := store.Count(ctx, c3)
+	if n1 <= n3 || n3 == 0 {
+		t.Errorf("chunk_size 40 gave %d chunks, 200 gave %d; want more small chunks", n1, n3)
+	}
+
+	// A later run finds the record and reuses the collection
+	upserts := len(store.batches)
+	later, err := newDerivedBuilder(ctx, store, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later.dir = b.dir
+	if c, err := later.collection(ctx, small, emb); err != nil || c != c1 {
+		t.Fatalf("later run got %s, %v; want cached %s", c, err, c1)
+	}
+	if len(store.batches) != upserts {
+		t.Errorf("cached collection was ingested again")
+	}
+
+	// Changed documents get a new collection
+	if err := os.WriteFile(dir+"/doc00.md", []byte("# Changed\n\nNew text."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := newDerivedBuilder(ctx, store, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed.dir = b.dir
+	if c, _ := changed.collection(ctx, small, emb); c == c1 {
+		t.Error("changed documents reused the old collection")
+	}
+
+	if err := b.cleanup(ctx); err != nil {
+		t.Fatalf("cleanup failed: %v", err)
+	}
+	if _, err := store.Count(ctx, c1); err == nil {
+		t.Errorf("collection %s still exists after cleanup", c1)
+	}
+	if _, err := os.Stat(b.recordPath(c3)); !os.IsNotExist(err) {
+		t.Errorf("record of %s still exists after cleanup", c3)
+	}
+}
+
+func TestResolveChunkConfig(t *testing.T) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() { chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap }()
+	chunkerName, chunkSize, chunkOverlap = "sentence", 512, 64
+
+	got := resolveChunkConfig(config.SimConfig{Name: "c", Overlap: 32})
+	if got.Chunker != "sentence" || got.ChunkSize != 512 || got.Overlap != 32 {
+		t.Errorf("resolveChunkConfig = %+v, want sentence/512/32", got)
+	}
+	got = resolveChunkConfig(config.SimConfig{Name: "c"})
+	if got.ChunkSize != 512 || got.Overlap != 64 {
+		t.Errorf("resolveChunkConfig = %+v, want the flag size and overlap", got)
+	}
+	// Overlap belongs with the chunk size it was set for
+	got = resolveChunkConfig(config.SimConfig{Name: "c", ChunkSize: 48})
+	if got.ChunkSize != 48 || got.Overlap != 0 {
+		t.Errorf("resolveChunkConfig = %+v, want size 48 without overlap", got)
+	}
+}
@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/tokenizer"
+	"github.com/spf13/cobra"
+)
+
+// defaultEstimateSample is the number of chunks embedded to measure throughput.
+const defaultEstimateSample = 32
+
+var estimateSample int
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate <docs-path>",
+	Short: "Estimate ingest cost and duration without writing to the store",
+	Long: `Estimate what ingesting a corpus will cost and how long it will take.
+
+Reads and chunks documents exactly like ingest, counts tokens per chunk,
+prices them with the embedder's list price, and embeds a small sample batch
+to measure throughput. Nothing is written to the vector store.
+
+The sample batch is sent to the embedder, so hosted providers bill for it
+(a few thousand tokens at most). Use --sample 0 to skip it; the ETA is then
+omitted.
+
+Equivalent to: ragtune ingest <docs-path> --dry-run
+
+Examples:
+  ragtune estimate ./docs --embedder openai
+  ragtune estimate ./docs --embedder voyage --voyage-model voyage-law-2 --chunk-size 1024`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEstimate,
+}
+
+func init() {
+	estimateCmd.Flags().StringVar(&chunkerName, "chunker", "fixed", "Chunking strategy (fixed, sentence, paragraph, recursive, token, markdown, semantic, code)")
+	estimateCmd.Flags().StringVar(&enrichTemplate, "enrich", "", "Template for embedded text, e.g. '{{title}} — {{heading_path}}\\n{{text}}'")
+	estimateCmd.Flags().BoolVar(&breadcrumb, "breadcrumb", false, "Prepend the heading path to embedded text (--chunker markdown)")
+	estimateCmd.Flags().Float64Var(&breakpointPercentile, "breakpoint-percentile", chunker.DefaultBreakpointPercentile, "Distance percentile that starts a new chunk (--chunker semantic)")
+	estimateCmd.Flags().IntVar(&minChunkSize, "min-chunk-size", 0, "Merge chunks shorter than this many characters (--chunker semantic)")
+	estimateCmd.Flags().IntVar(&chunkSize, "chunk-size", 512, "Target chunk size in characters (tokens for --chunker token)")
+	estimateCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 64, "Overlap between chunks in characters")
+	estimateCmd.Flags().BoolVar(&preChunked, "pre-chunked", false, "Treat each file as a single pre-chunked unit (skip splitting)")
+	addLoaderFlags(estimateCmd)
+	estimateCmd.Flags().IntVar(&estimateSample, "sample", defaultEstimateSample, "Chunks to embed for the throughput measurement (0 = skip)")
+
+	rootCmd.AddCommand(estimateCmd)
+}
+
+// IngestEstimate is the projected cost and duration of an ingest.
+type IngestEstimate struct {
+	Documents      int
+	Chunks         int
+	Tokens         int64 // Tokens billed, after the token policy is applied
+	ChunkingTokens int64 // Tokens already embedded while chunking (semantic chunker)
+	MaxTokens      int   // Longest chunk in tokens
+	OverLimit      int   // Chunks exceeding the model's input limit
+	TokenLimit     int   // Per-input limit (0 = unknown)
+	Model          embedder.ModelInfo
+	Cost           float64
+
+	SampleChunks int
+	SampleTime   time.Duration
+	ETA          time.Duration // Zero when no sample was taken
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	emb, err := initEmbedder()
+	if err != nil {
+		return fmt.Errorf("failed to init embedder: %w", err)
+	}
+	return printIngestEstimate(commandContext(cmd), emb, args[0], estimateSample)
+}
+
+// printIngestEstimate reads and chunks docsPath, estimates the ingest and prints it.
+func printIngestEstimate(ctx context.Context, emb embedder.Embedder, docsPath string, sample int) error {
+	docs, err := readDocuments(docsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read documents: %w", err)
+	}
+	before, _ := embedder.UsageOf(emb)
+	chunks, err := chunkDocuments(ctx, emb, docs)
+	if err != nil {
+		return err
+	}
+	after, _ := embedder.UsageOf(emb)
+
+	est, err := estimateIngest(ctx, emb, chunks, sample)
+	if err != nil {
+		return err
+	}
+	est.Documents = len(docs)
+	// The semantic chunker embeds every sentence window; ingest pays for that too
+	est.ChunkingTokens = after.Tokens - before.Tokens
+	est.Cost = est.Model.Cost(est.Tokens + est.ChunkingTokens)
+
+	printEstimate(est)
+	return nil
+}
+
+// estimateIngest counts tokens for chunks and times embedding of up to sample chunks.
+func estimateIngest(ctx context.Context, emb embedder.Embedder, chunks []chunker.Chunk, sample int) (IngestEstimate, error) {
+	est := IngestEstimate{
+		Chunks:     len(chunks),
+		Model:      embedder.Describe(emb),
+		TokenLimit: inputTokenLimit(emb),
+	}
+
+	for _, ch := range chunks {
+		n := tokenizer.Count(ch.EmbedText())
+		est.MaxTokens = max(est.MaxTokens, n)
+		if est.TokenLimit > 0 && n > est.TokenLimit {
+			est.OverLimit++
+			if tokenPolicy != string(embedder.PolicySplit) {
+				n = est.TokenLimit
+			}
+		}
+		est.Tokens += int64(n)
+	}
+	est.Cost = est.Model.Cost(est.Tokens)
+
+	if sample <= 0 || len(chunks) == 0 {
+		return est, nil
+	}
+
+	// Spread the sample across the corpus so chunk lengths are representative
+	sample = min(sample, len(chunks))
+	texts := make([]string, sample)
+	var sampleTokens int64
+	for i := range texts {
+		texts[i] = chunks[i*len(chunks)/sample].EmbedText()
+		sampleTokens += int64(tokenizer.Count(texts[i]))
+	}
+
+	start := time.Now()
+	if _, err := emb.EmbedBatch(ctx, texts); err != nil {
+		return est, fmt.Errorf("failed to embed sample batch: %w", err)
+	}
+	est.SampleChunks = sample
+	est.SampleTime = time.Since(start)
+
+	// Extrapolate by tokens rather than chunks: request time scales with input size
+	if sampleTokens > 0 {
+		est.ETA = time.Duration(float64(est.SampleTime) * float64(est.Tokens) / float64(sampleTokens))
+	}
+	return est, nil
+}
+
+// printEstimate prints an ingest estimate.
+func printEstimate(est IngestEstimate) {
+	avgTokens := int64(0)
+	if est.Chunks > 0 {
+		avgTokens = est.Tokens / int64(est.Chunks)
+	}
+
+	fmt.Println("Ingest estimate (dry run, nothing written)")
+	fmt.Printf("  Documents:   %d\n", est.Documents)
+	fmt.Printf("  Chunks:      %d (avg %d tokens, max %d)\n", est.Chunks, avgTokens, est.MaxTokens)
+	fmt.Printf("  Tokens:      %d\n", est.Tokens)
+	if est.ChunkingTokens > 0 {
+		fmt.Printf("  Chunking:    %d tokens embedded to find semantic breakpoints (included in cost)\n", est.ChunkingTokens)
+	}
+	if est.OverLimit > 0 {
+		fmt.Printf("  Over limit:  %d chunks exceed %d tokens (policy: %s)\n", est.OverLimit, est.TokenLimit, tokenPolicy)
+	}
+
+	model := est.Model.Model
+	if model == "" {
+		model = embedderName
+	}
+	if est.Model.PricePerMTokens > 0 {
+		fmt.Printf("  Model:       %s ($%.2f / 1M tokens)\n", model, est.Model.PricePerMTokens)
+	} else {
+		fmt.Printf("  Model:       %s (no list price, assuming local)\n", model)
+	}
+	fmt.Printf("  Cost:        %s\n", formatCost(est.Cost))
+
+	if est.SampleChunks == 0 {
+		fmt.Println("  ETA:         unknown (no sample batch)")
+		return
+	}
+	rate := float64(est.SampleChunks) / est.SampleTime.Seconds()
+	fmt.Printf("  Throughput:  %d chunks in %s (%.1f chunks/sec)\n", est.SampleChunks, est.SampleTime.Round(time.Millisecond), rate)
+	fmt.Printf("  ETA:         ~%s (embedding only, sequential batches)\n", est.ETA.Round(time.Second))
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/embedder"
+)
+
+func TestEstimateIngest_TokensAndLimit(t *testing.T) {
+	oldMax, oldPolicy := maxInputTokens, tokenPolicy
+	defer func() { maxInputTokens, tokenPolicy = oldMax, oldPolicy }()
+	maxInputTokens, tokenPolicy = 10, "truncate"
+
+	chunks := []chunker.Chunk{
+		{Text: "one two three"},
+		{Text: strings.Repeat("word ", 30)},
+	}
+
+	est, err := estimateIngest(context.Background(), &stubEmbedder{dim: 4}, chunks, 0)
+	if err != nil {
+		t.Fatalf("estimateIngest failed: %v", err)
+	}
+
+	if est.Chunks != 2 {
+		t.Errorf("Chunks = %d, want 2", est.Chunks)
+	}
+	if est.MaxTokens != 30 {
+		t.Errorf("MaxTokens = %d, want 30", est.MaxTokens)
+	}
+	if est.OverLimit != 1 {
+		t.Errorf("OverLimit = %d, want 1", est.OverLimit)
+	}
+	// 3 tokens + 30 truncated to 10
+	if est.Tokens != 13 {
+		t.Errorf("Tokens = %d, want 13", est.Tokens)
+	}
+	if est.Cost != 0 {
+		t.Errorf("Cost = %v, want 0 for a model without a list price", est.Cost)
+	}
+	if est.SampleChunks != 0 || est.ETA != 0 {
+		t.Errorf("expected no sample with sample=0, got %d chunks, ETA %s", est.SampleChunks, est.ETA)
+	}
+}
+
+func TestEstimateIngest_Sample(t *testing.T) {
+	chunks := make([]chunker.Chunk, 100)
+	for i := range chunks {
+		chunks[i] = chunker.Chunk{Text: "some chunk text"}
+	}
+	guard := embedder.NewTokenGuard(&stubEmbedder{dim: 4})
+
+	est, err := estimateIngest(context.Background(), guard, chunks, 8)
+	if err != nil {
+		t.Fatalf("estimateIngest failed: %v", err)
+	}
+
+	if est.SampleChunks != 8 {
+		t.Errorf("SampleChunks = %d, want 8", est.SampleChunks)
+	}
+	if got := guard.Usage().Inputs; got != 8 {
+		t.Errorf("embedded %d inputs, want only the 8-chunk sample", got)
+	}
+	if est.ETA < est.SampleTime {
+		t.Errorf("ETA %s should be at least the sample time %s", est.ETA, est.SampleTime)
+	}
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -11,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/metawake/ragtune/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -72,6 +72,9 @@ Examples:
   # Save as golden query (infers relevant doc from top result)
   ragtune explain "How to reset password?" --collection prod --save
 
+  # Show the parent sections of matched child chunks
+  ragtune explain "How to reset password?" --collection prod --return parent
+
   # Save with explicit relevant doc
   ragtune explain "How to reset password?" --collection prod --save --relevant docs/auth.md`,
 	Args: cobra.ExactArgs(1),
@@ -82,6 +85,7 @@ func init() {
 	explainCmd.Flags().BoolVar(&saveQuery, "save", false, "Save query to golden queries file")
 	explainCmd.Flags().StringVar(&goldenFile, "golden-file", "golden-queries.json", "Path to golden queries file")
 	explainCmd.Flags().StringVar(&relevantDoc, "relevant", "", "Relevant doc (inferred from top result if not specified)")
+	explainCmd.Flags().StringVar(&returnMode, "return", returnChunk, "Show chunks or their parents: chunk, parent (needs ingest --parent-size)")
 }
 
 func runExplain(cmd *cobra.Command, args []string) error {
@@ -90,8 +94,11 @@ func runExplain(cmd *cobra.Command, args []string) error {
 	if collectionName == "" {
 		return fmt.Errorf("--collection is required")
 	}
+	if err := validateReturnMode(returnMode); err != nil {
+		return err
+	}
 
-	ctx := context.Background()
+	ctx := commandContext(cmd)
 
 	// Initialize vector store
 	store, err := initVectorStore(ctx)
@@ -116,10 +123,13 @@ func runExplain(cmd *cobra.Command, args []string) error {
 
 	// Search
 	fmt.Printf("Searching collection '%s' on %s (top-k=%d)...\n", collectionName, storeName, topK)
-	results, err := store.Search(ctx, collectionName, queryVec, topK)
+	results, err := store.Search(ctx, collectionName, queryVec, searchLimit(returnMode, topK))
 	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
 	}
+	if returnMode == returnParent {
+		results = collapseToParents(results, topK)
+	}
 
 	// Display results
 	fmt.Println()
@@ -143,7 +153,19 @@ func runExplain(cmd *cobra.Command, args []string) error {
 		fmt.Println()
 		fmt.Printf("[%d] Score: %.4f | ID: %s\n", i+1, r.Score, r.ID)
 		fmt.Printf("    Source: %s\n", getPayloadString(r.Payload, "source"))
+		if heading, ok := r.Payload["heading_path"].(string); ok && heading != "" {
+			fmt.Printf("    Section: %s\n", heading)
+		}
+		if loc := formatLocation(r.Payload); loc != "" {
+			fmt.Printf("    Location: %s\n", loc)
+		}
+		if symbol, ok := r.Payload["symbol"].(string); ok && symbol != "" {
+			fmt.Printf("    Symbol: %s\n", symbol)
+		}
 
+		if matched, ok := r.Payload["matched_text"].(string); ok && matched != "" {
+			fmt.Printf("    Matched: %s\n", truncate(matched, 200))
+		}
 		text := getPayloadString(r.Payload, "text")
 		fmt.Printf("    Text: %s\n", truncate(text, 200))
 	}
@@ -349,6 +371,56 @@ func getPayloadString(payload map[string]interface{}, key string) string {
 	return "<unknown>"
 }
 
+// getPayloadStrings extracts a list of strings from a payload. Stores return
+// lists as []string, []interface{} after a JSON round trip, or a
+// comma-separated string (Chroma).
+func getPayloadStrings(payload map[string]interface{}, key string) []string {
+	switch v := payload[key].(type) {
+	case []string:
+		return v
+	case string:
+		return strings.Split(v, ",")
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// formatLocation describes where a chunk sits in its document, e.g.
+// "lines 120–141 of auth.md", or returns "" when the payload has no lines.
+func formatLocation(payload map[string]interface{}) string {
+	start, ok := getPayloadInt(payload, "start_line")
+	if !ok || start <= 0 {
+		return ""
+	}
+	file := filepath.Base(getPayloadString(payload, "source"))
+	end, _ := getPayloadInt(payload, "end_line")
+	if end <= start {
+		return fmt.Sprintf("line %d of %s", start, file)
+	}
+	return fmt.Sprintf("lines %d–%d of %s", start, end, file)
+}
+
+// getPayloadInt reads an integer payload value. Stores decode JSON numbers
+// differently, so int, int64 and float64 are all accepted.
+func getPayloadInt(payload map[string]interface{}, key string) (int, bool) {
+	switch v := payload[key].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
 func truncate(s string, maxLen int) string {
 	// Replace newlines with spaces for display
 	s = strings.ReplaceAll(s, "\n", " ")
@@ -370,6 +442,9 @@ type GoldenQuery struct {
 	ID           string   `json:"id"`
 	Text         string   `json:"text"`
 	RelevantDocs []string `json:"relevant_docs"`
+	Notes        string   `json:"notes,omitempty"`
+	// Judgments carries graded judgments through a rewrite of the file.
+	Judgments []config.Judgment `json:"judgments,omitempty"`
 }
 
 // appendGoldenQuery adds a query to the golden queries file.
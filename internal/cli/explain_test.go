@@ -332,6 +332,24 @@ func TestClassifyScoreShape(t *testing.T) {
 	}
 }
 
+func TestFormatLocation(t *testing.T) {
+	tests := []struct {
+		payload map[string]interface{}
+		want    string
+	}{
+		{map[string]interface{}{"source": "docs/auth.md", "start_line": 120, "end_line": 141}, "lines 120–141 of auth.md"},
+		{map[string]interface{}{"source": "main.go", "start_line": float64(7), "end_line": float64(7)}, "line 7 of main.go"},
+		{map[string]interface{}{"source": "a.md", "start_line": int64(3), "end_line": int64(4)}, "lines 3–4 of a.md"},
+		{map[string]interface{}{"source": "a.md", "text": "no lines"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatLocation(tt.payload); got != tt.want {
+			t.Errorf("formatLocation(%v) = %q, want %q", tt.payload, got, tt.want)
+		}
+	}
+}
+
 // Helper function
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
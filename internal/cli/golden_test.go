@@ -176,3 +176,30 @@ func TestAppendGoldenQuery_InvalidExistingFile(t *testing.T) {
 		t.Errorf("expected parse error, got: %v", err)
 	}
 }
+
+func TestAppendGoldenQuery_KeepsJudgments(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "golden.json")
+
+	existing := `{"queries": [{"id": "q1", "text": "auth", "relevant_docs": ["auth.md"], "notes": "graded", "judgments": [{"doc": "auth.md", "grade": 3}]}]}`
+	if err := os.WriteFile(filePath, []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := appendGoldenQuery(filePath, "billing", "billing.md"); err != nil {
+		t.Fatalf("appendGoldenQuery failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gq GoldenQueries
+	if err := json.Unmarshal(data, &gq); err != nil {
+		t.Fatal(err)
+	}
+	q := gq.Queries[0]
+	if q.Notes != "graded" || len(q.Judgments) != 1 || q.Judgments[0].Grade != 3 {
+		t.Errorf("rewrite lost notes or judgments: %+v", q)
+	}
+}
@@ -3,14 +3,17 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/metawake/ragtune/internal/chunker"
 	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/loader"
 	"github.com/metawake/ragtune/internal/vectorstore"
 	"github.com/metawake/ragtune/internal/vectorstore/chroma"
 	"github.com/metawake/ragtune/internal/vectorstore/mock"
@@ -20,14 +23,7 @@ import (
 	"github.com/metawake/ragtune/internal/vectorstore/weaviate"
 )
 
-// Batch size constants for embedding operations.
 const (
-	// defaultEmbedBatchSize is optimized for most API providers
-	defaultEmbedBatchSize = 64
-
-	// teiBatchSize is the TEI server's default max_client_batch_size
-	teiBatchSize = 32
-
 	// progressUpdateInterval controls how often progress is reported
 	progressUpdateInterval = 5 * time.Second
 )
@@ -38,44 +34,196 @@ var (
 	embeddingDim int
 	explainMode  bool
 	preChunked   bool
+	ingestDryRun bool
+	chunkerName  string
+	breadcrumb   bool
+
+	// Semantic chunker settings (shared with estimate and compare)
+	breakpointPercentile float64
+	minChunkSize         int
+
+	// Parent-child (small-to-big) hierarchy; 0 disables it
+	parentSize int
+
+	// Enrichment template for embedded text, e.g. "{{title}}\n{{text}}"
+	enrichTemplate string
+
+	// Ingest pipeline parallelism and batch size
+	chunkWorkers    int
+	embedWorkers    int
+	upsertWorkers   int
+	ingestBatchSize int
+
+	// Incremental ingest against a manifest of previously ingested documents
+	incremental  bool
+	manifestFile string
+
+	// Resumable ingest from the checkpoint of a failed run
+	resumeIngest   bool
+	checkpointFile string
+
+	// Record fields for JSON, JSONL and CSV documents
+	textField      string
+	idField        string
+	metadataFields []string
+
+	// Glob patterns selecting files under the docs path
+	includeGlobs []string
+	excludeGlobs []string
 )
 
+// stdinPath is the docs path that reads a JSONL stream from stdin.
+const stdinPath = "-"
+
+// stdin is read when the docs path is stdinPath; tests replace it.
+var stdin io.Reader = os.Stdin
+
 var ingestCmd = &cobra.Command{
 	Use:   "ingest <docs-path>",
 	Short: "Load documents, chunk, embed, and upsert into vector store",
 	Long: `Ingest documents into a vector store for RAG retrieval.
 
-Reads .md and .txt files from the specified directory, splits them into chunks,
-generates embeddings, and upserts into the configured vector store.
+Reads .md and .txt files, plus source files (.go, .py, .js, .ts, .java, .rs,
+.c, ...), from the specified directory, splits them into chunks, generates
+embeddings, and upserts into the configured vector store.
+
+HTML, DOCX and PDF files are converted to text. JSON, JSONL and CSV files
+yield one document per record, sourced as file#id: --text-field names the
+text field, --id-field the ID, and --metadata-fields fields to store in the
+payload. YAML front matter in .md and .txt files and <file>.meta.json
+sidecars are stored in every chunk's payload, not embedded.
+
+The docs path may also be a .zip or .tar.gz archive, or "-" to read JSONL
+records from stdin (sourced as stdin#id), e.g. after an export job. Select
+files with --include and --exclude glob patterns ("**" spans directories,
+a trailing "/" matches directories), and list patterns to always skip in a
+.ragtuneignore file at the root of the docs directory or archive.
+
+Chunking strategies (--chunker):
+  fixed      Character windows, broken at the last space (default)
+  sentence   Whole sentences packed up to --chunk-size characters
+  paragraph  Whole paragraphs packed up to --chunk-size characters
+  recursive  Paragraph, then line, sentence and word boundaries
+  token      Fixed token windows; --chunk-size and --chunk-overlap count tokens
+  markdown   Heading sections; never splits code fences or tables. Stores the
+             heading path; --breadcrumb also embeds it with each chunk
+  code       Top-level declarations (go/parser for Go, brace or indent blocks
+             elsewhere). Stores the symbol and line range of each chunk
+  semantic   Cuts where embeddings of adjacent sentences diverge (above
+             --breakpoint-percentile); --chunk-size is the maximum and
+             --min-chunk-size the minimum. Embeds every sentence window
+
+Use --parent-size for small-to-big retrieval: documents are split into
+parents of that size, and each parent into --chunk-size children with the
+same chunker. Only children are embedded; the parent's ID and text are
+stored in each child's payload for 'simulate --return parent'.
+
+Use --enrich for contextual retrieval: each chunk is embedded as the
+template rendered with its document context, while the stored text stays
+the original chunk. Fields: {{title}} (front matter title, first H1, or
+file name), {{heading_path}}, {{source}}, {{symbol}} and {{text}}.
 
 Use --pre-chunked when your documents are already chunked by an external tool
 (e.g., POMA, Unstructured, LlamaIndex). Each file is treated as a single chunk
 and embedded as-is, without splitting. The source is set to the filename so
 that retrieval metrics match your queries.json relevant_docs.
 
+Documents stream through a pipeline (read → chunk → embed → upsert) with
+bounded queues, so memory stays flat however large the corpus (except
+with --dedup, below), and each --batch-size batch of chunks is upserted as
+soon as it is embedded.
+--chunk-workers, --embed-workers and --upsert-workers set each stage's
+parallelism.
+
+Use --incremental to re-ingest only what changed since the last incremental
+run. A manifest (--manifest, default .ragtune/manifests/<store>-<collection>.json)
+records each document's content hash and chunk IDs: new and changed documents
+are chunked, embedded and upserted, and chunks of changed or removed documents
+are deleted. Changing the embedder or chunking flags re-ingests everything.
+
+Ingest checkpoints the IDs of upserted chunks (--checkpoint, default
+.ragtune/checkpoints/<store>-<collection>.ckpt) and deletes the file when it
+succeeds. After a failure, rerun the same command with --resume: chunk IDs
+are deterministic, so chunks already upserted are skipped instead of being
+embedded and paid for again. Ctrl-C, SIGTERM and --timeout stop ingest the
+same way, reporting what was committed.
+
+Use --dedup to find chunks copied between documents, before they are
+embedded: exact matches text equal after folding case and whitespace,
+minhash near duplicates by word-shingle Jaccard similarity
+(--dedup-threshold), and simhash by fingerprint distance (--dedup-distance).
+--dedup-cosine also compares the embedded vectors, holding up to 20,000
+kept chunks in memory. --dedup-mode report lists duplicates and ingests
+everything, skip drops all but the first copy, and merge drops them and
+stores every copy's source in the kept chunk's "sources" payload, which
+simulate, compare and audit match against. Duplicate detection keeps a
+fingerprint of every chunk, so its memory grows with the corpus.
+
+Use --dry-run to print chunk counts, token totals, estimated cost and ETA
+without writing anything (same as 'ragtune estimate').
+
 Example:
   ragtune ingest ./data/docs --store qdrant --collection demo --chunk-size 512
-  ragtune ingest ./poma-chunksets/ --collection demo --pre-chunked`,
+  ragtune ingest ./data/docs --collection demo-sent --chunker sentence
+  ragtune ingest ./data/docs --collection demo-pc --chunk-size 256 --parent-size 2048
+  ragtune ingest ./data/docs --collection demo-ctx --enrich '{{title}} — {{heading_path}}\n{{text}}'
+  ragtune ingest ./kb-export --collection kb --text-field body.text --metadata-fields product
+  ragtune ingest ./repo --collection docs --include 'docs/**/*.md' --exclude 'CHANGELOG.md'
+  ragtune ingest ./export.tar.gz --collection kb
+  export-kb --jsonl | ragtune ingest - --collection kb --text-field body
+  ragtune ingest ./wiki --collection wiki --dedup minhash --dedup-mode merge
+  ragtune ingest ./data/docs --collection demo --incremental
+  ragtune ingest ./data/docs --collection demo --resume
+  ragtune ingest ./poma-chunksets/ --collection demo --pre-chunked
+  ragtune ingest ./data/docs --embedder openai --dry-run`,
 	Args: cobra.ExactArgs(1),
 	RunE: runIngest,
 }
 
 func init() {
-	ingestCmd.Flags().IntVar(&chunkSize, "chunk-size", 512, "Target chunk size in characters")
+	ingestCmd.Flags().StringVar(&chunkerName, "chunker", "fixed", "Chunking strategy (fixed, sentence, paragraph, recursive, token, markdown, semantic, code)")
+	ingestCmd.Flags().BoolVar(&breadcrumb, "breadcrumb", false, "Prepend the heading path to embedded text (--chunker markdown)")
+	ingestCmd.Flags().Float64Var(&breakpointPercentile, "breakpoint-percentile", chunker.DefaultBreakpointPercentile, "Distance percentile that starts a new chunk (--chunker semantic)")
+	ingestCmd.Flags().IntVar(&minChunkSize, "min-chunk-size", 0, "Merge chunks shorter than this many characters (--chunker semantic)")
+	ingestCmd.Flags().IntVar(&chunkSize, "chunk-size", 512, "Target chunk size in characters (tokens for --chunker token)")
 	ingestCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 64, "Overlap between chunks in characters")
+	ingestCmd.Flags().StringVar(&enrichTemplate, "enrich", "", "Template for embedded text, e.g. '{{title}} — {{heading_path}}\\n{{text}}'")
+	ingestCmd.Flags().IntVar(&parentSize, "parent-size", 0, "Split parents of this size and embed --chunk-size children of each (0 = flat)")
+	addLoaderFlags(ingestCmd)
+	addDedupFlags(ingestCmd)
 	ingestCmd.Flags().IntVar(&embeddingDim, "embedding-dim", 0, "Embedding dimension (auto-detected from embedder if not set)")
 	ingestCmd.Flags().BoolVar(&explainMode, "explain", false, "Explain each step of the ingestion process")
 	ingestCmd.Flags().BoolVar(&preChunked, "pre-chunked", false, "Treat each file as a single pre-chunked unit (skip splitting)")
+	ingestCmd.Flags().IntVar(&chunkWorkers, "chunk-workers", defaultChunkWorkers, "Documents chunked in parallel")
+	ingestCmd.Flags().IntVar(&embedWorkers, "embed-workers", defaultEmbedWorkers, "Batches embedded in parallel")
+	ingestCmd.Flags().IntVar(&upsertWorkers, "upsert-workers", defaultUpsertWorkers, "Batches upserted in parallel")
+	ingestCmd.Flags().IntVar(&ingestBatchSize, "batch-size", defaultBatchSize, "Chunks embedded and upserted together")
+	ingestCmd.Flags().BoolVar(&incremental, "incremental", false, "Only ingest new and changed documents, and delete chunks of changed or removed ones")
+	ingestCmd.Flags().StringVar(&manifestFile, "manifest", "", "Manifest file for --incremental (default .ragtune/manifests/<store>-<collection>.json)")
+	ingestCmd.Flags().BoolVar(&resumeIngest, "resume", false, "Resume a failed ingest from its checkpoint, skipping chunks already upserted")
+	ingestCmd.Flags().StringVar(&checkpointFile, "checkpoint", "", "Checkpoint file (default .ragtune/checkpoints/<store>-<collection>.ckpt)")
+	ingestCmd.Flags().BoolVar(&ingestDryRun, "dry-run", false, "Estimate tokens, cost and duration without writing to the store")
 }
 
 func runIngest(cmd *cobra.Command, args []string) error {
 	docsPath := args[0]
+	ctx := commandContext(cmd)
+
+	if ingestDryRun {
+		emb, err := initEmbedder()
+		if err != nil {
+			return fmt.Errorf("failed to init embedder: %w", err)
+		}
+		return printIngestEstimate(ctx, emb, docsPath, defaultEstimateSample)
+	}
 
 	if collectionName == "" {
 		return fmt.Errorf("--collection is required")
 	}
+	if err := validateDedup(docsPath); err != nil {
+		return err
+	}
 
-	ctx := context.Background()
 	totalStart := time.Now()
 
 	// Initialize embedder first (need dimension for collection)
@@ -99,6 +247,18 @@ func runIngest(cmd *cobra.Command, args []string) error {
 	} else {
 		fmt.Printf("Using embedding dimension: %d (auto-detected from %s)\n", dim, embedderName)
 	}
+	if te, ok := emb.(*embedder.TransformEmbedder); ok {
+		q := te.Quantization()
+		fmt.Printf("Vector transform: %d dims, %s quantization (%s/vector)\n", te.Dim(), q, formatBytes(int64(q.BytesPerVector(te.Dim()))))
+	}
+	if limit := inputTokenLimit(emb); limit > 0 {
+		fmt.Printf("Input limit: %d tokens per chunk (policy: %s)\n", limit, tokenPolicy)
+	}
+	if info := embedder.Describe(emb); info.MaxBatchTokens > 0 {
+		fmt.Printf("Batch limits: %d texts / %d tokens per request\n", info.MaxBatchItems, info.MaxBatchTokens)
+	} else if info.MaxBatchItems > 0 {
+		fmt.Printf("Batch limits: %d texts per request\n", info.MaxBatchItems)
+	}
 	if explainMode {
 		fmt.Println("  💡 Embeddings are vectors (lists of numbers) representing meaning.")
 		fmt.Println("     Similar texts have similar vectors, enabling semantic search.")
@@ -109,203 +269,718 @@ func runIngest(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to ensure collection: %w", err)
 	}
 
-	// Read and chunk documents
-	fmt.Printf("Reading documents from %s...\n", docsPath)
-	readStart := time.Now()
-	docs, err := readDocuments(docsPath)
+	// Settings that determine chunk IDs and vectors, for the manifest and checkpoint
+	runCfg := currentManifestConfig(emb, dim)
+
+	// Record committed chunks so a failed run can be resumed
+	absDocs := docsPath
+	if docsPath != stdinPath {
+		absDocs, err = filepath.Abs(docsPath)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to read documents: %w", err)
+		return fmt.Errorf("invalid docs path: %w", err)
+	}
+	cpPath := checkpointPath(checkpointFile)
+	if !resumeIngest {
+		if _, err := os.Stat(cpPath); err == nil {
+			fmt.Printf("Discarding the checkpoint of an unfinished run (use --resume to continue it): %s\n", cpPath)
+		}
+	}
+	checkpoint, resumed, err := openCheckpoint(cpPath, checkpointHeader{
+		Store:      storeName,
+		Collection: collectionName,
+		Docs:       absDocs,
+		Config:     runCfg,
+	}, resumeIngest)
+	if err != nil {
+		return err
+	}
+	defer closeWithLog(checkpoint, "checkpoint")
+	if resumeIngest {
+		if resumed {
+			fmt.Printf("Resuming: %d chunks committed by the failed run will be skipped\n", checkpoint.count())
+		} else {
+			fmt.Println("No checkpoint to resume; starting from the beginning")
+		}
 	}
-	readTime := time.Since(readStart)
-	fmt.Printf("Found %d documents (read in %s)\n", len(docs), readTime.Round(time.Millisecond))
-
-	// Chunk documents (or use pre-chunked mode)
-	chunkStart := time.Now()
-	var allChunks []chunker.Chunk
-	var chunkTime time.Duration
 
-	if preChunked {
-		// Pre-chunked mode: each file is one chunk, no splitting.
-		// Useful for externally chunked data (POMA chunksets, etc.)
-		for i, doc := range docs {
-			text := strings.TrimSpace(doc.Content)
-			if len(text) == 0 {
-				continue
+	// Compare against the manifest to find what changed
+	var (
+		manifest *ingestManifest
+		plan     *manifestPlan
+		written  map[string][]string
+	)
+	if incremental {
+		if manifest, err = loadManifest(manifestPath(manifestFile)); err != nil {
+			return err
+		}
+		if manifest.chunkCount() > 0 {
+			if n, err := store.Count(ctx, collectionName); err == nil && n == 0 {
+				fmt.Printf("Collection %s is empty; ignoring the manifest\n", collectionName)
+				manifest = newManifest()
 			}
-			allChunks = append(allChunks, chunker.Chunk{
-				ID:     chunker.GenerateChunkID(doc.Path, i, text),
-				Text:   text,
-				Source: doc.Path,
-				Index:  i,
-			})
-		}
-		chunkTime = time.Since(chunkStart)
-		fmt.Printf("Pre-chunked: %d chunks from %d files (in %s)\n", len(allChunks), len(docs), chunkTime.Round(time.Millisecond))
-		if explainMode {
+		}
+		if len(manifest.Documents) > 0 && manifest.Config != runCfg {
+			fmt.Println("Ingest settings changed since the last run; re-ingesting every document")
+		}
+		plan = manifest.plan(runCfg)
+		written = make(map[string][]string)
+	}
+
+	if explainMode {
+		if preChunked {
 			fmt.Println("  💡 Pre-chunked mode: each file is treated as a single chunk.")
 			fmt.Println("     Use this when documents were already chunked by an external tool.")
-			fmt.Println()
-		}
-	} else {
-		c, err := chunker.New(chunkSize, chunkOverlap)
-		if err != nil {
-			return fmt.Errorf("invalid chunker config: %w", err)
-		}
-		for _, doc := range docs {
-			chunks := c.Chunk(doc.Content, doc.Path)
-			allChunks = append(allChunks, chunks...)
-		}
-		chunkTime = time.Since(chunkStart)
-		fmt.Printf("Created %d chunks (chunked in %s)\n", len(allChunks), chunkTime.Round(time.Millisecond))
-		if explainMode {
-			avgChunkSize := 0
-			if len(allChunks) > 0 {
-				totalChars := 0
-				for _, ch := range allChunks {
-					totalChars += len(ch.Text)
-				}
-				avgChunkSize = totalChars / len(allChunks)
+		} else {
+			unit := "chars"
+			if chunkerName == chunker.StrategyToken {
+				unit = "tokens"
 			}
 			fmt.Printf("  💡 Chunking splits documents into smaller pieces for embedding.\n")
-			fmt.Printf("     • Target size: %d chars, Overlap: %d chars\n", chunkSize, chunkOverlap)
-			fmt.Printf("     • Actual avg: %d chars per chunk\n", avgChunkSize)
+			fmt.Printf("     • Strategy: %s, Target size: %d %s, Overlap: %d %s\n", chunkerName, chunkSize, unit, chunkOverlap, unit)
 			fmt.Println("     • Smaller chunks = precise matching, less context")
 			fmt.Println("     • Larger chunks = more context, may include noise")
-			fmt.Println()
 		}
+		fmt.Println("  💡 Documents stream through read → chunk → embed → upsert; each batch")
+		fmt.Println("     is stored as soon as it is embedded, so memory stays flat.")
+		if dedupEnabled() {
+			fmt.Println("     Duplicate detection is the exception: it keeps a fingerprint of")
+			fmt.Println("     every chunk, so its memory grows with the corpus.")
+		}
+		fmt.Println()
 	}
 
-	// Generate embeddings in batches
-	fmt.Println("Generating embeddings...")
-	embedStart := time.Now()
-	var points []vectorstore.Point
+	// Find duplicate chunks before any are embedded
+	var dups *dedupPlan
+	if dedupEnabled() {
+		if dedupMethod != dedupOff {
+			fmt.Printf("Scanning %s for duplicate chunks (%s)...\n", docsPath, dedupMethod)
+		}
+		if dups, err = scanDuplicates(ctx, docsPath, emb); err != nil {
+			return err
+		}
+		defer dups.close()
+	}
 
-	// Batch size varies by provider
-	batchSize := defaultEmbedBatchSize
-	if embedderName == "tei" {
-		batchSize = teiBatchSize
+	// Stream documents through the pipeline, upserting batches as they complete
+	if docsPath == stdinPath {
+		fmt.Printf("Ingesting JSONL records from stdin into %s...\n", storeName)
+	} else {
+		fmt.Printf("Ingesting documents from %s into %s...\n", docsPath, storeName)
 	}
+	pipelineStart := time.Now()
 	lastProgress := time.Now()
-	for i := 0; i < len(allChunks); i += batchSize {
-		end := i + batchSize
-		if end > len(allChunks) {
-			end = len(allChunks)
+	pipe := &ingestPipeline{
+		emb:        emb,
+		store:      store,
+		collection: collectionName,
+		cfg: pipelineConfig{
+			ChunkWorkers:  chunkWorkers,
+			EmbedWorkers:  embedWorkers,
+			UpsertWorkers: upsertWorkers,
+			BatchSize:     ingestBatchSize,
+		},
+		progress: func(stats pipelineStats) {
+			// Progress update periodically
+			if time.Since(lastProgress) > progressUpdateInterval {
+				rate := float64(stats.Chunks) / time.Since(pipelineStart).Seconds()
+				fmt.Printf("  Upserted %d chunks from %d documents read (%.1f chunks/sec)\n", stats.Chunks, stats.Documents, rate)
+				lastProgress = time.Now()
+			}
+		},
+	}
+	// Chunks committed in this run or a resumed one, by source, for the manifest
+	recordWritten := func(chunks []chunker.Chunk) {
+		if written == nil {
+			return
 		}
-		batch := allChunks[i:end]
-
-		// Extract texts for batch embedding
-		texts := make([]string, len(batch))
-		for j, chunk := range batch {
-			texts[j] = chunk.Text
+		for _, ch := range chunks {
+			written[ch.Source] = append(written[ch.Source], ch.ID)
 		}
-
-		// Batch embed
-		vectors, err := emb.EmbedBatch(ctx, texts)
-		if err != nil {
-			return fmt.Errorf("failed to embed batch starting at %d: %w", i, err)
+	}
+	pipe.upserted = func(chunks []chunker.Chunk) error {
+		recordWritten(chunks)
+		return checkpoint.record(chunks)
+	}
+	if resumed {
+		pipe.skip = checkpoint.committed
+		pipe.skipped = recordWritten
+	}
+	if plan != nil {
+		pipe.filter = plan.check
+	}
+	if dups != nil {
+		if dups.cache != nil {
+			pipe.cached = dups.cache.get
 		}
-
-		// Create points from results
-		for j, chunk := range batch {
-			points = append(points, vectorstore.Point{
-				ID:     chunk.ID,
-				Vector: vectors[j],
-				Payload: map[string]interface{}{
-					"text":     sanitizeString(chunk.Text),
-					"source":   sanitizeString(chunk.Source),
-					"chunk_id": chunk.Index,
-				},
-			})
+		if dups.mode != dedupReport {
+			pipe.dedup = dups.apply
 		}
+		if dups.vectors != nil {
+			pipe.dedupVectors = dups.checkVectors
+		}
+	}
+	stats, err := pipe.run(ctx, docsPath)
+	if err != nil {
+		if ctx.Err() != nil {
+			// Interrupted or --timeout: the checkpoint holds everything committed
+			fmt.Printf("\nInterrupted: %d chunks from %d documents were committed to %s (%d documents read)\n",
+				stats.Chunks, stats.Chunked, collectionName, stats.Documents)
+			if committed := stats.Chunks + stats.Skipped; committed > 0 {
+				fmt.Printf("Checkpoint %s records %d committed chunks; rerun with --resume to continue\n", cpPath, committed)
+			}
+			return fmt.Errorf("ingest interrupted: %w", ctx.Err())
+		}
+		if stats.Chunks > 0 {
+			fmt.Printf("  %d chunks were upserted before the failure; rerun with --resume to continue\n", stats.Chunks)
+		}
+		return err
+	}
+	pipelineTime := time.Since(pipelineStart)
 
-		// Progress update periodically
-		if time.Since(lastProgress) > progressUpdateInterval || end%1000 == 0 {
-			elapsed := time.Since(embedStart)
-			rate := float64(end) / elapsed.Seconds()
-			eta := time.Duration(float64(len(allChunks)-end)/rate) * time.Second
-			fmt.Printf("  Embedded %d/%d chunks (%.1f/sec, ETA: %s)\n", end, len(allChunks), rate, eta.Round(time.Second))
-			lastProgress = time.Now()
+	if preChunked {
+		fmt.Printf("Pre-chunked: %d chunks from %d files\n", stats.Chunks, stats.Chunked)
+	} else {
+		fmt.Printf("Created %d chunks with %s chunker from %d documents\n", stats.Chunks, chunkerName, stats.Chunked)
+	}
+	if stats.Skipped > 0 {
+		fmt.Printf("Skipped %d chunks committed before the resume\n", stats.Skipped)
+	}
+	if dups != nil {
+		// Kept chunks stored before a vector duplicate of them was found
+		points := dups.remerged()
+		for start := 0; start < len(points); start += ingestBatchSize {
+			end := min(start+ingestBatchSize, len(points))
+			if err := store.Upsert(ctx, collectionName, points[start:end]); err != nil {
+				return fmt.Errorf("failed to update merged sources: %w", err)
+			}
 		}
+		dups.print(stats.Duplicates)
+	}
+	usage, _ := embedder.UsageOf(emb)
+	if usage.Truncated > 0 {
+		fmt.Printf("  ⚠ %d chunks exceeded the model's token limit and were truncated\n", usage.Truncated)
+	}
+	if usage.Split > 0 {
+		fmt.Printf("  %d chunks exceeded the model's token limit and were embedded in pieces\n", usage.Split)
 	}
-	embedTime := time.Since(embedStart)
-	embedRate := float64(len(allChunks)) / embedTime.Seconds()
 
-	// Upsert into vector store
-	fmt.Printf("Upserting into %s...\n", storeName)
-	upsertStart := time.Now()
-	if err := store.Upsert(ctx, collectionName, points); err != nil {
-		return fmt.Errorf("failed to upsert: %w", err)
+	// Delete chunks of changed and removed documents, then record the run
+	var deleted int
+	if manifest != nil {
+		plan.finish()
+		fmt.Printf("Changes: %d added, %d updated, %d deleted, %d unchanged\n", plan.Added, plan.Updated, plan.Deleted, plan.Unchanged)
+		var shared map[string][]string
+		if dups != nil {
+			shared = dups.shared
+		}
+		stale := manifest.apply(plan, written, shared, runCfg)
+		if len(stale) > 0 {
+			d, ok := store.(vectorstore.Deleter)
+			if !ok {
+				return fmt.Errorf("store %s does not support deleting points; --incremental cannot remove stale chunks", storeName)
+			}
+			if err := d.Delete(ctx, collectionName, stale); err != nil {
+				return fmt.Errorf("failed to delete stale chunks: %w", err)
+			}
+			deleted = len(stale)
+		}
+		manifest.Store, manifest.Collection = storeName, collectionName
+		if err := manifest.save(manifestPath(manifestFile)); err != nil {
+			return err
+		}
+	}
+	if err := checkpoint.finish(); err != nil {
+		return err
 	}
-	upsertTime := time.Since(upsertStart)
 
 	if explainMode {
-		fmt.Printf("  💡 Stored %d vectors in collection '%s' on %s.\n", len(points), collectionName, storeName)
+		avgChunkSize := int64(0)
+		if stats.Chunks > 0 {
+			avgChunkSize = stats.ChunkChars / int64(stats.Chunks)
+		}
+		fmt.Printf("  💡 Stored %d vectors in collection '%s' on %s (avg %d chars per chunk).\n", stats.Chunks, collectionName, storeName, avgChunkSize)
 		fmt.Println("     Each vector is stored with metadata (source file, text).")
 		fmt.Println("     Queries will find vectors with similar meaning to your question.")
+		fmt.Println("     Stage times below are summed over each stage's workers.")
 		fmt.Println()
 	}
 
 	totalTime := time.Since(totalStart)
+	throughput := float64(stats.Chunks) / pipelineTime.Seconds()
 
 	// Print summary
 	fmt.Printf("\n")
 	fmt.Printf("╔══════════════════════════════════════════════════════════════╗\n")
 	fmt.Printf("║  ✓ Ingestion Complete                                        ║\n")
 	fmt.Printf("╠══════════════════════════════════════════════════════════════╣\n")
-	fmt.Printf("║  Documents:     %8d                                      ║\n", len(docs))
-	fmt.Printf("║  Chunks:        %8d                                      ║\n", len(points))
+	fmt.Printf("║  Documents:     %8d                                      ║\n", stats.Documents)
+	fmt.Printf("║  Chunks:        %8d                                      ║\n", stats.Chunks)
+	if dups != nil {
+		n, what := dups.summary(stats.Duplicates)
+		fmt.Printf("║  Duplicates:    %8d  %-36s║\n", n, what)
+	}
+	if manifest != nil {
+		changes := fmt.Sprintf("%d added, %d updated, %d deleted, %d unchanged", plan.Added, plan.Updated, plan.Deleted, plan.Unchanged)
+		fmt.Printf("║  Changes:       %-45s ║\n", changes)
+		fmt.Printf("║  Deleted:       %8d  %-36s║\n", deleted, "stale chunks")
+	}
+	fmt.Printf("║  Tokens:        %8d  %-36s║\n", usage.Tokens, "(est. cost "+formatCost(embedder.Describe(emb).Cost(usage.Tokens))+")")
 	fmt.Printf("║  Collection:    %-42s ║\n", collectionName)
 	fmt.Printf("╠══════════════════════════════════════════════════════════════╣\n")
-	fmt.Printf("║  Read Time:     %8s                                      ║\n", readTime.Round(time.Millisecond))
-	fmt.Printf("║  Chunk Time:    %8s                                      ║\n", chunkTime.Round(time.Millisecond))
-	fmt.Printf("║  Embed Time:    %8s  (%.1f chunks/sec)                   ║\n", embedTime.Round(time.Second), embedRate)
-	fmt.Printf("║  Upsert Time:   %8s                                      ║\n", upsertTime.Round(time.Millisecond))
-	fmt.Printf("║  Total Time:    %8s                                      ║\n", totalTime.Round(time.Second))
+	fmt.Printf("║  Read Time:     %8s                                      ║\n", stats.ReadTime.Round(time.Millisecond))
+	fmt.Printf("║  Chunk Time:    %8s                                      ║\n", stats.ChunkTime.Round(time.Millisecond))
+	fmt.Printf("║  Embed Time:    %8s                                      ║\n", stats.EmbedTime.Round(time.Second))
+	fmt.Printf("║  Upsert Time:   %8s                                      ║\n", stats.UpsertTime.Round(time.Millisecond))
+	fmt.Printf("║  Total Time:    %8s  (%.1f chunks/sec)                   ║\n", totalTime.Round(time.Second), throughput)
 	fmt.Printf("╚══════════════════════════════════════════════════════════════╝\n")
 
 	return nil
 }
 
+// embedChunks embeds chunk texts in batches packed to the embedder's advertised
+// item and token limits. Batches the provider still rejects as too large are
+// split and retried. progress is called after each batch with the chunks done.
+func embedChunks(ctx context.Context, emb embedder.Embedder, chunks []chunker.Chunk, progress func(done, total int)) ([][]float32, error) {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.EmbedText()
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for _, b := range embedder.PlanBatches(emb, texts) {
+		batchVectors, err := embedder.EmbedSplitting(ctx, emb, texts[b.Start:b.End])
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed batch starting at %d: %w", b.Start, err)
+		}
+		if len(batchVectors) != b.End-b.Start {
+			return nil, fmt.Errorf("embedder returned %d vectors for batch of %d", len(batchVectors), b.End-b.Start)
+		}
+		vectors = append(vectors, batchVectors...)
+		if progress != nil {
+			progress(b.End, len(texts))
+		}
+	}
+	return vectors, nil
+}
+
+// chunkPayload builds the stored payload for a chunk.
+func chunkPayload(chunk chunker.Chunk) map[string]interface{} {
+	payload := map[string]interface{}{
+		"text":     sanitizeString(chunk.Text),
+		"source":   sanitizeString(chunk.Source),
+		"chunk_id": chunk.Index,
+	}
+	if chunk.Heading != "" {
+		payload["heading_path"] = sanitizeString(chunk.Heading)
+	}
+	if chunk.Symbol != "" {
+		payload["symbol"] = sanitizeString(chunk.Symbol)
+	}
+	if chunk.EndOffset > 0 {
+		payload["start_offset"] = chunk.StartOffset
+		payload["end_offset"] = chunk.EndOffset
+	}
+	if chunk.StartLine > 0 {
+		payload["start_line"] = chunk.StartLine
+		payload["end_line"] = chunk.EndLine
+	}
+	if chunk.Title != "" {
+		payload["title"] = sanitizeString(chunk.Title)
+	}
+	if chunk.DocHash != "" {
+		payload["doc_hash"] = chunk.DocHash
+	}
+	if chunk.Params.Strategy != "" {
+		payload["chunker"] = chunk.Params.Strategy
+		payload["chunk_size"] = chunk.Params.Size
+		payload["chunk_overlap"] = chunk.Params.Overlap
+	}
+	if chunk.ParentID != "" {
+		payload["parent_id"] = chunk.ParentID
+		payload["parent_text"] = sanitizeString(chunk.ParentText)
+		payload["parent_size"] = chunk.Params.ParentSize
+	}
+	if chunk.Params.Template != "" {
+		payload["enrich_template"] = chunk.Params.Template
+	}
+	if len(chunk.Duplicates) > 0 {
+		payload["sources"] = chunkSources(chunk)
+	}
+	// Document metadata never overrides the fields above
+	for k, v := range chunk.Metadata {
+		if _, ok := payload[k]; !ok {
+			payload[k] = v
+		}
+	}
+	return payload
+}
+
+// inputTokenLimit returns the per-input token limit enforced for emb (0 = none).
+func inputTokenLimit(emb embedder.Embedder) int {
+	if maxInputTokens > 0 {
+		return maxInputTokens
+	}
+	return embedder.Describe(emb).MaxInputTokens
+}
+
+// chunkDocuments splits documents with the configured chunker and applies the
+// --enrich template. The semantic chunker embeds sentences with emb to find
+// breakpoints.
+func chunkDocuments(ctx context.Context, emb embedder.Embedder, docs []Document) ([]chunker.Chunk, error) {
+	dc, err := newDocChunker(emb)
+	if err != nil {
+		return nil, err
+	}
+	var allChunks []chunker.Chunk
+	for i, doc := range docs {
+		chunks, err := dc.chunk(ctx, doc, i)
+		if err != nil {
+			return nil, err
+		}
+		allChunks = append(allChunks, chunks...)
+	}
+	return allChunks, nil
+}
+
+// docChunker splits one document at a time as configured by the chunking
+// flags. It is not safe for concurrent use; give each worker its own.
+type docChunker struct {
+	c, parent chunker.Strategy // c is nil with --pre-chunked
+	params    chunker.Params
+	tmpl      *chunker.Template
+}
+
+// newDocChunker validates the chunking flags and builds their strategies.
+func newDocChunker(emb embedder.Embedder) (*docChunker, error) {
+	dc := &docChunker{}
+	if enrichTemplate != "" {
+		var err error
+		if dc.tmpl, err = chunker.ParseTemplate(enrichTemplate); err != nil {
+			return nil, fmt.Errorf("invalid --enrich template: %w", err)
+		}
+	}
+	if preChunked {
+		dc.params = chunker.Params{Strategy: "pre-chunked"}
+		return dc, nil
+	}
+
+	c, err := chunker.NewStrategy(chunkerName, chunkSize, chunkOverlap, chunkerOptions(emb)...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chunker config: %w", err)
+	}
+	dc.c = c
+	dc.params = chunker.Params{Strategy: c.Name(), Size: chunkSize, Overlap: chunkOverlap}
+
+	if parentSize > 0 {
+		if parentSize <= chunkSize {
+			return nil, fmt.Errorf("--parent-size (%d) must be larger than --chunk-size (%d)", parentSize, chunkSize)
+		}
+		dc.parent, err = chunker.NewStrategy(chunkerName, parentSize, 0, chunkerOptions(emb)...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent chunker config: %w", err)
+		}
+		dc.params.ParentSize = parentSize
+	}
+	return dc, nil
+}
+
+// chunk splits doc, the index-th document read, and applies the template.
+// With --pre-chunked the document is one chunk, numbered by index.
+func (dc *docChunker) chunk(ctx context.Context, doc Document, index int) ([]chunker.Chunk, error) {
+	var chunks []chunker.Chunk
+	if dc.c == nil {
+		// Useful for externally chunked data (POMA chunksets, etc.)
+		text := strings.TrimSpace(doc.Content)
+		if len(text) == 0 {
+			return nil, nil
+		}
+		chunks = []chunker.Chunk{{
+			ID:     chunker.GenerateChunkID(doc.Path, index, text),
+			Text:   text,
+			Source: doc.Path,
+			Index:  index,
+		}}
+		annotate(chunks, doc, dc.params)
+	} else {
+		var err error
+		if chunks, err = splitDocument(ctx, dc.c, dc.parent, dc.params, doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if dc.tmpl != nil {
+		chunker.Enrich(chunks, dc.tmpl)
+		for i := range chunks {
+			chunks[i].Params.Template = dc.tmpl.String()
+		}
+	}
+	return chunks, nil
+}
+
+// chunkerOptions returns the strategy options set by the shared chunker flags.
+func chunkerOptions(emb embedder.Embedder) []chunker.Option {
+	return []chunker.Option{
+		chunker.WithBreadcrumb(breadcrumb),
+		chunker.WithEmbedder(emb),
+		chunker.WithBreakpointPercentile(breakpointPercentile),
+		chunker.WithMinChunkSize(minChunkSize),
+	}
+}
+
+// chunkWith splits docs with c and annotates each chunk with its provenance.
+func chunkWith(ctx context.Context, c, parent chunker.Strategy, params chunker.Params, docs []Document) ([]chunker.Chunk, error) {
+	var allChunks []chunker.Chunk
+	for _, doc := range docs {
+		chunks, err := splitDocument(ctx, c, parent, params, doc)
+		if err != nil {
+			return nil, err
+		}
+		allChunks = append(allChunks, chunks...)
+	}
+	return allChunks, nil
+}
+
+// splitDocument splits doc with c and annotates the chunks. With a parent
+// strategy, the document is first split into parents, and c splits each
+// parent into the children that get embedded.
+func splitDocument(ctx context.Context, c, parent chunker.Strategy, params chunker.Params, doc Document) ([]chunker.Chunk, error) {
+	var chunks []chunker.Chunk
+	if parent == nil {
+		var err error
+		if chunks, err = chunkText(ctx, c, doc.Content, doc.Path); err != nil {
+			return nil, err
+		}
+	} else {
+		parents, err := chunkText(ctx, parent, doc.Content, doc.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parents {
+			children, err := chunkText(ctx, c, p.Text, doc.Path)
+			if err != nil {
+				return nil, err
+			}
+			chunks = append(chunks, chunker.Nest(p, children, len(chunks))...)
+		}
+	}
+	annotate(chunks, doc, params)
+	return chunks, nil
+}
+
+// annotate records provenance and metadata on a document's chunks. Offsets
+// and lines are shifted to positions in the file, and a title from the
+// document's metadata wins over the one found in the text.
+func annotate(chunks []chunker.Chunk, doc Document, params chunker.Params) {
+	title, ok := doc.Metadata["title"].(string)
+	if !ok || title == "" {
+		title = chunker.DocumentTitle(doc.Content, doc.Path)
+	}
+	chunker.Annotate(chunks, doc.Content, title, params)
+	for i := range chunks {
+		ch := &chunks[i]
+		ch.Metadata = doc.Metadata
+		if ch.EndOffset > 0 {
+			ch.StartOffset += doc.Offset
+			ch.EndOffset += doc.Offset
+		}
+		if ch.StartLine > 0 {
+			ch.StartLine += doc.LineOffset
+			ch.EndLine += doc.LineOffset
+		}
+	}
+}
+
+// chunkText splits one text with c, passing ctx to strategies that call the embedder.
+func chunkText(ctx context.Context, c chunker.Strategy, text, source string) ([]chunker.Chunk, error) {
+	if cs, ok := c.(chunker.ContextStrategy); ok {
+		chunks, err := cs.ChunkContext(ctx, text, source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to chunk %s: %w", source, err)
+		}
+		return chunks, nil
+	}
+	return c.Chunk(text, source), nil
+}
+
+// formatCost formats an estimated USD cost; local models report as free.
+func formatCost(usd float64) string {
+	switch {
+	case usd == 0:
+		return "free"
+	case usd < 0.0001:
+		return "<$0.0001"
+	case usd < 0.01:
+		return fmt.Sprintf("$%.4f", usd)
+	default:
+		return fmt.Sprintf("$%.2f", usd)
+	}
+}
+
 // Document represents a loaded document
 type Document struct {
-	Path    string
-	Content string
+	Path     string
+	Content  string
+	Metadata map[string]interface{} // Fields stored in every chunk's payload
+
+	// Position of Content in the file (non-zero after stripped front matter)
+	Offset     int
+	LineOffset int
+}
+
+// addLoaderFlags registers the file selection and record field flags used
+// by readDocuments.
+func addLoaderFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&includeGlobs, "include", nil, "Only read files matching these glob patterns (e.g. 'docs/**/*.md')")
+	cmd.Flags().StringSliceVar(&excludeGlobs, "exclude", nil, "Skip files and directories matching these glob patterns (e.g. 'node_modules/,CHANGELOG.md'); adds to .ragtuneignore")
+	cmd.Flags().StringVar(&textField, "text-field", "text", "JSON/JSONL field or CSV column holding the text (dotted paths select nested fields)")
+	cmd.Flags().StringVar(&idField, "id-field", "id", "JSON/JSONL field or CSV column used as the record ID in sources (file#id)")
+	cmd.Flags().StringSliceVar(&metadataFields, "metadata-fields", nil, "JSON/JSONL fields or CSV columns to store in the payload")
 }
 
-// readDocuments reads all .md and .txt files from a directory
+// loaderOptions returns the record field options set by flags.
+func loaderOptions() loader.Options {
+	opts := loader.DefaultOptions()
+	if textField != "" {
+		opts.TextField = textField
+	}
+	if idField != "" {
+		opts.IDField = idField
+	}
+	opts.MetadataFields = metadataFields
+	return opts
+}
+
+// documentLoaders returns the loaders readDocuments uses: the built-in
+// formats plus source code files as plain text.
+func documentLoaders() *loader.Registry {
+	reg := loader.Default(loaderOptions())
+	reg.Register(loader.Text, chunker.CodeExtensions()...)
+	return reg
+}
+
+// readDocuments reads every file under dir that has a loader: text,
+// markdown, source code, HTML, JSON/JSONL, CSV, DOCX and PDF. Multi-record
+// files yield one document per record. Front matter and .meta.json sidecars
+// become document metadata.
+//
+// dir may also be a .zip or .tar.gz archive, or "-" for JSONL records on
+// stdin. Files are selected by --include, --exclude and a .ragtuneignore
+// file at the root of dir.
 func readDocuments(dir string) ([]Document, error) {
 	var docs []Document
+	err := walkDocuments(dir, func(loaded []Document) error {
+		docs = append(docs, loaded...)
+		return nil
+	})
+	return docs, err
+}
+
+// walkDocuments reads the files under dir one at a time, in lexical order,
+// and calls fn with the documents of each, as readDocuments would return them.
+// Archive entries are read in archive order, and stdin one record at a time.
+func walkDocuments(dir string, fn func(docs []Document) error) error {
+	reg := documentLoaders()
+	filter, err := loader.NewFilter(includeGlobs, excludeGlobs)
+	if err != nil {
+		return err
+	}
+	var skipped []string
+	reg.Skipped = func(path string, _ error) {
+		if _, warned := skippedFiles.LoadOrStore(path, true); !warned {
+			skipped = append(skipped, path)
+		}
+	}
+	defer func() { warnSkipped(skipped) }()
+	emit := func(loaded []loader.Document) error {
+		docs := make([]Document, len(loaded))
+		for i, d := range loaded {
+			docs[i] = Document{
+				Path:       d.Path,
+				Content:    d.Content,
+				Metadata:   d.Metadata,
+				Offset:     d.Offset,
+				LineOffset: d.LineOffset,
+			}
+		}
+		return fn(docs)
+	}
+
+	if dir == stdinPath {
+		return loader.ReadJSONL(stdin, "stdin", loaderOptions(), func(d loader.Document) error {
+			return emit([]loader.Document{d})
+		})
+	}
+	if loader.IsArchive(dir) {
+		return reg.WalkArchive(dir, filter, emit)
+	}
+	if info, err := os.Stat(dir); err == nil && info.IsDir() {
+		if err := filter.ReadIgnoreFile(filepath.Join(dir, loader.IgnoreFile)); err != nil {
+			return err
+		}
+	}
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
 		if err != nil {
 			return err
 		}
+		rel = filepath.ToSlash(rel)
 		if info.IsDir() {
+			if rel != "." && filter.SkipsDir(rel) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
-
-		ext := strings.ToLower(filepath.Ext(path))
-		if ext != ".md" && ext != ".txt" {
+		if rel == "." {
+			rel = info.Name() // dir is a single file
+		}
+		if _, ok := reg.For(path); !ok || loader.IsSidecar(path) || !filter.Selects(rel) {
 			return nil
 		}
 
-		content, err := os.ReadFile(path)
+		loaded, err := reg.LoadFile(path)
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", path, err)
+			return err
 		}
-
-		docs = append(docs, Document{
-			Path:    path,
-			Content: string(content),
-		})
-		return nil
+		return emit(loaded)
 	})
+}
 
-	return docs, err
+// skippedFiles holds the files walkDocuments has warned about, so commands
+// that read the same tree more than once warn once.
+var skippedFiles sync.Map
+
+// warnSkipped warns about JSON files that were not loaded because no record
+// has the text field, e.g. package.json in a repository.
+func warnSkipped(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	names := paths
+	more := ""
+	if len(names) > 3 {
+		names, more = names[:3], fmt.Sprintf(" and %d more", len(paths)-3)
+	}
+	fmt.Fprintf(os.Stderr, "Warning: skipped %d JSON files with no %q field (set --text-field to load them): %s%s\n",
+		len(paths), loaderOptions().TextField, strings.Join(names, ", "), more)
 }
 
-// initVectorStore creates the appropriate vector store based on flags
+// initVectorStore creates the appropriate vector store based on flags,
+// with each call limited by --request-timeout.
 func initVectorStore(ctx context.Context) (vectorstore.Store, error) {
+	store, err := connectVectorStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return vectorstore.WithTimeout(store, requestTimeout), nil
+}
+
+// connectVectorStore connects to the store selected by --store.
+func connectVectorStore(ctx context.Context) (vectorstore.Store, error) {
 	switch storeName {
 	case "qdrant":
 		return qdrant.New(ctx, qdrantAddr)
@@ -330,33 +1005,33 @@ func initVectorStore(ctx context.Context) (vectorstore.Store, error) {
 	}
 }
 
-// initEmbedder creates the appropriate embedder based on flags
+// initEmbedder creates the embedder selected by flags, applying any
+// --dims/--quantization transform.
 func initEmbedder() (embedder.Embedder, error) {
-	switch embedderName {
-	case "openai":
-		return embedder.NewOpenAIEmbedder(), nil
-	case "ollama":
-		return embedder.NewOllamaEmbedder(
-			embedder.WithOllamaURL(ollamaAddr),
-			embedder.WithOllamaModel(ollamaModel),
-			embedder.WithOllamaConcurrency(ollamaConcurrency),
-		), nil
-	case "tei":
-		return embedder.NewTEIEmbedder(
-			embedder.WithTEIURL(teiAddr),
-			embedder.WithTEIModel(teiModel),
-		), nil
-	case "cohere":
-		return embedder.NewCohereEmbedder(
-			embedder.WithCohereModel(cohereModel),
-		), nil
-	case "voyage":
-		return embedder.NewVoyageEmbedder(
-			embedder.WithVoyageModel(voyageModel),
-		), nil
-	default:
-		return nil, fmt.Errorf("unsupported embedder: %s (supported: openai, ollama, tei, cohere, voyage)", embedderName)
+	emb, err := createEmbedder(embedderName)
+	if err != nil {
+		return nil, err
+	}
+	return withVectorTransform(emb, vectorDims, quantization)
+}
+
+// withVectorTransform wraps emb with Matryoshka truncation and quantization.
+// Returns emb unchanged when no transform is requested.
+func withVectorTransform(emb embedder.Embedder, dims int, quant string) (embedder.Embedder, error) {
+	q, err := embedder.ParseQuantization(quant)
+	if err != nil {
+		return nil, err
+	}
+	if dims < 0 {
+		return nil, fmt.Errorf("--dims cannot be negative")
+	}
+	if dims == 0 && q == embedder.QuantizationNone {
+		return emb, nil
 	}
+	return embedder.NewTransformEmbedder(emb,
+		embedder.WithDims(dims),
+		embedder.WithQuantization(q),
+	), nil
 }
 
 // sanitizeString removes invalid UTF-8 and control characters for gRPC compatibility.
@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/chunker"
+)
+
+func TestFormatCost(t *testing.T) {
+	tests := []struct {
+		usd  float64
+		want string
+	}{
+		{0, "free"},
+		{0.000002, "<$0.0001"},
+		{0.0004, "$0.0004"},
+		{12.345, "$12.35"},
+	}
+
+	for _, tt := range tests {
+		if got := formatCost(tt.usd); got != tt.want {
+			t.Errorf("formatCost(%v) = %q, want %q", tt.usd, got, tt.want)
+		}
+	}
+}
+
+func TestEmbedChunks_OrderAndProgress(t *testing.T) {
+	chunks := make([]chunker.Chunk, 150)
+	for i := range chunks {
+		chunks[i] = chunker.Chunk{Text: strings.Repeat("x", i+1)}
+	}
+
+	var progress []int
+	vectors, err := embedChunks(context.Background(), &stubEmbedder{dim: 4}, chunks, func(done, total int) {
+		progress = append(progress, done)
+	})
+	if err != nil {
+		t.Fatalf("embedChunks failed: %v", err)
+	}
+
+	if len(vectors) != len(chunks) {
+		t.Fatalf("got %d vectors, want %d", len(vectors), len(chunks))
+	}
+	// stubEmbedder derives vectors from text length, so order is checkable
+	for _, i := range []int{0, 63, 64, 149} {
+		want, _ := (&stubEmbedder{dim: 4}).Embed(context.Background(), chunks[i].Text)
+		if vectors[i][0] != want[0] || vectors[i][1] != want[1] {
+			t.Errorf("vector %d does not match its chunk", i)
+		}
+	}
+	// No advertised limits: default batch size
+	want := []int{64, 128, 150}
+	if len(progress) != len(want) || progress[2] != 150 {
+		t.Errorf("progress = %v, want %v", progress, want)
+	}
+}
+
+func TestChunkPayload(t *testing.T) {
+	payload := chunkPayload(chunker.Chunk{Text: "body", Source: "docs/auth.md", Index: 2})
+	if payload["text"] != "body" || payload["source"] != "docs/auth.md" || payload["chunk_id"] != 2 {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+	if _, ok := payload["heading_path"]; ok {
+		t.Error("heading_path should be omitted for chunks without headings")
+	}
+
+	payload = chunkPayload(chunker.Chunk{Text: "body", Heading: "Auth > Keys"})
+	if payload["heading_path"] != "Auth > Keys" {
+		t.Errorf("heading_path = %v, want %q", payload["heading_path"], "Auth > Keys")
+	}
+	if _, ok := payload["start_line"]; ok {
+		t.Error("line range should be omitted when unknown")
+	}
+
+	payload = chunkPayload(chunker.Chunk{Text: "func F() {}", Symbol: "F", StartLine: 10, EndLine: 12})
+	if payload["symbol"] != "F" || payload["start_line"] != 10 || payload["end_line"] != 12 {
+		t.Errorf("unexpected code payload: %v", payload)
+	}
+}
+
+func TestChunkDocuments_Provenance(t *testing.T) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() { chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap }()
+	chunkerName, chunkSize, chunkOverlap = "paragraph", 40, 0
+
+	doc := "# Auth\n\nKeys identify a project.\n\nRotate them every 90 days."
+	chunks, err := chunkDocuments(context.Background(), nil, []Document{{Path: "docs/auth.md", Content: doc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d", len(chunks))
+	}
+
+	payload := chunkPayload(chunks[1])
+	want := map[string]interface{}{
+		"start_offset":  34,
+		"end_offset":    60,
+		"start_line":    5,
+		"end_line":      5,
+		"title":         "Auth",
+		"doc_hash":      chunker.HashDocument(doc),
+		"chunker":       "paragraph",
+		"chunk_size":    40,
+		"chunk_overlap": 0,
+	}
+	for k, v := range want {
+		if payload[k] != v {
+			t.Errorf("payload[%q] = %v, want %v", k, payload[k], v)
+		}
+	}
+}
+
+func TestChunkDocuments_ParentChild(t *testing.T) {
+	oldName, oldSize, oldOverlap, oldParent := chunkerName, chunkSize, chunkOverlap, parentSize
+	defer func() { chunkerName, chunkSize, chunkOverlap, parentSize = oldName, oldSize, oldOverlap, oldParent }()
+	chunkerName, chunkSize, chunkOverlap, parentSize = "sentence", 30, 0, 60
+
+	doc := "Keys identify a project. Rotate them often. Tokens expire hourly. Refresh them early."
+	chunks, err := chunkDocuments(context.Background(), nil, []Document{{Path: "auth.txt", Content: doc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 children, got %d", len(chunks))
+	}
+
+	first, last := chunkPayload(chunks[0]), chunkPayload(chunks[3])
+	if first["parent_id"] == "" || first["parent_id"] == last["parent_id"] {
+		t.Errorf("children of different parents should have distinct parent IDs: %v, %v", first["parent_id"], last["parent_id"])
+	}
+	if first["parent_text"] != "Keys identify a project. Rotate them often." {
+		t.Errorf("parent_text = %q", first["parent_text"])
+	}
+	if first["parent_size"] != 60 || first["chunk_size"] != 30 {
+		t.Errorf("parent_size = %v, chunk_size = %v", first["parent_size"], first["chunk_size"])
+	}
+	if last["chunk_id"] != 3 || last["start_offset"] != 66 {
+		t.Errorf("children should be numbered and located in the document: %v", last)
+	}
+
+	parentSize = 30
+	if _, err := chunkDocuments(context.Background(), nil, []Document{{Path: "auth.txt", Content: doc}}); err == nil {
+		t.Error("expected error when --parent-size is not larger than --chunk-size")
+	}
+}
+
+func TestChunkDocuments_Enrich(t *testing.T) {
+	oldName, oldSize, oldOverlap, oldTmpl := chunkerName, chunkSize, chunkOverlap, enrichTemplate
+	defer func() { chunkerName, chunkSize, chunkOverlap, enrichTemplate = oldName, oldSize, oldOverlap, oldTmpl }()
+	chunkerName, chunkSize, chunkOverlap = "markdown", 200, 0
+	enrichTemplate = `{{title}} — {{heading_path}}\n{{text}}`
+
+	// The loader moves front matter into Metadata
+	doc := Document{
+		Path:     "auth.md",
+		Content:  "# Auth\n\n## Keys\n\nRotate them every 90 days.",
+		Metadata: map[string]interface{}{"title": "Auth Guide"},
+	}
+	chunks, err := chunkDocuments(context.Background(), nil, []Document{doc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := chunks[len(chunks)-1]
+	if want := "Auth Guide — Auth > Keys\n" + last.Text; last.EmbedText() != want {
+		t.Errorf("EmbedText() = %q, want %q", last.EmbedText(), want)
+	}
+	payload := chunkPayload(last)
+	if payload["text"] != last.Text || payload["enrich_template"] != "{{title}} — {{heading_path}}\n{{text}}" {
+		t.Errorf("unexpected payload: %v", payload)
+	}
+
+	enrichTemplate = "{{title}}"
+	if _, err := chunkDocuments(context.Background(), nil, []Document{doc}); err == nil {
+		t.Error("expected error for template without {{text}}")
+	}
+}
+
+func TestReadDocuments_SourceFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"guide.md":  "# Guide",
+		"main.go":   "package main",
+		"app.py":    "print(1)",
+		"image.png": "binary",
+		"notes.bin": "{}",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, d := range docs {
+		names = append(names, filepath.Base(d.Path))
+	}
+	if strings.Join(names, ",") != "app.py,guide.md,main.go" {
+		t.Errorf("read %v, want app.py, guide.md, main.go", names)
+	}
+}
+
+func TestReadDocuments_Records(t *testing.T) {
+	oldText, oldID, oldMeta := textField, idField, metadataFields
+	defer func() { textField, idField, metadataFields = oldText, oldID, oldMeta }()
+	textField, idField, metadataFields = "body", "id", []string{"product"}
+
+	dir := t.TempDir()
+	kb := `{"id": "kb-1", "body": "Reset passwords from the login page.", "product": "auth"}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "kb.jsonl"), []byte(kb), 0644); err != nil {
+		t.Fatal(err)
+	}
+	page := "<html><body><nav>Menu</nav><h1>Billing</h1><p>Invoices are monthly.</p></body></html>"
+	if err := os.WriteFile(filepath.Join(dir, "billing.html"), []byte(page), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 docs, got %d", len(docs))
+	}
+	if docs[0].Content != "# Billing\n\nInvoices are monthly." {
+		t.Errorf("HTML content = %q", docs[0].Content)
+	}
+	if filepath.Base(docs[1].Path) != "kb.jsonl#kb-1" {
+		t.Errorf("record path = %s, want kb.jsonl#kb-1", docs[1].Path)
+	}
+
+	chunks, err := chunkWith(context.Background(), mustStrategy(t, "fixed"), nil, chunker.Params{}, docs[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := chunkPayload(chunks[0])
+	if payload["product"] != "auth" || payload["text"] != "Reset passwords from the login page." {
+		t.Errorf("metadata should be stored in the payload: %v", payload)
+	}
+}
+
+func TestReadDocuments_SkipsJSONWithoutText(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"guide.md":      "# Guide\n\nInstall the CLI.",
+		"package.json":  `{"name": "site", "version": "1.0.0"}`,
+		"tsconfig.json": `{"compilerOptions": {"strict": true}}`,
+		"kb.json":       `[{"id": 1, "text": "Keys expire yearly."}, {"id": 2, "title": "No text"}]`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		t.Fatalf("JSON files without a text field should be skipped, got %v", err)
+	}
+	var names []string
+	for _, d := range docs {
+		names = append(names, filepath.Base(d.Path))
+	}
+	if strings.Join(names, ",") != "guide.md,kb.json#1" {
+		t.Errorf("read %v, want guide.md, kb.json#1", names)
+	}
+	for _, name := range []string{"package.json", "tsconfig.json"} {
+		if _, ok := skippedFiles.Load(filepath.Join(dir, name)); !ok {
+			t.Errorf("%s was not reported as skipped", name)
+		}
+	}
+}
+
+func TestReadDocuments_FrontMatter(t *testing.T) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() { chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap }()
+	chunkerName, chunkSize, chunkOverlap = "paragraph", 40, 0
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.md")
+	content := "---\ntitle: Key Rotation\nproduct: auth\n---\n# Keys\n\nRotate them every 90 days."
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".meta.json", []byte(`{"version": "2.0"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := readDocuments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 {
+		t.Fatalf("expected the sidecar to be skipped, got %d docs", len(docs))
+	}
+	chunks, err := chunkDocuments(context.Background(), nil, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		if strings.Contains(ch.Text, "product:") {
+			t.Errorf("front matter should be stripped from chunk text: %q", ch.Text)
+		}
+	}
+
+	last := chunks[len(chunks)-1]
+	payload := chunkPayload(last)
+	want := map[string]interface{}{
+		"title":      "Key Rotation",
+		"product":    "auth",
+		"version":    "2.0",
+		"start_line": 5,
+	}
+	for k, v := range want {
+		if payload[k] != v {
+			t.Errorf("payload[%q] = %v, want %v", k, payload[k], v)
+		}
+	}
+	if got := content[payload["start_offset"].(int):payload["end_offset"].(int)]; got != last.Text {
+		t.Errorf("offsets should point into the file, got %q", got)
+	}
+}
+
+func mustStrategy(t *testing.T, name string) chunker.Strategy {
+	t.Helper()
+	c, err := chunker.NewStrategy(name, 512, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReadDocuments_Filters(t *testing.T) {
+	oldInclude, oldExclude := includeGlobs, excludeGlobs
+	defer func() { includeGlobs, excludeGlobs = oldInclude, oldExclude }()
+
+	dir := t.TempDir()
+	for _, name := range []string{"guide.md", "CHANGELOG.md", "notes.txt", "drafts/idea.md", "node_modules/pkg/README.md", "api/spec.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# "+name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".ragtuneignore"), []byte("node_modules/\ndrafts/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		t.Helper()
+		docs, err := readDocuments(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, d := range docs {
+			rel, _ := filepath.Rel(dir, d.Path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := read(); got != "CHANGELOG.md,api/spec.md,guide.md,notes.txt" {
+		t.Errorf(".ragtuneignore: read %s", got)
+	}
+	includeGlobs, excludeGlobs = []string{"*.md"}, []string{"CHANGELOG.md"}
+	if got := read(); got != "api/spec.md,guide.md" {
+		t.Errorf("--include/--exclude: read %s", got)
+	}
+	excludeGlobs = []string{"[bad"}
+	if _, err := readDocuments(dir); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestReadDocuments_Stdin(t *testing.T) {
+	old := stdin
+	defer func() { stdin = old }()
+	stdin = strings.NewReader(`{"id": "a", "text": "First record."}` + "\n\n" + `{"id": "b", "text": "Second record."}` + "\n")
+
+	var calls int
+	var docs []Document
+	err := walkDocuments(stdinPath, func(loaded []Document) error {
+		calls++
+		docs = append(docs, loaded...)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(docs) != 2 {
+		t.Fatalf("got %d docs in %d calls, want one record per call", len(docs), calls)
+	}
+	if docs[0].Path != "stdin#a" || docs[1].Content != "Second record." {
+		t.Errorf("docs = %+v", docs)
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+// maxKRange caps --k-range so a typo does not retrieve thousands of results per query.
+const maxKRange = 1000
+
+// parseKRange parses a list of cutoffs such as "1-20", "1,3,5,10" or
+// "1-5,10,20" into sorted, distinct values.
+func parseKRange(s string) ([]int, error) {
+	var ks []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, fmt.Errorf("invalid k %q in --k-range", part)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(strings.TrimSpace(hi)); err != nil {
+				return nil, fmt.Errorf("invalid k range %q in --k-range", part)
+			}
+		}
+		if from < 1 || to < from {
+			return nil, fmt.Errorf("invalid k range %q in --k-range: k must be at least 1 and ranges ascending", part)
+		}
+		if to > maxKRange {
+			return nil, fmt.Errorf("k %d in --k-range exceeds the maximum of %d", to, maxKRange)
+		}
+		for k := from; k <= to; k++ {
+			ks = append(ks, k)
+		}
+	}
+	if len(ks) == 0 {
+		return nil, fmt.Errorf("--k-range is empty")
+	}
+	slices.Sort(ks)
+	return slices.Compact(ks), nil
+}
+
+// cutResults returns copies of results cut to their top k, so metrics
+// computed from a deeper retrieval match a search at depth k.
+func cutResults(results []metrics.QueryResult, k int) []metrics.QueryResult {
+	out := make([]metrics.QueryResult, len(results))
+	for i, r := range results {
+		if len(r.RetrievedIDs) > k {
+			r.RetrievedIDs = r.RetrievedIDs[:k]
+		}
+		if len(r.Scores) > k {
+			r.Scores = r.Scores[:k]
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// printKCurve prints metrics at each cutoff and the recommended k and
+// minimum score.
+func printKCurve(curve []metrics.KPoint, fit *metrics.ThresholdFit, targetPrecision float64) {
+	fmt.Printf("\n  Metrics by K:\n")
+	fmt.Printf("    %4s  %6s  %9s  %6s  %6s  %6s\n", "K", "Recall", "Precision", "F1", "MRR", "NDCG")
+	for _, p := range curve {
+		fmt.Printf("    %4d  %6.3f  %9.3f  %6.3f  %6.3f  %6.3f\n", p.K, p.Recall, p.Precision, p.F1, p.MRR, p.NDCG)
+	}
+
+	fmt.Println()
+	if fit == nil {
+		if targetPrecision > 0 {
+			fmt.Printf("  No k and min_score in the range reach precision %.2f\n", targetPrecision)
+		} else {
+			fmt.Printf("  No k and min_score recommended: results carry no scores or relevant docs\n")
+		}
+		return
+	}
+	fmt.Printf("  Recommended: k=%d, min_score=%.2f\n", fit.K, fit.MinScore)
+	fmt.Printf("    %s  (precision=%.3f, recall=%.3f, F1=%.3f, %.1f results/query)\n",
+		describeFit(fit), fit.Precision, fit.Recall, fit.F1, fit.AvgResults)
+}
+
+// describeFit states what a threshold fit optimized.
+func describeFit(fit *metrics.ThresholdFit) string {
+	if fit.TargetPrecision > 0 {
+		return fmt.Sprintf("Best recall at precision >= %.2f", fit.TargetPrecision)
+	}
+	return "Best F1"
+}
+
+// recallBar draws a recall in [0, 1] as a bar of width cells.
+func recallBar(recall float64, width int) string {
+	filled := int(recall*float64(width) + 0.5)
+	filled = max(0, min(filled, width))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+func TestParseKRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "1-5", want: []int{1, 2, 3, 4, 5}},
+		{in: "1,3,5,10", want: []int{1, 3, 5, 10}},
+		{in: "10, 1-3, 2", want: []int{1, 2, 3, 10}},
+		{in: "7", want: []int{7}},
+		{in: "", wantErr: true},
+		{in: "0-5", wantErr: true},
+		{in: "5-1", wantErr: true},
+		{in: "1-x", wantErr: true},
+		{in: "five", wantErr: true},
+		{in: "1-5000", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseKRange(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseKRange(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("parseKRange(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestCutResults(t *testing.T) {
+	deep := []metrics.QueryResult{{
+		QueryID:      "q1",
+		RetrievedIDs: []string{"x", "y", "a"},
+		RelevantIDs:  []string{"a"},
+		Scores:       []float32{0.9, 0.8, 0.7},
+	}}
+	cut := cutResults(deep, 2)
+	if len(cut[0].RetrievedIDs) != 2 || len(cut[0].Scores) != 2 {
+		t.Fatalf("cut to %v / %v, want 2 results", cut[0].RetrievedIDs, cut[0].Scores)
+	}
+	// MRR of the deep results would credit rank 3
+	if m := metrics.Compute(cut, 2); m.MRR != 0 {
+		t.Errorf("MRR at k=2 = %v, want 0", m.MRR)
+	}
+	if len(deep[0].RetrievedIDs) != 3 {
+		t.Error("cutResults modified its input")
+	}
+}
+
+func TestGenerateMarkdownReport_KCurve(t *testing.T) {
+	run := RunResult{
+		Configs: []ConfigResult{{
+			Config: config.SimConfig{Name: "default", TopK: 5},
+			KCurve: []metrics.KPoint{
+				{K: 1, Recall: 0.5, Precision: 1},
+				{K: 5, Recall: 1, Precision: 0.4},
+			},
+			Threshold: &metrics.ThresholdFit{K: 5, MinScore: 0.62, Precision: 0.9, Recall: 0.95},
+		}},
+	}
+	out, err := generateMarkdownReport(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Metrics by K", "k=5, min_score=0.62", "| 5 | 1.000 | 0.400 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+}
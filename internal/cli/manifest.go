@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/embedder"
+)
+
+// manifestVersion is bumped when the manifest format changes incompatibly.
+const manifestVersion = 1
+
+// defaultManifestDir holds manifests when --manifest is not given.
+const defaultManifestDir = ".ragtune/manifests"
+
+// ingestManifest records what an incremental ingest wrote to a collection:
+// for each document path, the hash of its content and metadata and the IDs
+// of its chunks. The next run compares documents against it to embed only
+// what changed and delete chunks that no longer exist.
+type ingestManifest struct {
+	Version    int                      `json:"version"`
+	Store      string                   `json:"store"`
+	Collection string                   `json:"collection"`
+	Config     manifestConfig           `json:"config"`
+	Documents  map[string]manifestEntry `json:"documents"`
+}
+
+// manifestEntry is one document in the manifest. SharedIDs are the kept
+// chunks of other documents that stand in for the document's duplicate
+// chunks (ingest --dedup skip or merge); they are not deleted while any
+// entry refers to them.
+type manifestEntry struct {
+	Hash      string   `json:"hash"`
+	ChunkIDs  []string `json:"chunk_ids"`
+	SharedIDs []string `json:"shared_ids,omitempty"`
+}
+
+// manifestConfig holds the settings that change chunks or vectors of
+// unchanged documents. If any differs from the manifest, every document is
+// re-ingested.
+type manifestConfig struct {
+	Embedder       string  `json:"embedder"`
+	Dim            int     `json:"dim"`
+	Quantization   string  `json:"quantization,omitempty"`
+	TokenPolicy    string  `json:"token_policy,omitempty"`
+	MaxInputTokens int     `json:"max_input_tokens,omitempty"`
+	Chunker        string  `json:"chunker"`
+	ChunkSize      int     `json:"chunk_size"`
+	ChunkOverlap   int     `json:"chunk_overlap"`
+	ParentSize     int     `json:"parent_size,omitempty"`
+	Enrich         string  `json:"enrich,omitempty"`
+	Breadcrumb     bool    `json:"breadcrumb,omitempty"`
+	Breakpoint     float64 `json:"breakpoint_percentile,omitempty"`
+	MinChunkSize   int     `json:"min_chunk_size,omitempty"`
+	Dedup          string  `json:"dedup,omitempty"`
+}
+
+// manifestPlan accumulates the difference between the documents on disk and
+// a manifest as files are read.
+type manifestPlan struct {
+	Added, Updated, Deleted, Unchanged int
+
+	// Removed lists paths in the manifest that are no longer on disk.
+	Removed []string
+
+	m        *ingestManifest
+	reingest bool              // settings changed: every document is updated
+	hashes   map[string]string // hash of every document path seen
+	changed  map[string]bool   // added and updated paths
+}
+
+// currentManifestConfig returns the manifest config for the ingest flags.
+func currentManifestConfig(emb embedder.Embedder, dim int) manifestConfig {
+	name := embedderName
+	if info := embedder.Describe(emb); info.Model != "" {
+		name = info.Provider + "/" + info.Model
+	}
+	cfg := manifestConfig{
+		Embedder:       name,
+		Dim:            dim,
+		Quantization:   quantization,
+		TokenPolicy:    tokenPolicy,
+		MaxInputTokens: maxInputTokens,
+		Chunker:        chunkerName,
+		ChunkSize:      chunkSize,
+		ChunkOverlap:   chunkOverlap,
+		ParentSize:     parentSize,
+		Enrich:         enrichTemplate,
+		Breadcrumb:     breadcrumb,
+		Dedup:          dedupConfig(),
+	}
+	if preChunked {
+		cfg.Chunker, cfg.ChunkSize, cfg.ChunkOverlap = "pre-chunked", 0, 0
+	}
+	if cfg.Chunker == chunker.StrategySemantic {
+		cfg.Breakpoint = breakpointPercentile
+		cfg.MinChunkSize = minChunkSize
+	}
+	return cfg
+}
+
+// manifestPath returns the manifest file for the collection: path if set,
+// else <defaultManifestDir>/<store>-<collection>.json.
+func manifestPath(path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(defaultManifestDir, storeName+"-"+collectionName+".json")
+}
+
+// newManifest returns an empty manifest.
+func newManifest() *ingestManifest {
+	return &ingestManifest{Version: manifestVersion, Documents: map[string]manifestEntry{}}
+}
+
+// loadManifest reads the manifest at path. A missing file yields an empty
+// manifest, so the first incremental run ingests every document.
+func loadManifest(path string) (*ingestManifest, error) {
+	m := newManifest()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	if m.Version != manifestVersion {
+		return nil, fmt.Errorf("manifest %s has version %d, expected %d (delete it to re-ingest)", path, m.Version, manifestVersion)
+	}
+	if m.Documents == nil {
+		m.Documents = map[string]manifestEntry{}
+	}
+	return m, nil
+}
+
+// save writes the manifest to path atomically, creating parent directories.
+func (m *ingestManifest) save(path string) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create manifest directory: %w", err)
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// chunkCount returns the number of chunks recorded in the manifest.
+func (m *ingestManifest) chunkCount() int {
+	n := 0
+	for _, e := range m.Documents {
+		n += len(e.ChunkIDs)
+	}
+	return n
+}
+
+// plan starts comparing documents against the manifest. When cfg differs
+// from the config the manifest was written with, every document counts as
+// updated.
+func (m *ingestManifest) plan(cfg manifestConfig) *manifestPlan {
+	return &manifestPlan{
+		m:        m,
+		reingest: m.Config != cfg,
+		hashes:   map[string]string{},
+		changed:  map[string]bool{},
+	}
+}
+
+// check classifies the documents of one file and returns those that are new
+// or changed and must be ingested.
+func (p *manifestPlan) check(docs []Document) []Document {
+	var changed []Document
+	for path, hash := range documentHashes(docs) {
+		p.hashes[path] = hash
+		entry, known := p.m.Documents[path]
+		switch {
+		case !known:
+			p.Added++
+		case !p.reingest && entry.Hash == hash:
+			p.Unchanged++
+			continue
+		default:
+			p.Updated++
+		}
+		p.changed[path] = true
+	}
+	for _, doc := range docs {
+		if p.changed[doc.Path] {
+			changed = append(changed, doc)
+		}
+	}
+	return changed
+}
+
+// finish records the manifest's documents that check never saw as removed.
+func (p *manifestPlan) finish() {
+	p.Removed = p.Removed[:0]
+	for path := range p.m.Documents {
+		if _, ok := p.hashes[path]; !ok {
+			p.Removed = append(p.Removed, path)
+		}
+	}
+	sort.Strings(p.Removed)
+	p.Deleted = len(p.Removed)
+}
+
+// apply records the chunk IDs written for the plan's changed documents,
+// and the kept chunk IDs their dropped duplicates share, both keyed by
+// source, and drops removed documents. It returns the IDs of chunks the
+// manifest held for changed or removed documents that no entry refers to
+// any more: these are stale and must be deleted from the collection. A kept
+// chunk outlives its own document while a duplicate's document refers to it.
+func (m *ingestManifest) apply(p *manifestPlan, written, shared map[string][]string, cfg manifestConfig) []string {
+	var old []string
+	for path := range p.changed {
+		old = append(old, m.Documents[path].ChunkIDs...)
+		old = append(old, m.Documents[path].SharedIDs...)
+		ids := slices.Clone(shared[path])
+		slices.Sort(ids)
+		m.Documents[path] = manifestEntry{Hash: p.hashes[path], ChunkIDs: written[path], SharedIDs: slices.Compact(ids)}
+	}
+	for _, path := range p.Removed {
+		old = append(old, m.Documents[path].ChunkIDs...)
+		old = append(old, m.Documents[path].SharedIDs...)
+		delete(m.Documents, path)
+	}
+	m.Config = cfg
+	if len(old) == 0 {
+		return nil
+	}
+
+	live := make(map[string]bool)
+	for _, e := range m.Documents {
+		for _, id := range e.ChunkIDs {
+			live[id] = true
+		}
+		for _, id := range e.SharedIDs {
+			live[id] = true
+		}
+	}
+	var stale []string
+	for _, id := range old {
+		if !live[id] {
+			live[id] = true // each ID once
+			stale = append(stale, id)
+		}
+	}
+	return stale
+}
+
+// documentHashes returns the hash of each document path over its content and
+// metadata. Records sharing a path are hashed together, in order.
+func documentHashes(docs []Document) map[string]string {
+	type part struct {
+		Content  string                 `json:"content"`
+		Metadata map[string]interface{} `json:"metadata,omitempty"`
+	}
+	parts := make(map[string][]part)
+	for _, doc := range docs {
+		parts[doc.Path] = append(parts[doc.Path], part{doc.Content, doc.Metadata})
+	}
+
+	hashes := make(map[string]string, len(parts))
+	for path, p := range parts {
+		// json.Marshal sorts map keys, so equal metadata encodes identically
+		data, err := json.Marshal(p)
+		if err != nil {
+			data = []byte(fmt.Sprint(p))
+		}
+		sum := sha256.Sum256(data)
+		hashes[path] = hex.EncodeToString(sum[:])
+	}
+	return hashes
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+// syncManifest runs one incremental ingest of docs, given as the files
+// they were read from, into store without embedding: it chunks the changed
+// documents, upserts them and deletes stale chunks, as runIngest does.
+func syncManifest(t *testing.T, store *mock.Store, m *ingestManifest, files [][]Document, cfg manifestConfig) *manifestPlan {
+	t.Helper()
+	ctx := context.Background()
+	plan := m.plan(cfg)
+	var changed []Document
+	for _, docs := range files {
+		changed = append(changed, plan.check(docs)...)
+	}
+	plan.finish()
+
+	chunks, err := chunkDocuments(ctx, nil, changed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	points := make([]vectorstore.Point, len(chunks))
+	written := make(map[string][]string)
+	for i, ch := range chunks {
+		points[i] = vectorstore.Point{ID: ch.ID, Vector: []float32{1, 0}, Payload: chunkPayload(ch)}
+		written[ch.Source] = append(written[ch.Source], ch.ID)
+	}
+	if err := store.Upsert(ctx, "docs", points); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, "docs", m.apply(plan, written, nil, cfg)); err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+// storedSources returns the sorted sources of the points in the collection.
+func storedSources(t *testing.T, store *mock.Store) []string {
+	t.Helper()
+	points, err := store.GetPoints("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sources []string
+	for _, p := range points {
+		sources = append(sources, p.Payload["source"].(string))
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+func TestManifest_Incremental(t *testing.T) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() { chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap }()
+	chunkerName, chunkSize, chunkOverlap = "paragraph", 12, 0
+
+	store := mock.New()
+	if err := store.EnsureCollection(context.Background(), "docs", 2); err != nil {
+		t.Fatal(err)
+	}
+	m := newManifest()
+	cfg := manifestConfig{Chunker: "paragraph", ChunkSize: 12}
+
+	docs := [][]Document{
+		{{Path: "a.md", Content: "Alpha one.\n\nAlpha two."}},
+		{{Path: "b.md", Content: "Beta."}},
+		{{Path: "c.md", Content: "Gamma."}},
+	}
+	plan := syncManifest(t, store, m, docs, cfg)
+	if plan.Added != 3 || plan.Updated+plan.Deleted+plan.Unchanged != 0 {
+		t.Errorf("first run: %+v", plan)
+	}
+	if got := storedSources(t, store); len(got) != 4 {
+		t.Fatalf("stored %v, want 4 chunks", got)
+	}
+
+	// Re-run without changes: nothing to embed
+	plan = syncManifest(t, store, m, docs, cfg)
+	if plan.Unchanged != 3 || plan.Added+plan.Updated+plan.Deleted != 0 {
+		t.Errorf("unchanged run: %+v", plan)
+	}
+
+	// a.md loses a paragraph, b.md gains metadata, c.md is removed, d.md is new
+	docs = [][]Document{
+		{{Path: "a.md", Content: "Alpha one."}},
+		{{Path: "b.md", Content: "Beta.", Metadata: map[string]interface{}{"team": "core"}}},
+		{{Path: "d.md", Content: "Delta."}},
+	}
+	plan = syncManifest(t, store, m, docs, cfg)
+	if plan.Added != 1 || plan.Updated != 2 || plan.Deleted != 1 || plan.Unchanged != 0 {
+		t.Errorf("changed run: %+v", plan)
+	}
+	want := []string{"a.md", "b.md", "d.md"}
+	got := storedSources(t, store)
+	if len(got) != len(want) {
+		t.Fatalf("stored %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stored %v, want %v", got, want)
+		}
+	}
+	if m.chunkCount() != 3 {
+		t.Errorf("manifest has %d chunks, want 3", m.chunkCount())
+	}
+
+	// New settings re-ingest everything
+	plan = m.plan(manifestConfig{Chunker: "paragraph", ChunkSize: 60})
+	for _, file := range docs {
+		if changed := plan.check(file); len(changed) != 1 {
+			t.Errorf("config change: %s not re-ingested", file[0].Path)
+		}
+	}
+	if plan.Updated != 3 {
+		t.Errorf("config change: %+v", plan)
+	}
+}
+
+func TestManifest_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "manifest.json")
+
+	m, err := loadManifest(path)
+	if err != nil {
+		t.Fatalf("missing manifest should load empty: %v", err)
+	}
+	if len(m.Documents) != 0 {
+		t.Errorf("expected empty manifest, got %v", m.Documents)
+	}
+
+	m.Config = manifestConfig{Embedder: "openai/text-embedding-3-small", Dim: 1536, Chunker: "fixed", ChunkSize: 512}
+	m.Documents["a.md"] = manifestEntry{Hash: "abc", ChunkIDs: []string{"1", "2"}}
+	if err := m.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := loadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Config != m.Config || loaded.chunkCount() != 2 || loaded.Documents["a.md"].Hash != "abc" {
+		t.Errorf("round trip mismatch: %+v", loaded)
+	}
+}
+
+func TestManifestPlan_Records(t *testing.T) {
+	m := newManifest()
+	plan := m.plan(manifestConfig{})
+	file := []Document{
+		{Path: "kb.jsonl#1", Content: "one"},
+		{Path: "kb.jsonl#2", Content: "two"},
+	}
+	if got := plan.check(file); len(got) != 2 {
+		t.Fatalf("first run: %d changed records, want 2", len(got))
+	}
+	plan.finish()
+	m.apply(plan, map[string][]string{"kb.jsonl#1": {"c1"}, "kb.jsonl#2": {"c2"}}, nil, manifestConfig{})
+
+	// Only the edited record is re-ingested, and its stale chunk deleted
+	file[1].Content = "two, edited"
+	plan = m.plan(manifestConfig{})
+	got := plan.check(file)
+	if len(got) != 1 || got[0].Path != "kb.jsonl#2" {
+		t.Fatalf("changed = %v, want kb.jsonl#2", got)
+	}
+	plan.finish()
+	stale := m.apply(plan, map[string][]string{"kb.jsonl#2": {"c3"}}, nil, manifestConfig{})
+	if len(stale) != 1 || stale[0] != "c2" {
+		t.Errorf("stale = %v, want [c2]", stale)
+	}
+}
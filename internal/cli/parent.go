@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+// Return modes for simulate and explain.
+const (
+	returnChunk  = "chunk"  // rank the embedded chunks themselves
+	returnParent = "parent" // rank the parents of matched child chunks
+)
+
+// parentSearchFactor over-fetches children in parent mode so that k distinct
+// parents usually survive deduplication.
+const parentSearchFactor = 4
+
+// returnMode is shared by simulate and explain (--return).
+var returnMode string
+
+// validateReturnMode rejects anything but chunk or parent.
+func validateReturnMode(mode string) error {
+	if mode != returnChunk && mode != returnParent {
+		return fmt.Errorf("unsupported --return mode: %s (supported: %s, %s)", mode, returnChunk, returnParent)
+	}
+	return nil
+}
+
+// searchLimit returns how many hits to request from the store for k results.
+func searchLimit(mode string, k int) int {
+	if mode == returnParent {
+		return k * parentSearchFactor
+	}
+	return k
+}
+
+// collapseToParents keeps the best-scoring hit per parent, in score order, up
+// to k results. Each kept hit carries the parent's text in "text" and the
+// matched child's text in "matched_text". Hits without a parent_id (from a
+// flat ingest) are kept as they are and count as their own parent.
+func collapseToParents(results []vectorstore.Result, k int) []vectorstore.Result {
+	seen := make(map[string]bool)
+	var out []vectorstore.Result
+	for _, r := range results {
+		if len(out) == k {
+			break
+		}
+		parentID, _ := r.Payload["parent_id"].(string)
+		if parentID == "" {
+			if !seen[r.ID] {
+				seen[r.ID] = true
+				out = append(out, r)
+			}
+			continue
+		}
+		if seen[parentID] {
+			continue
+		}
+		seen[parentID] = true
+
+		payload := make(map[string]interface{}, len(r.Payload)+1)
+		for key, v := range r.Payload {
+			payload[key] = v
+		}
+		payload["matched_text"] = r.Payload["text"]
+		if text, ok := r.Payload["parent_text"].(string); ok && text != "" {
+			payload["text"] = text
+		}
+		out = append(out, vectorstore.Result{ID: parentID, Score: r.Score, Payload: payload})
+	}
+	return out
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+func TestCollapseToParents(t *testing.T) {
+	child := func(id, parent string, score float32) vectorstore.Result {
+		return vectorstore.Result{ID: id, Score: score, Payload: map[string]interface{}{
+			"text":        "child " + id,
+			"source":      "auth.md",
+			"parent_id":   parent,
+			"parent_text": "parent " + parent,
+		}}
+	}
+	flat := vectorstore.Result{ID: "f1", Score: 0.7, Payload: map[string]interface{}{"text": "flat"}}
+	results := []vectorstore.Result{
+		child("c1", "p1", 0.9),
+		child("c2", "p1", 0.85),
+		child("c3", "p2", 0.8),
+		flat,
+		child("c4", "p3", 0.6),
+	}
+
+	got := collapseToParents(results, 3)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(got))
+	}
+	wantIDs := []string{"p1", "p2", "f1"}
+	for i, id := range wantIDs {
+		if got[i].ID != id {
+			t.Errorf("result %d ID = %s, want %s", i, got[i].ID, id)
+		}
+	}
+	if got[0].Score != 0.9 {
+		t.Errorf("parent should keep its best child's score, got %v", got[0].Score)
+	}
+	if got[0].Payload["text"] != "parent p1" || got[0].Payload["matched_text"] != "child c1" {
+		t.Errorf("unexpected parent payload: %v", got[0].Payload)
+	}
+	if results[0].Payload["text"] != "child c1" {
+		t.Error("collapseToParents should not modify the store's payloads")
+	}
+	if got[2].Payload["text"] != "flat" {
+		t.Errorf("hits without a parent should be kept as-is, got %v", got[2].Payload)
+	}
+}
+
+func TestReturnMode(t *testing.T) {
+	if err := validateReturnMode(returnParent); err != nil {
+		t.Errorf("parent should be valid: %v", err)
+	}
+	if err := validateReturnMode("document"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+	if got := searchLimit(returnChunk, 5); got != 5 {
+		t.Errorf("searchLimit(chunk, 5) = %d, want 5", got)
+	}
+	if got := searchLimit(returnParent, 5); got != 5*parentSearchFactor {
+		t.Errorf("searchLimit(parent, 5) = %d, want %d", got, 5*parentSearchFactor)
+	}
+}
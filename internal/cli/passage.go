@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/config"
+)
+
+// defaultMinOverlap is the default --min-overlap.
+const defaultMinOverlap = 0.5
+
+// validateMinOverlap checks a --min-overlap value.
+func validateMinOverlap(v float64) error {
+	if v <= 0 || v > 1 {
+		return fmt.Errorf("--min-overlap must be greater than 0 and at most 1, got %g", v)
+	}
+	return nil
+}
+
+// validateMaxChunkRatio checks a --max-chunk-ratio value.
+func validateMaxChunkRatio(v float64) error {
+	if v < 0 {
+		return fmt.Errorf("--max-chunk-ratio cannot be negative, got %g", v)
+	}
+	return nil
+}
+
+// passageID returns the ID a retrieved chunk is scored as: the label of the
+// first passage of q in source that the chunk covers by at least the minimum
+// overlap, and is at most maxRatio times as long as (0 = any length), or
+// source itself, which does not match a document with passages. Spans and
+// chunk IDs only match chunks stored under the passage's document; quotes
+// also match copies merged from it (ingest --dedup merge).
+func passageID(payload map[string]interface{}, source string, q config.Query, minOverlap, maxRatio float64) string {
+	own := normalizeSource(filepath.Base(getPayloadString(payload, "source")), q.RelevantDocs)
+	for _, p := range q.Passages {
+		if p.Doc != source {
+			continue
+		}
+		threshold := minOverlap
+		if p.MinOverlap > 0 {
+			threshold = p.MinOverlap
+		}
+		coverage, ratio := passageOverlap(payload, p, own == source)
+		if coverage >= threshold && (maxRatio == 0 || ratio <= maxRatio) {
+			return p.Label()
+		}
+	}
+	return source
+}
+
+// passageOverlap returns the fraction of a passage a chunk covers, 1 when
+// the chunk contains it, and the ratio of the chunk's length to the
+// passage's, which is 0 for chunk IDs. A chunk ID match is all or nothing.
+// own reports whether the chunk is stored under the passage's document, so
+// its chunk ID and offsets apply.
+func passageOverlap(payload map[string]interface{}, p config.Passage, own bool) (coverage, ratio float64) {
+	switch {
+	case p.Chunk != nil:
+		if id, ok := getPayloadInt(payload, "chunk_id"); own && ok && id == *p.Chunk {
+			return 1, 0
+		}
+		return 0, 0
+	case p.Quote != "":
+		text, _ := payload["text"].(string)
+		return quoteOverlap(text, p.Quote)
+	default:
+		start, ok := getPayloadInt(payload, "start_offset")
+		end, ok2 := getPayloadInt(payload, "end_offset")
+		if !own || !ok || !ok2 || end <= start {
+			return 0, 0 // Collections ingested without offsets cannot match spans
+		}
+		shared := min(end, p.End) - max(start, p.Start)
+		if shared <= 0 {
+			return 0, 0
+		}
+		return float64(shared) / float64(p.End-p.Start), float64(end-start) / float64(p.End-p.Start)
+	}
+}
+
+// quoteOverlap returns the fraction of a quote a chunk's text covers, with
+// whitespace collapsed, and the ratio of their lengths. The chunk covers
+// the whole quote when it contains it, all of itself when it is inside the
+// quote, or the longest start of the quote it ends with or end it starts
+// with, as when the quote crosses a chunk boundary.
+func quoteOverlap(text, quote string) (coverage, ratio float64) {
+	text = strings.Join(strings.Fields(text), " ")
+	quote = strings.Join(strings.Fields(quote), " ")
+	if text == "" || quote == "" {
+		return 0, 0
+	}
+	shared := 0
+	if strings.Contains(text, quote) || strings.Contains(quote, text) {
+		shared = min(len(text), len(quote))
+	}
+	for n := min(len(text), len(quote)) - 1; n > shared; n-- {
+		if strings.HasSuffix(text, quote[:n]) || strings.HasPrefix(text, quote[len(quote)-n:]) {
+			shared = n
+		}
+	}
+	if shared == 0 {
+		return 0, 0
+	}
+	return float64(shared) / float64(len(quote)), float64(len(text)) / float64(len(quote))
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/metrics"
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+func TestQuoteOverlap(t *testing.T) {
+	tests := []struct {
+		name                    string
+		text, quote             string
+		wantCoverage, wantRatio float64
+	}{
+		{"quote inside chunk", "To rotate a key, open Settings and click Rotate.", "open Settings and click Rotate", 1, 48.0 / 30},
+		{"chunk inside quote", "click Rotate", "open Settings and click Rotate now", 12.0 / 34, 12.0 / 34},
+		{"whitespace ignored", "open\n  Settings", "open Settings", 1, 1},
+		{"quote starts at chunk end", "first part. open Settings", "open Settings and click Rotate", 13.0 / 30, 25.0 / 30},
+		{"quote ends at chunk start", "click Rotate. Later text here", "open Settings and click Rotate", 12.0 / 30, 29.0 / 30},
+		{"unrelated", "billing and invoices", "open Settings and click Rotate", 0, 0},
+		{"empty chunk", "", "anything", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coverage, ratio := quoteOverlap(tt.text, tt.quote)
+			if math.Abs(coverage-tt.wantCoverage) > 1e-9 || math.Abs(ratio-tt.wantRatio) > 1e-9 {
+				t.Errorf("quoteOverlap() = %v, %v, want %v, %v", coverage, ratio, tt.wantCoverage, tt.wantRatio)
+			}
+		})
+	}
+}
+
+func TestPassageOverlap(t *testing.T) {
+	chunk := map[string]interface{}{
+		"source":       "auth.md",
+		"text":         "rotate keys every 90 days",
+		"chunk_id":     float64(3), // as decoded from JSON
+		"start_offset": 100,
+		"end_offset":   200,
+	}
+	three, four := 3, 4
+	tests := []struct {
+		name                    string
+		p                       config.Passage
+		own                     bool
+		wantCoverage, wantRatio float64
+	}{
+		{"chunk ID", config.Passage{Doc: "auth.md", Chunk: &three}, true, 1, 0},
+		{"other chunk ID", config.Passage{Doc: "auth.md", Chunk: &four}, true, 0, 0},
+		{"chunk ID of a merged copy", config.Passage{Doc: "auth.md", Chunk: &three}, false, 0, 0},
+		{"span inside chunk", config.Passage{Doc: "auth.md", Start: 120, End: 160}, true, 1, 2.5},
+		{"span half covered", config.Passage{Doc: "auth.md", Start: 150, End: 250}, true, 0.5, 1},
+		{"span matches chunk", config.Passage{Doc: "auth.md", Start: 100, End: 200}, true, 1, 1},
+		{"chunk inside span", config.Passage{Doc: "auth.md", Start: 50, End: 450}, true, 0.25, 0.25},
+		{"span after chunk", config.Passage{Doc: "auth.md", Start: 200, End: 300}, true, 0, 0},
+		{"quote", config.Passage{Doc: "auth.md", Quote: "every 90 days"}, true, 1, 25.0 / 13},
+		{"quote is chunk", config.Passage{Doc: "auth.md", Quote: "rotate keys  every 90 days"}, true, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coverage, ratio := passageOverlap(chunk, tt.p, tt.own)
+			if math.Abs(coverage-tt.wantCoverage) > 1e-9 || math.Abs(ratio-tt.wantRatio) > 1e-9 {
+				t.Errorf("passageOverlap() = %v, %v, want %v, %v", coverage, ratio, tt.wantCoverage, tt.wantRatio)
+			}
+		})
+	}
+
+	// Without offsets spans cannot match
+	noOffsets := map[string]interface{}{"source": "auth.md", "text": "x"}
+	if got, _ := passageOverlap(noOffsets, config.Passage{Doc: "auth.md", Start: 0, End: 10}, true); got != 0 {
+		t.Errorf("span without offsets = %v, want 0", got)
+	}
+}
+
+func TestPassageID_FAQQuoteAtDefaultChunkSize(t *testing.T) {
+	// The quote from the passages example in docs/faq.md
+	quote := config.Passage{Doc: "keys.md", Quote: "Revoked keys stop working within five minutes"}
+	q := config.Query{RelevantDocs: []string{"keys.md"}, Passages: []config.Passage{quote}}
+	doc := strings.Repeat("Create keys from the console and store them in a secret manager. ", 12) +
+		"Revoked keys stop working within five minutes. " +
+		strings.Repeat("Each key belongs to one project and can be scoped to read-only access. ", 12)
+
+	// ingest defaults: --chunker fixed --chunk-size 512 --chunk-overlap 64
+	c, err := chunker.NewStrategy("fixed", 512, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := 0
+	for _, ch := range c.Chunk(doc, "docs/keys.md") {
+		payload := map[string]interface{}{"source": ch.Source, "text": ch.Text}
+		if passageID(payload, "keys.md", q, defaultMinOverlap, 0) == quote.Label() {
+			hits++
+		}
+	}
+	if hits == 0 {
+		t.Error("no 512-character chunk matches the quote at the default --min-overlap")
+	}
+}
+
+func TestPassageID_MaxChunkRatio(t *testing.T) {
+	q := config.Query{
+		RelevantDocs: []string{"keys.md"},
+		Passages: []config.Passage{
+			{Doc: "keys.md", Start: 1200, End: 1650},
+			{Doc: "keys.md", Quote: "Revoked keys stop working within five minutes."},
+		},
+	}
+	section := "Revoked keys stop working within five minutes."
+
+	// A whole-document chunk covers both passages, so it is a hit unless
+	// --max-chunk-ratio rejects chunks that much longer than the passage
+	whole := map[string]interface{}{
+		"source":       "docs/keys.md",
+		"text":         strings.Repeat("Unrelated setup steps. ", 40) + section,
+		"start_offset": 0,
+		"end_offset":   5000,
+	}
+	if got := passageID(whole, "keys.md", q, defaultMinOverlap, 0); got != "keys.md#1200-1650" {
+		t.Errorf("whole-document chunk without a ratio scored as %q, want keys.md#1200-1650", got)
+	}
+	if got := passageID(whole, "keys.md", q, defaultMinOverlap, 4); got != "keys.md" {
+		t.Errorf("whole-document chunk with --max-chunk-ratio 4 scored as %q, want a miss", got)
+	}
+
+	// Section-sized chunks are hits either way
+	sized := map[string]interface{}{"source": "docs/keys.md", "text": "x", "start_offset": 1150, "end_offset": 1700}
+	if got := passageID(sized, "keys.md", q, defaultMinOverlap, 4); got != "keys.md#1200-1650" {
+		t.Errorf("section chunk scored as %q, want keys.md#1200-1650", got)
+	}
+	quoted := map[string]interface{}{"source": "docs/keys.md", "text": "Keys. " + section}
+	if got := passageID(quoted, "keys.md", q, defaultMinOverlap, 4); got != q.Passages[1].Label() {
+		t.Errorf("quote chunk scored as %q, want %q", got, q.Passages[1].Label())
+	}
+}
+
+func TestRunQuery_Passages(t *testing.T) {
+	oldOverlap := minOverlap
+	defer func() { minOverlap = oldOverlap }()
+	minOverlap = defaultMinOverlap
+
+	ctx := context.Background()
+	store := mock.New()
+	_ = store.EnsureCollection(ctx, "coll", 4)
+	vec := []float32{1, 1, 1, 1}
+	_ = store.Upsert(ctx, "coll", []vectorstore.Point{
+		{ID: "1", Vector: vec, Payload: map[string]interface{}{"source": "docs/auth.md", "text": "intro to auth", "chunk_id": 0, "start_offset": 0, "end_offset": 100}},
+		{ID: "2", Vector: vec, Payload: map[string]interface{}{"source": "docs/auth.md", "text": "rotate keys every 90 days", "chunk_id": 1, "start_offset": 100, "end_offset": 200}},
+		{ID: "3", Vector: vec, Payload: map[string]interface{}{"source": "docs/billing.md", "text": "invoices", "chunk_id": 0, "start_offset": 0, "end_offset": 50}},
+	})
+
+	q := config.Query{
+		ID:           "q1",
+		Text:         "how often to rotate keys",
+		RelevantDocs: []string{"auth.md", "billing.md"},
+		Passages:     []config.Passage{{Doc: "auth.md", Start: 120, End: 180}},
+	}
+	qr, err := runQuery(ctx, store, &stubEmbedder{dim: 4}, "coll", returnChunk, 3, q)
+	if err != nil {
+		t.Fatalf("runQuery failed: %v", err)
+	}
+
+	if want := []string{"billing.md", "auth.md#120-180"}; !slices.Equal(qr.RelevantIDs, want) {
+		t.Errorf("RelevantIDs = %v, want %v", qr.RelevantIDs, want)
+	}
+	// The intro chunk of auth.md is from the right document but the wrong section
+	if !slices.Contains(qr.RetrievedIDs, "auth.md#120-180") || !slices.Contains(qr.RetrievedIDs, "auth.md") {
+		t.Errorf("RetrievedIDs = %v, want the passage and a non-matching auth.md chunk", qr.RetrievedIDs)
+	}
+	if r := metrics.RecallAtK(qr.RetrievedIDs, qr.RelevantIDs, 3); r != 1 {
+		t.Errorf("recall = %v, want 1", r)
+	}
+
+	// Only the intro chunk retrieved: the document alone is not a hit
+	intro := []string{"auth.md", "billing.md"}
+	if r := metrics.RecallAtK(intro, qr.RelevantIDs, 2); r != 0.5 {
+		t.Errorf("recall with the wrong section = %v, want 0.5", r)
+	}
+}
@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+// Default parallelism and batch size of the ingest pipeline.
+const (
+	defaultChunkWorkers  = 4
+	defaultEmbedWorkers  = 2
+	defaultUpsertWorkers = 2
+	defaultBatchSize     = 256
+)
+
+// pipelineConfig sets the parallelism of each ingest stage.
+type pipelineConfig struct {
+	ChunkWorkers  int
+	EmbedWorkers  int
+	UpsertWorkers int
+
+	// BatchSize is the number of chunks embedded and upserted together.
+	// Embedding requests are packed to the provider's limits within a batch.
+	BatchSize int
+}
+
+// validate reports settings that would stall the pipeline.
+func (c pipelineConfig) validate() error {
+	switch {
+	case c.ChunkWorkers < 1:
+		return fmt.Errorf("--chunk-workers must be at least 1")
+	case c.EmbedWorkers < 1:
+		return fmt.Errorf("--embed-workers must be at least 1")
+	case c.UpsertWorkers < 1:
+		return fmt.Errorf("--upsert-workers must be at least 1")
+	case c.BatchSize < 1:
+		return fmt.Errorf("--batch-size must be at least 1")
+	}
+	return nil
+}
+
+// pipelineStats counts the work an ingest pipeline has done. Stage times
+// are busy time summed over the stage's workers, so with several workers
+// they can exceed the wall-clock time.
+type pipelineStats struct {
+	Documents  int   // Documents read (including ones skipped as unchanged)
+	Chunked    int   // Documents chunked
+	Chunks     int   // Chunks upserted
+	Skipped    int   // Chunks skipped as already committed
+	Duplicates int   // Chunks dropped as duplicates
+	ChunkChars int64 // Characters in upserted chunks
+
+	ReadTime, ChunkTime, EmbedTime, UpsertTime time.Duration
+}
+
+// ingestPipeline streams documents from disk into a collection:
+//
+//	read → chunk → batch → embed → upsert
+//
+// Stages are connected by bounded channels, so at most a few batches of
+// chunks are in memory however large the corpus, and each batch is upserted
+// as soon as it is embedded.
+type ingestPipeline struct {
+	emb        embedder.Embedder
+	store      vectorstore.Store
+	collection string
+	cfg        pipelineConfig
+
+	// filter, if set, selects the documents of each file to ingest.
+	filter func(docs []Document) []Document
+
+	// cached, if set, returns the chunks of a document chunked before the
+	// pipeline ran, so they are not chunked again. Workers call it
+	// concurrently.
+	cached func(doc Document, index int) ([]chunker.Chunk, bool, error)
+
+	// dedup, if set, drops duplicate chunks before they are embedded; calls
+	// never overlap. dedupVectors, if set, drops them by their vectors after;
+	// every embed worker calls it.
+	dedup        func(chunks []chunker.Chunk) []chunker.Chunk
+	dedupVectors func(chunks []chunker.Chunk, points []vectorstore.Point) ([]chunker.Chunk, []vectorstore.Point)
+
+	// skip, if set, reports chunks already committed by an earlier run;
+	// they are not embedded again.
+	skip func(id string) bool
+
+	// skipped, if set, is called with the chunks skip selected.
+	// Calls never overlap.
+	skipped func(chunks []chunker.Chunk)
+
+	// upserted, if set, is called after each batch is committed to the store.
+	// Calls never overlap; an error stops the pipeline.
+	upserted func(chunks []chunker.Chunk) error
+
+	// progress, if set, is called after each upsert with the stats so far.
+	// Calls never overlap.
+	progress func(stats pipelineStats)
+
+	mu    sync.Mutex
+	stats pipelineStats
+}
+
+// indexedDoc is a document with its position among all documents read,
+// which numbers --pre-chunked chunks.
+type indexedDoc struct {
+	doc   Document
+	index int
+}
+
+// embeddedBatch is a batch of chunks with their vectors, ready to upsert.
+type embeddedBatch struct {
+	chunks []chunker.Chunk
+	points []vectorstore.Point
+}
+
+// run ingests every document under dir and returns the stats. On error the
+// batches upserted so far stay in the store.
+func (p *ingestPipeline) run(ctx context.Context, dir string) (pipelineStats, error) {
+	if err := p.cfg.validate(); err != nil {
+		return pipelineStats{}, err
+	}
+	// Build one chunker per worker up front, so bad flags fail before any work
+	chunkers := make([]*docChunker, p.cfg.ChunkWorkers)
+	for i := range chunkers {
+		dc, err := newDocChunker(p.emb)
+		if err != nil {
+			return pipelineStats{}, err
+		}
+		chunkers[i] = dc
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	docs := make(chan indexedDoc, 2*p.cfg.ChunkWorkers)
+	chunked := make(chan []chunker.Chunk, 2*p.cfg.ChunkWorkers)
+	batches := make(chan []chunker.Chunk, p.cfg.EmbedWorkers)
+	embedded := make(chan embeddedBatch, p.cfg.UpsertWorkers)
+
+	// Read files one at a time, in order, so document indexes are stable
+	g.Go(func() error {
+		defer close(docs)
+		index := 0
+		start := time.Now()
+		err := walkDocuments(dir, func(loaded []Document) error {
+			p.record(func(s *pipelineStats) {
+				s.Documents += len(loaded)
+				s.ReadTime += time.Since(start)
+			})
+			first := index
+			index += len(loaded)
+			selected := loaded
+			if p.filter != nil {
+				selected = p.filter(loaded)
+			}
+			// selected is a subsequence of loaded; find each document's index
+			i := 0
+			for _, doc := range selected {
+				for loaded[i].Path != doc.Path {
+					i++
+				}
+				select {
+				case docs <- indexedDoc{doc: doc, index: first + i}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+				i++
+			}
+			start = time.Now()
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to read documents: %w", err)
+		}
+		return nil
+	})
+
+	// Chunk documents in parallel
+	var chunkWG sync.WaitGroup
+	for _, dc := range chunkers {
+		dc := dc
+		chunkWG.Add(1)
+		g.Go(func() error {
+			defer chunkWG.Done()
+			for d := range docs {
+				start := time.Now()
+				chunks, err := p.chunk(ctx, dc, d)
+				if err != nil {
+					return err
+				}
+				p.record(func(s *pipelineStats) {
+					s.Chunked++
+					s.ChunkTime += time.Since(start)
+				})
+				select {
+				case chunked <- chunks:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		chunkWG.Wait()
+		close(chunked)
+		return nil
+	})
+
+	// Gather chunks into batches
+	g.Go(func() error {
+		defer close(batches)
+		batch := make([]chunker.Chunk, 0, p.cfg.BatchSize)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			batch = make([]chunker.Chunk, 0, p.cfg.BatchSize)
+			return nil
+		}
+		for chunks := range chunked {
+			if p.dedup != nil {
+				n := len(chunks)
+				chunks = p.dedup(chunks)
+				p.countDuplicates(n - len(chunks))
+			}
+			if p.skip != nil {
+				chunks = p.skipCommitted(chunks)
+			}
+			for _, ch := range chunks {
+				batch = append(batch, ch)
+				if len(batch) == p.cfg.BatchSize {
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return flush()
+	})
+
+	// Embed batches in parallel
+	var embedWG sync.WaitGroup
+	for i := 0; i < p.cfg.EmbedWorkers; i++ {
+		embedWG.Add(1)
+		g.Go(func() error {
+			defer embedWG.Done()
+			for batch := range batches {
+				start := time.Now()
+				vectors, err := embedChunks(ctx, p.emb, batch, nil)
+				if err != nil {
+					return err
+				}
+				points := make([]vectorstore.Point, len(batch))
+				for j, chunk := range batch {
+					points[j] = vectorstore.Point{
+						ID:      chunk.ID,
+						Vector:  vectors[j],
+						Payload: chunkPayload(chunk),
+					}
+				}
+				p.record(func(s *pipelineStats) { s.EmbedTime += time.Since(start) })
+				if p.dedupVectors != nil {
+					n := len(batch)
+					batch, points = p.dedupVectors(batch, points)
+					p.countDuplicates(n - len(batch))
+					if len(batch) == 0 {
+						continue
+					}
+				}
+				select {
+				case embedded <- embeddedBatch{chunks: batch, points: points}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}
+	g.Go(func() error {
+		embedWG.Wait()
+		close(embedded)
+		return nil
+	})
+
+	// Upsert batches as they are embedded
+	for i := 0; i < p.cfg.UpsertWorkers; i++ {
+		g.Go(func() error {
+			for b := range embedded {
+				start := time.Now()
+				if err := p.store.Upsert(ctx, p.collection, b.points); err != nil {
+					return fmt.Errorf("failed to upsert: %w", err)
+				}
+				var chars int64
+				for _, ch := range b.chunks {
+					chars += int64(len(ch.Text))
+				}
+				// Callbacks run under the stats lock, one batch at a time
+				var err error
+				p.record(func(s *pipelineStats) {
+					s.Chunks += len(b.chunks)
+					s.ChunkChars += chars
+					s.UpsertTime += time.Since(start)
+					if p.upserted != nil {
+						err = p.upserted(b.chunks)
+					}
+					if p.progress != nil {
+						p.progress(*s)
+					}
+				})
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	return p.record(func(*pipelineStats) {}), err
+}
+
+// chunk returns the chunks of d, from the cache if they are there.
+func (p *ingestPipeline) chunk(ctx context.Context, dc *docChunker, d indexedDoc) ([]chunker.Chunk, error) {
+	if p.cached != nil {
+		if chunks, ok, err := p.cached(d.doc, d.index); err != nil || ok {
+			return chunks, err
+		}
+	}
+	return dc.chunk(ctx, d.doc, d.index)
+}
+
+// skipCommitted returns the chunks skip does not select, and reports the
+// others as skipped.
+func (p *ingestPipeline) skipCommitted(chunks []chunker.Chunk) []chunker.Chunk {
+	var keep, skipped []chunker.Chunk
+	for _, ch := range chunks {
+		if p.skip(ch.ID) {
+			skipped = append(skipped, ch)
+		} else {
+			keep = append(keep, ch)
+		}
+	}
+	if len(skipped) > 0 {
+		p.record(func(s *pipelineStats) {
+			s.Skipped += len(skipped)
+			if p.skipped != nil {
+				p.skipped(skipped)
+			}
+		})
+	}
+	return keep
+}
+
+// countDuplicates adds n dropped duplicates to the stats.
+func (p *ingestPipeline) countDuplicates(n int) {
+	if n > 0 {
+		p.record(func(s *pipelineStats) { s.Duplicates += n })
+	}
+}
+
+// record updates the stats under the lock and returns a snapshot.
+func (p *ingestPipeline) record(update func(s *pipelineStats)) pipelineStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	update(&p.stats)
+	return p.stats
+}
@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+// batchRecorder is a mock store that records the size of each upsert.
+type batchRecorder struct {
+	*mock.Store
+	mu      sync.Mutex
+	batches []int
+}
+
+func (s *batchRecorder) Upsert(ctx context.Context, collection string, points []vectorstore.Point) error {
+	s.mu.Lock()
+	s.batches = append(s.batches, len(points))
+	s.mu.Unlock()
+	return s.Store.Upsert(ctx, collection, points)
+}
+
+// writeCorpus writes n small markdown files to a temp dir and returns it.
+func writeCorpus(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i := 0; i < n; i++ {
+		text := fmt.Sprintf("# Doc %d\n\nFirst paragraph of document %d.\n\nSecond paragraph of document %d.", i, i, i)
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("doc%02d.md", i)), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// storedIDs returns the sorted IDs of the points in the collection.
+func storedIDs(t *testing.T, store *mock.Store) []string {
+	t.Helper()
+	points, err := store.GetPoints("docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, len(points))
+	for i, p := range points {
+		ids[i] = p.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func newTestPipeline(t *testing.T, store vectorstore.Store) *ingestPipeline {
+	t.Helper()
+	if err := store.EnsureCollection(context.Background(), "docs", 4); err != nil {
+		t.Fatal(err)
+	}
+	return &ingestPipeline{
+		emb:        &stubEmbedder{dim: 4},
+		store:      store,
+		collection: "docs",
+		cfg:        pipelineConfig{ChunkWorkers: 3, EmbedWorkers: 2, UpsertWorkers: 2, BatchSize: 4},
+	}
+}
+
+func TestIngestPipeline(t *testing.T) {
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() { chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap }()
+	chunkerName, chunkSize, chunkOverlap = "paragraph", 40, 0
+
+	dir := writeCorpus(t, 25)
+	store := &batchRecorder{Store: mock.New()}
+	pipe := newTestPipeline(t, store)
+
+	var progress []int
+	pipe.progress = func(stats pipelineStats) { progress = append(progress, stats.Chunks) }
+
+	stats, err := pipe.run(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	// The pipeline stores exactly the chunks a one-shot ingest would
+	docs, err := readDocuments(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := chunkDocuments(context.Background(), nil, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]string, len(chunks))
+	for i, ch := range chunks {
+		want[i] = ch.ID
+	}
+	sort.Strings(want)
+	got := storedIDs(t, store.Store)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("stored %d chunks, want the %d from chunkDocuments", len(got), len(want))
+	}
+
+	if stats.Documents != 25 || stats.Chunked != 25 || stats.Chunks != len(chunks) {
+		t.Errorf("stats = %+v, want 25 documents and %d chunks", stats, len(chunks))
+	}
+	// Upserted incrementally, in batches of at most --batch-size
+	if len(store.batches) < len(chunks)/4 {
+		t.Errorf("got %d upserts for %d chunks, want batches of 4", len(store.batches), len(chunks))
+	}
+	for _, n := range store.batches {
+		if n > 4 {
+			t.Errorf("upsert of %d points exceeds batch size 4", n)
+		}
+	}
+	if len(progress) != len(store.batches) || progress[len(progress)-1] != len(chunks) {
+		t.Errorf("progress = %v, want one call per batch ending at %d", progress, len(chunks))
+	}
+}
+
+func TestIngestPipeline_FilterKeepsPreChunkedIDs(t *testing.T) {
+	old := preChunked
+	defer func() { preChunked = old }()
+	preChunked = true
+
+	dir := writeCorpus(t, 5)
+	full := mock.New()
+	if _, err := newTestPipeline(t, full).run(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Skipping files must not renumber the documents after them
+	partial := mock.New()
+	pipe := newTestPipeline(t, partial)
+	pipe.filter = func(docs []Document) []Document {
+		if strings.HasSuffix(docs[0].Path, "doc03.md") {
+			return docs
+		}
+		return nil
+	}
+	if _, err := pipe.run(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	points, _ := partial.GetPoints("docs")
+	if len(points) != 1 {
+		t.Fatalf("expected 1 chunk, got %d", len(points))
+	}
+	found := false
+	for _, id := range storedIDs(t, full) {
+		found = found || id == points[0].ID
+	}
+	if !found {
+		t.Errorf("chunk ID %s differs from the full ingest", points[0].ID)
+	}
+}
+
+func TestIngestPipeline_UpsertError(t *testing.T) {
+	dir := writeCorpus(t, 40)
+	store := mock.New()
+	pipe := newTestPipeline(t, store)
+	pipe.cfg.BatchSize = 1
+
+	boom := errors.New("store unavailable")
+	var mu sync.Mutex
+	calls := 0
+	store.UpsertFunc = func(ctx context.Context, collection string, points []vectorstore.Point) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 3 {
+			return boom
+		}
+		return nil
+	}
+
+	stats, err := pipe.run(context.Background(), dir)
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want %v", err, boom)
+	}
+	if stats.Chunks < 2 {
+		t.Errorf("stats.Chunks = %d, want the upserts before the failure counted", stats.Chunks)
+	}
+}
+
+func TestPipelineConfig_Validate(t *testing.T) {
+	good := pipelineConfig{ChunkWorkers: 1, EmbedWorkers: 1, UpsertWorkers: 1, BatchSize: 1}
+	if err := good.validate(); err != nil {
+		t.Errorf("valid config rejected: %v", err)
+	}
+	bad := good
+	bad.EmbedWorkers = 0
+	if err := bad.validate(); err == nil || !strings.Contains(err.Error(), "--embed-workers") {
+		t.Errorf("err = %v, want --embed-workers error", err)
+	}
+}
+
+func TestIngestPipeline_Canceled(t *testing.T) {
+	dir := writeCorpus(t, 40)
+	store := mock.New()
+	pipe := newTestPipeline(t, store)
+	pipe.cfg.BatchSize = 1
+
+	// Interrupt after the second upsert, as Ctrl-C would
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var mu sync.Mutex
+	calls := 0
+	store.UpsertFunc = func(ctx context.Context, collection string, points []vectorstore.Point) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if calls++; calls == 2 {
+			cancel()
+		}
+		return nil
+	}
+
+	stats, err := pipe.run(ctx, dir)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if stats.Chunks < 2 || stats.Chunks >= 80 {
+		t.Errorf("stats.Chunks = %d, want the upserts before the interrupt counted", stats.Chunks)
+	}
+}
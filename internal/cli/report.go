@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/metawake/ragtune/internal/metrics"
 	"github.com/spf13/cobra"
 )
 
@@ -135,6 +136,133 @@ func generateMarkdownReport(run RunResult) (string, error) {
 
 	sb.WriteString("\n---\n\n")
 
+	// Storage footprint (only when configs vary vector settings)
+	hasVectorSettings := false
+	for _, cfg := range run.Configs {
+		if cfg.Footprint != nil && (cfg.Config.Dims > 0 || cfg.Config.Quantization != "") {
+			hasVectorSettings = true
+			break
+		}
+	}
+
+	if hasVectorSettings {
+		sb.WriteString("## Storage Footprint\n\n")
+		sb.WriteString("| Config | Dims | Quantization | Bytes/Vector | Total | Recall@K |\n")
+		sb.WriteString("|--------|------|--------------|--------------|-------|----------|\n")
+		for _, cfg := range run.Configs {
+			if cfg.Footprint == nil {
+				continue
+			}
+			total := "-"
+			if cfg.Footprint.Vectors > 0 {
+				total = formatBytes(cfg.Footprint.TotalBytes)
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %d | %s | %.3f |\n",
+				cfg.Config.Name,
+				cfg.Footprint.Dims,
+				cfg.Footprint.Quantization,
+				cfg.Footprint.BytesPerVector,
+				total,
+				cfg.Metrics.RecallAtK,
+			))
+		}
+		sb.WriteString("\n---\n\n")
+	}
+
+	// Bootstrap confidence intervals (only when simulate ran with --bootstrap)
+	hasBootstrap := false
+	for _, cfg := range run.Configs {
+		if cfg.Bootstrap != nil && cfg.Bootstrap.Intervals != nil {
+			hasBootstrap = true
+			break
+		}
+	}
+
+	if hasBootstrap {
+		sb.WriteString("## Confidence Intervals\n\n")
+		sb.WriteString("| Config | Metric | Estimate | Mean ± Std | 95% Percentile | 95% BCa |\n")
+		sb.WriteString("|--------|--------|----------|------------|----------------|---------|\n")
+		for _, cfg := range run.Configs {
+			if cfg.Bootstrap == nil {
+				continue
+			}
+			for _, m := range []struct{ key, label string }{
+				{"recall_at_k", "Recall@K"},
+				{"mrr", "MRR"},
+				{"ndcg_at_k", "NDCG@K"},
+				{"coverage", "Coverage"},
+			} {
+				iv, ok := cfg.Bootstrap.Intervals[m.key]
+				if !ok {
+					continue
+				}
+				sb.WriteString(fmt.Sprintf("| %s | %s | %.3f | %.3f ± %.3f | [%.3f, %.3f] | [%.3f, %.3f] |\n",
+					cfg.Config.Name, m.label, iv.Estimate, iv.Mean, iv.Std,
+					iv.PercentileLo, iv.PercentileHi, iv.BCaLo, iv.BCaHi))
+			}
+		}
+		sb.WriteString(fmt.Sprintf("\n*%d resamples of the queries; BCa corrects the percentile interval for bias and skew.*\n", bootstrapResamples(run)))
+		sb.WriteString("\n---\n\n")
+	}
+
+	// Graded metrics (only when queries carry graded judgments)
+	graded := false
+	for _, cfg := range run.Configs {
+		for _, qr := range cfg.QueryResults {
+			if qr.Grades != nil {
+				graded = true
+				break
+			}
+		}
+	}
+
+	if graded {
+		sb.WriteString("## Graded Relevance\n\n")
+		sb.WriteString("| Config | Top-K | NDCG@K | ERR@K | Graded Precision@K |\n")
+		sb.WriteString("|--------|-------|--------|-------|--------------------|\n")
+		for _, cfg := range run.Configs {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %.3f | %.3f | %.3f |\n",
+				cfg.Config.Name,
+				cfg.Config.TopK,
+				cfg.Metrics.NDCGAtK,
+				cfg.Metrics.ERRAtK,
+				cfg.Metrics.GradedPrecisionAtK,
+			))
+		}
+		sb.WriteString("\n---\n\n")
+	}
+
+	// Metrics across --k-range with the recommended k and min_score
+	hasCurve := false
+	for _, cfg := range run.Configs {
+		if len(cfg.KCurve) > 0 {
+			hasCurve = true
+			break
+		}
+	}
+
+	if hasCurve {
+		sb.WriteString("## Metrics by K\n\n")
+		for _, cfg := range run.Configs {
+			if len(cfg.KCurve) == 0 {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("### Config: %s\n\n", cfg.Config.Name))
+			if fit := cfg.Threshold; fit != nil {
+				sb.WriteString(fmt.Sprintf("**Recommended:** k=%d, min_score=%.2f — %s (precision %.3f, recall %.3f, F1 %.3f, %.1f results/query)\n\n",
+					fit.K, fit.MinScore, describeFit(fit), fit.Precision, fit.Recall, fit.F1, fit.AvgResults))
+			}
+			sb.WriteString("| K | Recall@K | Precision@K | F1 | MRR | NDCG@K | Recall |\n")
+			sb.WriteString("|---|----------|-------------|----|-----|--------|--------|\n")
+			for _, p := range cfg.KCurve {
+				sb.WriteString(fmt.Sprintf("| %d | %.3f | %.3f | %.3f | %.3f | %.3f | `%s` |\n",
+					p.K, p.Recall, p.Precision, p.F1, p.MRR, p.NDCG, recallBar(p.Recall, 20)))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("---\n\n")
+	}
+
 	// Detailed results per config
 	sb.WriteString("## Detailed Results\n\n")
 
@@ -170,6 +298,15 @@ func generateMarkdownReport(run RunResult) (string, error) {
 	sb.WriteString("- **Coverage**: Fraction of all relevant docs ever retrieved across queries (higher is better)\n")
 	sb.WriteString("- **Redundancy**: Average times each doc is retrieved (lower may indicate diverse results)\n")
 	sb.WriteString("- **Latency**: Query latency including embedding + search (p50/p95/p99 percentiles)\n")
+	if graded {
+		sb.WriteString("- **NDCG@K**: Ranking quality with gains of 2^grade - 1, so the best answers count most (higher is better)\n")
+		sb.WriteString("- **ERR@K**: Expected Reciprocal Rank — how soon a user finds a highly graded result (higher is better)\n")
+		sb.WriteString("- **Graded Precision@K**: Precision with partial credit of grade / max grade per result (higher is better)\n")
+	}
+	if hasCurve {
+		sb.WriteString("- **Precision@K**: Fraction of the top-K results that are relevant (higher is better)\n")
+		sb.WriteString("- **min_score**: Similarity below which results are dropped; fitted with the recommended k\n")
+	}
 	sb.WriteString("\n---\n\n")
 	sb.WriteString("*Generated by [RagTune](https://github.com/metawake/ragtune)*\n")
 
@@ -179,16 +316,21 @@ func generateMarkdownReport(run RunResult) (string, error) {
 func generateJSONReport(run RunResult) (string, error) {
 	// Create a simplified report structure
 	type ReportConfig struct {
-		Name       string  `json:"name"`
-		TopK       int     `json:"top_k"`
-		RecallAtK  float64 `json:"recall_at_k"`
-		MRR        float64 `json:"mrr"`
-		Coverage   float64 `json:"coverage"`
-		Redundancy float64 `json:"redundancy"`
-		LatencyP50 float64 `json:"latency_p50_ms,omitempty"`
-		LatencyP95 float64 `json:"latency_p95_ms,omitempty"`
-		LatencyP99 float64 `json:"latency_p99_ms,omitempty"`
-		LatencyAvg float64 `json:"latency_avg_ms,omitempty"`
+		Name       string                `json:"name"`
+		TopK       int                   `json:"top_k"`
+		RecallAtK  float64               `json:"recall_at_k"`
+		MRR        float64               `json:"mrr"`
+		NDCGAtK    float64               `json:"ndcg_at_k"`
+		ERRAtK     float64               `json:"err_at_k"`
+		GradedPAtK float64               `json:"graded_precision_at_k"`
+		Coverage   float64               `json:"coverage"`
+		Redundancy float64               `json:"redundancy"`
+		LatencyP50 float64               `json:"latency_p50_ms,omitempty"`
+		LatencyP95 float64               `json:"latency_p95_ms,omitempty"`
+		LatencyP99 float64               `json:"latency_p99_ms,omitempty"`
+		LatencyAvg float64               `json:"latency_avg_ms,omitempty"`
+		KCurve     []metrics.KPoint      `json:"k_curve,omitempty"`
+		Threshold  *metrics.ThresholdFit `json:"threshold,omitempty"`
 	}
 
 	type Report struct {
@@ -210,12 +352,17 @@ func generateJSONReport(run RunResult) (string, error) {
 			TopK:       cfg.Config.TopK,
 			RecallAtK:  cfg.Metrics.RecallAtK,
 			MRR:        cfg.Metrics.MRR,
+			NDCGAtK:    cfg.Metrics.NDCGAtK,
+			ERRAtK:     cfg.Metrics.ERRAtK,
+			GradedPAtK: cfg.Metrics.GradedPrecisionAtK,
 			Coverage:   cfg.Metrics.Coverage,
 			Redundancy: cfg.Metrics.Redundancy,
 			LatencyP50: cfg.Metrics.LatencyP50,
 			LatencyP95: cfg.Metrics.LatencyP95,
 			LatencyP99: cfg.Metrics.LatencyP99,
 			LatencyAvg: cfg.Metrics.LatencyAvg,
+			KCurve:     cfg.KCurve,
+			Threshold:  cfg.Threshold,
 		})
 	}
 
@@ -249,5 +396,13 @@ func hasOverlap(a, b []string) bool {
 	return false
 }
 
-
-
+// bootstrapResamples returns the resample count of the first config run
+// with --bootstrap.
+func bootstrapResamples(run RunResult) int {
+	for _, cfg := range run.Configs {
+		if cfg.Bootstrap != nil {
+			return cfg.Bootstrap.N
+		}
+	}
+	return 0
+}
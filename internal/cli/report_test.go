@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+func TestGenerateMarkdownReport_Bootstrap(t *testing.T) {
+	results := []metrics.QueryResult{
+		{RetrievedIDs: []string{"a"}, RelevantIDs: []string{"a"}},
+		{RetrievedIDs: []string{"x"}, RelevantIDs: []string{"b"}},
+		{RetrievedIDs: []string{"c"}, RelevantIDs: []string{"c"}},
+	}
+	bs := metrics.Bootstrap(results, 5, 50, 0, 42, false)
+	run := RunResult{Configs: []ConfigResult{{
+		Config:       config.SimConfig{Name: "default", TopK: 5},
+		Metrics:      metrics.Compute(results, 5),
+		Bootstrap:    &bs,
+		QueryResults: results,
+	}}}
+
+	out, err := generateMarkdownReport(run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"## Confidence Intervals", "| default | Recall@K | 0.667 |", "50 resamples"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report missing %q", want)
+		}
+	}
+
+	// Runs without --bootstrap have no section
+	run.Configs[0].Bootstrap = nil
+	if out, _ := generateMarkdownReport(run); strings.Contains(out, "Confidence Intervals") {
+		t.Error("report has a Confidence Intervals section without bootstrap")
+	}
+}
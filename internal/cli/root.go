@@ -4,8 +4,12 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -34,6 +38,26 @@ Sponsor:
   If RagTune saves you time, star the repo or support development:
   GitHub: https://github.com/metawake/ragtune
   ETH: 0x0a542565b3615e8fc934cc3cc4921a0c22e5dc5e`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if commandTimeout > 0 {
+			ctx, cancel := context.WithTimeout(cmd.Context(), commandTimeout)
+			cancelTimeout = cancel
+			cmd.SetContext(ctx)
+		}
+	},
+}
+
+// cancelTimeout releases the --timeout context once the command returns.
+var cancelTimeout context.CancelFunc = func() {}
+
+// commandContext returns the command's context: canceled on SIGINT/SIGTERM
+// and after --timeout. Commands run outside Execute, as in tests, get a
+// background context.
+func commandContext(cmd *cobra.Command) context.Context {
+	if cmd != nil && cmd.Context() != nil {
+		return cmd.Context()
+	}
+	return context.Background()
 }
 
 // Global flags
@@ -55,7 +79,13 @@ var (
 	voyageModel       string
 	teiAddr           string
 	teiModel          string
+	vectorDims        int
+	quantization      string
+	tokenPolicy       string
+	maxInputTokens    int
 	topK              int
+	commandTimeout    time.Duration
+	requestTimeout    time.Duration
 )
 
 func init() {
@@ -80,16 +110,33 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cohereModel, "cohere-model", "embed-english-v3.0", "Cohere embedding model")
 	rootCmd.PersistentFlags().StringVar(&voyageModel, "voyage-model", "voyage-2", "Voyage embedding model (voyage-2, voyage-law-2, voyage-code-2)")
 
+	// Vector transform flags (must match between ingest and query time)
+	rootCmd.PersistentFlags().IntVar(&vectorDims, "dims", 0, "Truncate embeddings to the first N dimensions, Matryoshka-style (0 = full length)")
+	rootCmd.PersistentFlags().StringVar(&quantization, "quantization", "none", "Vector quantization before storage (none, int8, binary)")
+
+	// Input length flags
+	rootCmd.PersistentFlags().StringVar(&tokenPolicy, "token-policy", "truncate", "How to handle inputs over the model's token limit (truncate, split, error)")
+	rootCmd.PersistentFlags().IntVar(&maxInputTokens, "max-input-tokens", 0, "Override the model's per-input token limit (0 = model default)")
+
 	// Retrieval flags
 	rootCmd.PersistentFlags().IntVar(&topK, "top-k", 5, "Number of results to retrieve")
 
+	// Timeout flags
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", 0, "Stop the command after this long, as if interrupted (e.g. 30m; 0 = no limit)")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 0, "Fail any single embedding or vector store call after this long (e.g. 30s; 0 = no limit)")
+
 	rootCmd.AddCommand(ingestCmd)
 	rootCmd.AddCommand(explainCmd)
 }
 
-// Execute runs the root command
+// Execute runs the root command. SIGINT and SIGTERM cancel the command's
+// context, so long-running commands can stop cleanly and report partial work.
 func Execute() error {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer func() { cancelTimeout() }()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return err
 	}
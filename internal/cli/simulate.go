@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/metawake/ragtune/internal/chunker"
 	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/embedder"
 	"github.com/metawake/ragtune/internal/metrics"
+	"github.com/metawake/ragtune/internal/vectorstore"
 	"github.com/spf13/cobra"
 )
 
@@ -32,6 +36,15 @@ var (
 	// Bootstrap flags
 	bootstrapN    int
 	bootstrapSeed int64
+	// Auto-ingest flags
+	simulateDocs    string
+	simulateCleanup bool
+	// Top-k and score threshold fitting flags
+	kRange          string
+	targetPrecision float64
+	// Passage-level ground truth flags
+	minOverlap    float64
+	maxChunkRatio float64
 )
 
 var simulateCmd = &cobra.Command{
@@ -62,13 +75,71 @@ Baseline Comparison:
   metric. Use --fail-on-regression to fail CI if any metric decreased.
 
 Bootstrap Confidence Intervals:
-  Use --bootstrap N to run N bootstrap samples and report mean ± std for
-  each metric. This enables distinguishing real changes from random variance.
-  Example: "Recall@5: 0.664 ± 0.012" means the true value is likely in that range.
+  Use --bootstrap N to recompute the metrics on N resamples of the queries
+  and report mean ± std with a 95% BCa interval for each. This enables
+  distinguishing real changes from random variance. Use 1000 or more
+  resamples for stable interval bounds; --bootstrap-seed makes them
+  reproducible.
+  Example: "Recall@5: 0.664 ± 0.012  [0.640, 0.687]" means the true value is
+  likely in [0.640, 0.687]. The run file has percentile and BCa intervals for
+  every metric.
+
+Parent-Child Retrieval:
+  For collections ingested with --parent-size, --return parent ranks parents
+  instead of the embedded children: hits are deduplicated by parent and
+  metrics are computed over the top-k distinct parents. Configs may set
+  return (chunk or parent) to compare both modes on one collection.
+
+Chunking Sweeps:
+  With --docs, each config is run against a collection built from those
+  documents with its chunker, chunk_size and overlap (unset ones take the
+  --chunker, --chunk-size and --chunk-overlap values, except that a config
+  setting chunk_size alone has no overlap), plus its embedder, parent_size,
+  enrich, breadcrumb, dims and quantization. Configs that differ only in
+  top_k or return share a collection. Collections are named
+  <collection>-<hash of documents and settings> and reused by later runs
+  until the documents or settings change; --cleanup deletes them afterwards.
+  A config that sets collection uses it as is.
+
+Top-K and Score Threshold:
+  --k-range retrieves max(k) results per query once and reports recall,
+  precision, F1, MRR and NDCG at every k in the range. It then fits the k
+  and minimum similarity score that maximize F1, or with --target-precision
+  the recall at that precision, and recommends them (e.g. k=7,
+  min_score=0.62). Precision counts every result kept; the main metrics
+  stay at top_k.
+
+Passage-Level Ground Truth:
+  Queries may list passages: a chunk ID, an exact quote, or a byte span
+  of a document. A document with passages counts as found only when a
+  retrieved chunk covers at least --min-overlap of one of them, so a chunk
+  from the wrong section of the right document is a miss. A chunk that
+  contains the whole passage covers all of it however long it is; set
+  --max-chunk-ratio to also reject chunks longer than that many times the
+  passage, such as whole-document chunks.
+
+Vector Compression:
+  Configs may set collection, dims and quantization (none, int8, binary) to
+  compare collections ingested with truncated or quantized vectors. Each
+  config reports its storage footprint alongside recall.
+
+Interruption:
+  Ctrl-C, SIGTERM or --timeout stops the run after the current query. The
+  queries evaluated so far are saved to a timestamped file marked
+  "incomplete": true; latest.json is left as it was, and baseline and CI
+  checks are skipped.
 
 Examples:
   ragtune simulate --collection demo --queries data/queries.json
 
+  # Sweep chunk sizes from one configs.yaml, building a collection per config
+  ragtune simulate --collection sweep --queries golden.json \
+    --docs ./docs --configs chunk-sweep.yaml
+
+  # Recommend k and min_score for 90% precision
+  ragtune simulate --collection prod --queries golden.json \
+    --k-range 1-20 --target-precision 0.9
+
   # With bootstrap confidence intervals
   ragtune simulate --collection prod --queries golden.json --bootstrap 20
 
@@ -86,7 +157,17 @@ func init() {
 	simulateCmd.Flags().StringVar(&queriesPath, "queries", "", "Path to queries JSON file (required)")
 	simulateCmd.Flags().StringVar(&configsPath, "configs", "", "Path to configs YAML/JSON file (optional)")
 	simulateCmd.Flags().StringVar(&outputDir, "output", "runs", "Output directory for run artifacts")
+	simulateCmd.Flags().StringVar(&returnMode, "return", returnChunk, "Rank chunks or their parents: chunk, parent (needs ingest --parent-size)")
+	simulateCmd.Flags().StringVar(&simulateDocs, "docs", "", "Build a collection per chunking config from these documents (cached across runs)")
+	simulateCmd.Flags().BoolVar(&simulateCleanup, "cleanup", false, "Delete the collections built with --docs after the run")
+	simulateCmd.Flags().StringVar(&chunkerName, "chunker", "fixed", "Chunking strategy for configs that do not set one (--docs)")
+	simulateCmd.Flags().IntVar(&chunkSize, "chunk-size", 512, "Chunk size for configs that do not set chunk_size (--docs)")
+	simulateCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 64, "Chunk overlap for configs that set neither chunk_size nor overlap (--docs)")
 	_ = simulateCmd.MarkFlagRequired("queries")
+	simulateCmd.Flags().Float64Var(&minOverlap, "min-overlap", defaultMinOverlap, "Fraction of a query passage a chunk must cover to count as a hit")
+	simulateCmd.Flags().Float64Var(&maxChunkRatio, "max-chunk-ratio", 0, "Longest a chunk may be, as a multiple of the query passage it covers, to count as a hit (0 = any)")
+	simulateCmd.Flags().StringVar(&kRange, "k-range", "", "Report metrics at each k (e.g. 1-20 or 1,3,5,10) and recommend k and min_score")
+	simulateCmd.Flags().Float64Var(&targetPrecision, "target-precision", 0, "Fit min_score for the best recall at this precision (0 = best F1; needs --k-range)")
 
 	// CI mode flags
 	simulateCmd.Flags().BoolVar(&ciMode, "ci", false, "CI mode: exit 1 if thresholds not met")
@@ -115,6 +196,10 @@ type RunResult struct {
 	Collection string         `json:"collection"`
 	Store      string         `json:"store"`
 	Configs    []ConfigResult `json:"configs"`
+
+	// Incomplete marks a run stopped by an interrupt or --timeout. Its
+	// configs hold the queries evaluated before the stop.
+	Incomplete bool `json:"incomplete,omitempty"`
 }
 
 // ConfigResult represents results for a single configuration.
@@ -122,15 +207,59 @@ type ConfigResult struct {
 	Config       config.SimConfig         `json:"config"`
 	Metrics      metrics.Result           `json:"metrics"`
 	Bootstrap    *metrics.BootstrapResult `json:"bootstrap,omitempty"`
+	Footprint    *StorageFootprint        `json:"footprint,omitempty"`
 	QueryResults []metrics.QueryResult    `json:"query_results"`
+
+	// KCurve holds metrics at each k of --k-range, and Threshold the
+	// recommended k and min_score.
+	KCurve    []metrics.KPoint      `json:"k_curve,omitempty"`
+	Threshold *metrics.ThresholdFit `json:"threshold,omitempty"`
+
+	// Incomplete marks a config whose queries were not all evaluated.
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+// StorageFootprint estimates the vector storage used by a configuration.
+// Vectors is a best-effort count from the store; TotalBytes excludes index overhead.
+type StorageFootprint struct {
+	Dims           int    `json:"dims"`
+	Quantization   string `json:"quantization"`
+	BytesPerVector int    `json:"bytes_per_vector"`
+	Vectors        int64  `json:"vectors,omitempty"`
+	TotalBytes     int64  `json:"total_bytes,omitempty"`
 }
 
 func runSimulate(cmd *cobra.Command, args []string) error {
 	if collectionName == "" {
 		return fmt.Errorf("--collection is required")
 	}
+	if err := validateReturnMode(returnMode); err != nil {
+		return err
+	}
+	if simulateCleanup && simulateDocs == "" {
+		return fmt.Errorf("--cleanup requires --docs")
+	}
+	var ks []int
+	if kRange != "" {
+		var err error
+		if ks, err = parseKRange(kRange); err != nil {
+			return err
+		}
+	}
+	if targetPrecision < 0 || targetPrecision > 1 {
+		return fmt.Errorf("--target-precision must be between 0 and 1, got %g", targetPrecision)
+	}
+	if targetPrecision > 0 && ks == nil {
+		return fmt.Errorf("--target-precision requires --k-range")
+	}
+	if err := validateMinOverlap(minOverlap); err != nil {
+		return err
+	}
+	if err := validateMaxChunkRatio(maxChunkRatio); err != nil {
+		return err
+	}
 
-	ctx := context.Background()
+	ctx := commandContext(cmd)
 
 	// Initialize store
 	store, err := initVectorStore(ctx)
@@ -150,8 +279,13 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load queries: %w", err)
 	}
+	// Graded judgments add ERR and graded precision to the metrics shown
+	graded := slices.ContainsFunc(queries, func(q config.Query) bool { return len(q.Judgments) > 0 })
 	if !jsonOutput {
 		fmt.Printf("Loaded %d queries\n", len(queries))
+		if graded {
+			fmt.Println("Using graded relevance judgments")
+		}
 	}
 
 	// Load or create default configs
@@ -161,6 +295,24 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 		if err != nil {
 			return fmt.Errorf("failed to load configs: %w", err)
 		}
+		for _, cfg := range configs {
+			if cfg.Chunker != "" && !slices.Contains(chunker.StrategyNames(), cfg.Chunker) {
+				return fmt.Errorf("config %q: unsupported chunker: %s (supported: %s)", cfg.Name, cfg.Chunker, strings.Join(chunker.StrategyNames(), ", "))
+			}
+			if cfg.BreakpointPercentile < 0 || cfg.BreakpointPercentile > 100 {
+				return fmt.Errorf("config %q: breakpoint_percentile must be between 0 and 100", cfg.Name)
+			}
+			if cfg.Enrich != "" {
+				if _, err := chunker.ParseTemplate(cfg.Enrich); err != nil {
+					return fmt.Errorf("config %q: enrich: %w", cfg.Name, err)
+				}
+			}
+			if cfg.Return != "" {
+				if err := validateReturnMode(cfg.Return); err != nil {
+					return fmt.Errorf("config %q: %w", cfg.Name, err)
+				}
+			}
+		}
 	} else {
 		// Default config
 		configs = []config.SimConfig{
@@ -171,6 +323,29 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Running %d configurations\n", len(configs))
 	}
 
+	// Build a collection per chunking config from --docs
+	var builder *derivedBuilder
+	if simulateDocs != "" {
+		if builder, err = newDerivedBuilder(ctx, store, simulateDocs, jsonOutput); err != nil {
+			return err
+		}
+		if simulateCleanup {
+			defer func() {
+				// Clean up even after an interrupt
+				if err := builder.cleanup(context.WithoutCancel(ctx)); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}()
+		}
+	} else if !jsonOutput {
+		for _, cfg := range configs {
+			if cfg.Collection == "" && (cfg.ChunkSize != 0 || cfg.Overlap != 0) {
+				fmt.Printf("Note: config %q sets chunk_size or overlap, but every config without a collection searches %s; use --docs to build one per chunking config\n", cfg.Name, collectionName)
+				break
+			}
+		}
+	}
+
 	// Run simulation
 	runResult := RunResult{
 		Timestamp:  time.Now().UTC().Format(time.RFC3339),
@@ -178,59 +353,90 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 		Store:      storeName,
 	}
 
+	// interrupted is set when the context is canceled by a signal or --timeout
+	var interrupted error
+
 	for _, cfg := range configs {
+		coll := collectionName
+		if cfg.Collection != "" {
+			coll = cfg.Collection
+		}
+
+		// Per-config embedder and vector transform override --embedder and --dims/--quantization
+		cfgEmb := emb
+		if cfg.Embedder != "" || cfg.Dims != 0 || cfg.Quantization != "" {
+			if cfgEmb, err = configEmbedder(cfg); err != nil {
+				return fmt.Errorf("config %s: %w", cfg.Name, err)
+			}
+		}
+
+		// Build the collection for this config's chunking, or reuse it
+		if builder != nil && cfg.Collection == "" {
+			cfg = resolveChunkConfig(cfg)
+			if coll, err = builder.collection(ctx, cfg, cfgEmb); err != nil {
+				if interrupted = ctx.Err(); interrupted != nil {
+					break
+				}
+				return fmt.Errorf("config %s: %w", cfg.Name, err)
+			}
+			cfg.Collection = coll
+		}
+
+		mode := returnMode
+		if cfg.Return != "" {
+			mode = cfg.Return
+		}
+
 		if !jsonOutput {
-			fmt.Printf("\n--- Config: %s (top_k=%d) ---\n", cfg.Name, cfg.TopK)
+			fmt.Printf("\n--- Config: %s (top_k=%d%s) ---\n", cfg.Name, cfg.TopK, describeConfigTarget(cfg))
 		}
 
-		var queryResults []metrics.QueryResult
+		footprint := computeFootprint(ctx, store, coll, cfgEmb)
 
-		for i, q := range queries {
-			// Track latency
-			queryStart := time.Now()
+		// With --k-range, retrieve deep enough for every k once
+		depth := cfg.TopK
+		if len(ks) > 0 {
+			depth = max(depth, ks[len(ks)-1])
+		}
 
-			// Embed query
-			vec, err := emb.Embed(ctx, q.Text)
-			if err != nil {
-				return fmt.Errorf("failed to embed query %s: %w", q.ID, err)
+		var queryResults, deepResults []metrics.QueryResult
+
+		for i, q := range queries {
+			if interrupted = ctx.Err(); interrupted != nil {
+				break
 			}
 
-			// Search
-			results, err := store.Search(ctx, collectionName, vec, cfg.TopK)
+			qr, err := runQuery(ctx, store, cfgEmb, coll, mode, depth, q)
 			if err != nil {
-				return fmt.Errorf("search failed for query %s: %w", q.ID, err)
+				if interrupted = ctx.Err(); interrupted != nil {
+					break
+				}
+				return err
 			}
+			deepResults = append(deepResults, qr)
 
-			// Calculate latency (embedding + search)
-			latencyMs := float64(time.Since(queryStart).Microseconds()) / 1000.0
-
-			// Extract IDs (source files) from results
-			var retrievedIDs []string
-			var scores []float32
-			for _, r := range results {
-				source := getPayloadString(r.Payload, "source")
-				// Extract just the filename for matching
-				source = filepath.Base(source)
-				// Normalize: map chunk-level sources back to parent doc.
-				// e.g. "rfc6749_oauth2_cs0227.txt" -> "rfc6749_oauth2.txt"
-				// if "rfc6749_oauth2.txt" is in the relevant docs.
-				source = normalizeSource(source, q.RelevantDocs)
-				retrievedIDs = append(retrievedIDs, source)
-				scores = append(scores, r.Score)
+			if !jsonOutput {
+				fmt.Printf("  [%d/%d] %s (%.1fms)\n", i+1, len(queries), q.ID, qr.LatencyMs)
 			}
+		}
 
-			queryResults = append(queryResults, metrics.QueryResult{
-				QueryID:      q.ID,
-				Query:        q.Text,
-				RetrievedIDs: retrievedIDs,
-				RelevantIDs:  q.RelevantDocs,
-				Scores:       scores,
-				LatencyMs:    latencyMs,
-			})
+		queryResults = deepResults
+		if depth > cfg.TopK {
+			queryResults = cutResults(deepResults, cfg.TopK)
+		}
 
-			if !jsonOutput {
-				fmt.Printf("  [%d/%d] %s (%.1fms)\n", i+1, len(queries), q.ID, latencyMs)
+		if interrupted != nil {
+			// Keep the queries evaluated so far; skip bootstrap and reporting
+			if len(queryResults) > 0 {
+				runResult.Configs = append(runResult.Configs, ConfigResult{
+					Config:       cfg,
+					Metrics:      metrics.Compute(queryResults, cfg.TopK),
+					Footprint:    footprint,
+					QueryResults: queryResults,
+					Incomplete:   true,
+				})
 			}
+			break
 		}
 
 		// Compute metrics
@@ -239,7 +445,7 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 		// Compute bootstrap confidence intervals if requested
 		var bs *metrics.BootstrapResult
 		if bootstrapN > 0 {
-			bsResult := metrics.Bootstrap(queryResults, cfg.TopK, bootstrapN, 0, bootstrapSeed, false)
+			bsResult := metrics.Bootstrap(queryResults, cfg.TopK, bootstrapN, 0, bootstrapSeed, true)
 			bs = &bsResult
 		}
 
@@ -247,10 +453,11 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 			fmt.Printf("\n  Metrics:\n")
 			if bs != nil {
 				// Show metrics with confidence intervals
-				fmt.Printf("    Recall@%d:  %.3f ± %.3f  (n=%d)\n", cfg.TopK, bs.RecallMean, bs.RecallStd, bs.N)
-				fmt.Printf("    MRR:        %.3f ± %.3f\n", bs.MRRMean, bs.MRRStd)
-				fmt.Printf("    NDCG@%d:    %.3f ± %.3f\n", cfg.TopK, bs.NDCGMean, bs.NDCGStd)
-				fmt.Printf("    Coverage:   %.3f ± %.3f\n", bs.CoverageMean, bs.CoverageStd)
+				ndcg, coverage := bs.Intervals["ndcg_at_k"], bs.Intervals["coverage"]
+				fmt.Printf("    Recall@%d:  %.3f ± %.3f  [%.3f, %.3f]  (n=%d)\n", cfg.TopK, bs.RecallMean, bs.RecallStd, bs.RecallCI95Lo, bs.RecallCI95Hi, bs.N)
+				fmt.Printf("    MRR:        %.3f ± %.3f  [%.3f, %.3f]\n", bs.MRRMean, bs.MRRStd, bs.MRRCI95Lo, bs.MRRCI95Hi)
+				fmt.Printf("    NDCG@%d:    %.3f ± %.3f  [%.3f, %.3f]\n", cfg.TopK, bs.NDCGMean, bs.NDCGStd, ndcg.BCaLo, ndcg.BCaHi)
+				fmt.Printf("    Coverage:   %.3f ± %.3f  [%.3f, %.3f]\n", bs.CoverageMean, bs.CoverageStd, coverage.BCaLo, coverage.BCaHi)
 			} else {
 				// Show point estimates only
 				fmt.Printf("    Recall@%d:  %.3f\n", cfg.TopK, m.RecallAtK)
@@ -258,11 +465,29 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 				fmt.Printf("    NDCG@%d:    %.3f\n", cfg.TopK, m.NDCGAtK)
 				fmt.Printf("    Coverage:   %.3f\n", m.Coverage)
 			}
+			if graded {
+				fmt.Printf("    ERR@%d:     %.3f\n", cfg.TopK, m.ERRAtK)
+				fmt.Printf("    GradedP@%d: %.3f\n", cfg.TopK, m.GradedPrecisionAtK)
+			}
 			fmt.Printf("    Redundancy: %.2f\n", m.Redundancy)
 			if m.LatencyAvg > 0 {
 				fmt.Printf("    Latency:    p50=%.1fms  p95=%.1fms  p99=%.1fms  avg=%.1fms\n",
 					m.LatencyP50, m.LatencyP95, m.LatencyP99, m.LatencyAvg)
 			}
+			fmt.Printf("    Storage:    %s\n", footprint)
+		}
+
+		// Metrics across --k-range and the recommended k and min_score
+		var curve []metrics.KPoint
+		var fit *metrics.ThresholdFit
+		if len(ks) > 0 {
+			curve = metrics.Curve(deepResults, ks)
+			if f, ok := metrics.FitThreshold(deepResults, ks, targetPrecision); ok {
+				fit = &f
+			}
+			if !jsonOutput {
+				printKCurve(curve, fit, targetPrecision)
+			}
 		}
 
 		// Per-query failure analysis
@@ -275,32 +500,35 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 			Config:       cfg,
 			Metrics:      m,
 			Bootstrap:    bs,
+			Footprint:    footprint,
 			QueryResults: queryResults,
+			KCurve:       curve,
+			Threshold:    fit,
 		})
 	}
 
-	// Save run artifact
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output dir: %w", err)
+	// An interrupted run is saved for inspection, then stops: no baseline or CI checks
+	if interrupted != nil {
+		runResult.Incomplete = true
+		runPath, err := saveRunResult(runResult, outputDir)
+		if err != nil {
+			return err
+		}
+		done := 0
+		for _, c := range runResult.Configs {
+			if !c.Incomplete {
+				done++
+			}
+		}
+		return fmt.Errorf("simulation interrupted after %d of %d configs; partial run saved to %s: %w", done, len(configs), runPath, interrupted)
 	}
 
-	ts := strings.ReplaceAll(runResult.Timestamp, ":", "-")
-	runPath := filepath.Join(outputDir, fmt.Sprintf("%s.json", ts))
-
-	runData, err := json.MarshalIndent(runResult, "", "  ")
+	// Save run artifact
+	runPath, err := saveRunResult(runResult, outputDir)
 	if err != nil {
-		return fmt.Errorf("failed to marshal run result: %w", err)
+		return err
 	}
-
-	if err := os.WriteFile(runPath, runData, 0644); err != nil {
-		return fmt.Errorf("failed to write run file: %w", err)
-	}
-
-	// Also save as latest.json for convenience
 	latestPath := filepath.Join(outputDir, "latest.json")
-	if err := os.WriteFile(latestPath, runData, 0644); err != nil {
-		return fmt.Errorf("failed to write latest.json: %w", err)
-	}
 
 	if !jsonOutput {
 		fmt.Printf("\n✓ Run saved to %s\n", runPath)
@@ -409,6 +637,182 @@ func runSimulate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runQuery embeds q, searches coll for its top k results in mode, and
+// returns them as a query result, with the latency of both steps.
+func runQuery(ctx context.Context, store vectorstore.Store, emb embedder.Embedder, coll, mode string, k int, q config.Query) (metrics.QueryResult, error) {
+	// Track latency
+	queryStart := time.Now()
+
+	// Embed query
+	vec, err := emb.Embed(ctx, q.Text)
+	if err != nil {
+		return metrics.QueryResult{}, fmt.Errorf("failed to embed query %s: %w", q.ID, err)
+	}
+
+	// Search
+	results, err := store.Search(ctx, coll, vec, searchLimit(mode, k))
+	if err != nil {
+		return metrics.QueryResult{}, fmt.Errorf("search failed for query %s: %w", q.ID, err)
+	}
+	if mode == returnParent {
+		results = collapseToParents(results, k)
+	}
+
+	// Calculate latency (embedding + search)
+	latencyMs := float64(time.Since(queryStart).Microseconds()) / 1000.0
+
+	// Extract IDs (source files) from results
+	var retrievedIDs []string
+	var scores []float32
+	for _, r := range results {
+		source := getPayloadString(r.Payload, "source")
+		// Extract just the filename for matching
+		source = filepath.Base(source)
+		// Normalize: map chunk-level sources back to parent doc.
+		// e.g. "rfc6749_oauth2_cs0227.txt" -> "rfc6749_oauth2.txt"
+		// if "rfc6749_oauth2.txt" is in the relevant docs.
+		source = normalizeSource(source, q.RelevantDocs)
+		source = mergedSource(r.Payload, source, q.RelevantDocs)
+		// Documents with passages count only the chunks overlapping one
+		if len(q.Passages) > 0 {
+			source = passageID(r.Payload, source, q, minOverlap, maxChunkRatio)
+		}
+		retrievedIDs = append(retrievedIDs, source)
+		scores = append(scores, r.Score)
+	}
+
+	return metrics.QueryResult{
+		QueryID:      q.ID,
+		Query:        q.Text,
+		RetrievedIDs: retrievedIDs,
+		RelevantIDs:  q.RelevantIDs(),
+		Scores:       scores,
+		LatencyMs:    latencyMs,
+		Grades:       q.Grades(),
+		MaxGrade:     float64(q.GradeScale()),
+	}, nil
+}
+
+// describeConfigTarget formats the optional collection and vector settings of a config
+// for the per-config header line.
+func describeConfigTarget(cfg config.SimConfig) string {
+	var parts []string
+	if cfg.Collection != "" {
+		parts = append(parts, "collection="+cfg.Collection)
+	}
+	if cfg.Embedder != "" {
+		parts = append(parts, "embedder="+cfg.Embedder)
+	}
+	if cfg.Chunker != "" {
+		parts = append(parts, "chunker="+cfg.Chunker)
+	}
+	if cfg.ChunkSize > 0 {
+		parts = append(parts, fmt.Sprintf("chunk_size=%d", cfg.ChunkSize))
+	}
+	if cfg.Overlap > 0 {
+		parts = append(parts, fmt.Sprintf("overlap=%d", cfg.Overlap))
+	}
+	if cfg.Breadcrumb {
+		parts = append(parts, "breadcrumb")
+	}
+	if cfg.Enrich != "" {
+		parts = append(parts, fmt.Sprintf("enrich=%q", cfg.Enrich))
+	}
+	if cfg.BreakpointPercentile > 0 {
+		parts = append(parts, fmt.Sprintf("breakpoint=p%g", cfg.BreakpointPercentile))
+	}
+	if cfg.MinChunkSize > 0 {
+		parts = append(parts, fmt.Sprintf("min_chunk=%d", cfg.MinChunkSize))
+	}
+	if cfg.ParentSize > 0 {
+		parts = append(parts, fmt.Sprintf("parent_size=%d", cfg.ParentSize))
+	}
+	if cfg.Return != "" {
+		parts = append(parts, "return="+cfg.Return)
+	}
+	if cfg.Dims > 0 {
+		parts = append(parts, fmt.Sprintf("dims=%d", cfg.Dims))
+	}
+	if cfg.Quantization != "" {
+		parts = append(parts, "quantization="+cfg.Quantization)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(parts, ", ")
+}
+
+// configEmbedder creates the embedder for a config that sets embedder,
+// dims or quantization.
+func configEmbedder(cfg config.SimConfig) (embedder.Embedder, error) {
+	name := embedderName
+	if cfg.Embedder != "" {
+		name = cfg.Embedder
+	}
+	raw, err := createEmbedder(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init embedder: %w", err)
+	}
+	dims, quant := configTransform(cfg)
+	return withVectorTransform(raw, dims, quant)
+}
+
+// configTransform returns the vector transform of cfg. Dims and
+// quantization each fall back to --dims and --quantization when unset.
+func configTransform(cfg config.SimConfig) (int, string) {
+	dims, quant := cfg.Dims, cfg.Quantization
+	if dims == 0 {
+		dims = vectorDims
+	}
+	if quant == "" {
+		quant = quantization
+	}
+	return dims, quant
+}
+
+// computeFootprint estimates storage for the vectors emb produces in coll.
+// The vector count is best-effort; it is left at zero if the store can't report it.
+func computeFootprint(ctx context.Context, store vectorstore.Store, coll string, emb embedder.Embedder) *StorageFootprint {
+	q := embedder.QuantizationNone
+	if te, ok := emb.(*embedder.TransformEmbedder); ok {
+		q = te.Quantization()
+	}
+
+	f := &StorageFootprint{
+		Dims:           emb.Dim(),
+		Quantization:   string(q),
+		BytesPerVector: q.BytesPerVector(emb.Dim()),
+	}
+	if n, err := store.Count(ctx, coll); err == nil {
+		f.Vectors = n
+		f.TotalBytes = n * int64(f.BytesPerVector)
+	}
+	return f
+}
+
+// String formats the footprint for console output.
+func (f *StorageFootprint) String() string {
+	s := fmt.Sprintf("%d dims × %s = %s/vector", f.Dims, f.Quantization, formatBytes(int64(f.BytesPerVector)))
+	if f.Vectors > 0 {
+		s += fmt.Sprintf(" (%s for %d vectors)", formatBytes(f.TotalBytes), f.Vectors)
+	}
+	return s
+}
+
+// formatBytes renders a byte count with a binary unit suffix.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // QueryFailure represents a query that failed to retrieve its relevant documents.
 type QueryFailure struct {
 	QueryID       string
@@ -582,6 +986,36 @@ func checkCIThresholds(result RunResult) error {
 }
 
 // loadBaseline reads a previous run result from a JSON file.
+// saveRunResult writes the run to dir as <timestamp>.json and returns its
+// path. Complete runs are also saved as latest.json, so an interrupted run
+// never replaces the baseline.
+func saveRunResult(run RunResult, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	ts := strings.ReplaceAll(run.Timestamp, ":", "-")
+	runPath := filepath.Join(dir, fmt.Sprintf("%s.json", ts))
+
+	runData, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal run result: %w", err)
+	}
+
+	if err := os.WriteFile(runPath, runData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write run file: %w", err)
+	}
+	if run.Incomplete {
+		return runPath, nil
+	}
+
+	// Also save as latest.json for convenience
+	if err := os.WriteFile(filepath.Join(dir, "latest.json"), runData, 0644); err != nil {
+		return "", fmt.Errorf("failed to write latest.json: %w", err)
+	}
+	return runPath, nil
+}
+
 func loadBaseline(path string) (*RunResult, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -596,6 +1030,9 @@ func loadBaseline(path string) (*RunResult, error) {
 	if len(result.Configs) == 0 {
 		return nil, fmt.Errorf("baseline has no configs")
 	}
+	if result.Incomplete {
+		return nil, fmt.Errorf("baseline %s is an incomplete (interrupted) run", path)
+	}
 
 	return &result, nil
 }
@@ -760,16 +1197,18 @@ func checkRegressions(deltas []MetricDelta) error {
 
 // JSONOutput represents the machine-readable output format for CI pipelines.
 type JSONOutput struct {
-	Status     string             `json:"status"` // "pass" or "fail"
-	Timestamp  string             `json:"timestamp"`
-	Collection string             `json:"collection"`
-	Store      string             `json:"store"`
-	Metrics    JSONMetrics        `json:"metrics"`
-	Bootstrap  *JSONBootstrap     `json:"bootstrap,omitempty"`
-	Baseline   *JSONBaseline      `json:"baseline,omitempty"`
-	Thresholds *JSONThresholds    `json:"thresholds,omitempty"`
-	Failures   []JSONQueryFailure `json:"failures,omitempty"`
-	RunFile    string             `json:"run_file"`
+	Status     string                `json:"status"` // "pass" or "fail"
+	Timestamp  string                `json:"timestamp"`
+	Collection string                `json:"collection"`
+	Store      string                `json:"store"`
+	Metrics    JSONMetrics           `json:"metrics"`
+	Bootstrap  *JSONBootstrap        `json:"bootstrap,omitempty"`
+	Baseline   *JSONBaseline         `json:"baseline,omitempty"`
+	Thresholds *JSONThresholds       `json:"thresholds,omitempty"`
+	Failures   []JSONQueryFailure    `json:"failures,omitempty"`
+	KCurve     []metrics.KPoint      `json:"k_curve,omitempty"`
+	Threshold  *metrics.ThresholdFit `json:"threshold,omitempty"`
+	RunFile    string                `json:"run_file"`
 }
 
 // JSONMetrics contains the core metrics in JSON format.
@@ -777,6 +1216,8 @@ type JSONMetrics struct {
 	RecallAtK  float64 `json:"recall_at_k"`
 	MRR        float64 `json:"mrr"`
 	NDCGAtK    float64 `json:"ndcg_at_k"`
+	ERRAtK     float64 `json:"err_at_k"`
+	GradedPAtK float64 `json:"graded_precision_at_k"`
 	Coverage   float64 `json:"coverage"`
 	Redundancy float64 `json:"redundancy"`
 	LatencyP50 float64 `json:"latency_p50_ms"`
@@ -802,6 +1243,8 @@ type JSONBootstrap struct {
 	NDCGStd      float64 `json:"ndcg_std"`
 	CoverageMean float64 `json:"coverage_mean"`
 	CoverageStd  float64 `json:"coverage_std"`
+	// Intervals holds percentile and BCa intervals for every metric.
+	Intervals map[string]metrics.Interval `json:"intervals,omitempty"`
 }
 
 // JSONBaseline contains baseline comparison data.
@@ -862,6 +1305,8 @@ func buildJSONOutput(result RunResult, runPath string, failures []QueryFailure,
 			RecallAtK:  m.RecallAtK,
 			MRR:        m.MRR,
 			NDCGAtK:    m.NDCGAtK,
+			ERRAtK:     m.ERRAtK,
+			GradedPAtK: m.GradedPrecisionAtK,
 			Coverage:   m.Coverage,
 			Redundancy: m.Redundancy,
 			LatencyP50: m.LatencyP50,
@@ -871,6 +1316,8 @@ func buildJSONOutput(result RunResult, runPath string, failures []QueryFailure,
 			QueryCount: len(cfg.QueryResults),
 			TopK:       cfg.Config.TopK,
 		},
+		KCurve:    cfg.KCurve,
+		Threshold: cfg.Threshold,
 	}
 
 	// Add bootstrap data if available
@@ -890,6 +1337,7 @@ func buildJSONOutput(result RunResult, runPath string, failures []QueryFailure,
 			NDCGStd:      bs.NDCGStd,
 			CoverageMean: bs.CoverageMean,
 			CoverageStd:  bs.CoverageStd,
+			Intervals:    bs.Intervals,
 		}
 	}
 
@@ -984,3 +1432,18 @@ func normalizeSource(source string, relevantDocs []string) string {
 
 	return source
 }
+
+// mergedSource returns source, or if it is not relevant, the first of the
+// payload's merged duplicate sources (ingest --dedup merge) that is, so a
+// chunk copied into several documents counts as any of them.
+func mergedSource(payload map[string]interface{}, source string, relevantDocs []string) string {
+	if slices.Contains(relevantDocs, source) {
+		return source
+	}
+	for _, s := range getPayloadStrings(payload, "sources") {
+		if alt := normalizeSource(filepath.Base(s), relevantDocs); slices.Contains(relevantDocs, alt) {
+			return alt
+		}
+	}
+	return source
+}
@@ -2,12 +2,17 @@ package cli
 
 import (
 	"bytes"
+	"context"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/embedder"
 	"github.com/metawake/ragtune/internal/metrics"
+	"github.com/metawake/ragtune/internal/vectorstore"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
 )
 
 func TestCheckCIThresholds_AllPass(t *testing.T) {
@@ -835,3 +840,187 @@ func TestAbs(t *testing.T) {
 		}
 	}
 }
+
+// stubEmbedder returns a deterministic vector derived from the text length.
+type stubEmbedder struct {
+	dim int
+}
+
+func (s *stubEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec := make([]float32, s.dim)
+	for i := range vec {
+		vec[i] = float32((len(text)+i)%7) + 1
+	}
+	return vec, nil
+}
+
+func (s *stubEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i], _ = s.Embed(ctx, text)
+	}
+	return out, nil
+}
+
+func (s *stubEmbedder) Dim() int {
+	return s.dim
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1024, "1.0 KiB"},
+		{1536 * 1024, "1.5 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.input); got != tt.expected {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
+
+func TestDescribeConfigTarget(t *testing.T) {
+	if got := describeConfigTarget(config.SimConfig{Name: "plain", TopK: 5}); got != "" {
+		t.Errorf("expected empty description for plain config, got %q", got)
+	}
+
+	cfg := config.SimConfig{Name: "small", TopK: 5, Collection: "docs-256", Chunker: "sentence", Dims: 256, Quantization: "int8"}
+	want := ", collection=docs-256, chunker=sentence, dims=256, quantization=int8"
+	if got := describeConfigTarget(cfg); got != want {
+		t.Errorf("describeConfigTarget() = %q, want %q", got, want)
+	}
+
+	cfg = config.SimConfig{Name: "semantic", TopK: 5, Chunker: "semantic", BreakpointPercentile: 90, MinChunkSize: 200}
+	want = ", chunker=semantic, breakpoint=p90, min_chunk=200"
+	if got := describeConfigTarget(cfg); got != want {
+		t.Errorf("describeConfigTarget() = %q, want %q", got, want)
+	}
+
+	cfg = config.SimConfig{Name: "contextual", TopK: 5, Collection: "docs-ctx", Enrich: "{{title}}\n{{text}}"}
+	want = `, collection=docs-ctx, enrich="{{title}}\n{{text}}"`
+	if got := describeConfigTarget(cfg); got != want {
+		t.Errorf("describeConfigTarget() = %q, want %q", got, want)
+	}
+
+	cfg = config.SimConfig{Name: "sweep", TopK: 5, Collection: "docs-3183d8c0d872", Embedder: "tei", Chunker: "fixed", ChunkSize: 256, Overlap: 32}
+	want = ", collection=docs-3183d8c0d872, embedder=tei, chunker=fixed, chunk_size=256, overlap=32"
+	if got := describeConfigTarget(cfg); got != want {
+		t.Errorf("describeConfigTarget() = %q, want %q", got, want)
+	}
+
+	cfg = config.SimConfig{Name: "parents", TopK: 5, ParentSize: 2048, Return: "parent"}
+	want = ", parent_size=2048, return=parent"
+	if got := describeConfigTarget(cfg); got != want {
+		t.Errorf("describeConfigTarget() = %q, want %q", got, want)
+	}
+}
+
+func TestComputeFootprint(t *testing.T) {
+	ctx := context.Background()
+	store := mock.New()
+	_ = store.EnsureCollection(ctx, "coll", 256)
+	_ = store.Upsert(ctx, "coll", []vectorstore.Point{
+		{ID: "a", Vector: make([]float32, 256)},
+		{ID: "b", Vector: make([]float32, 256)},
+	})
+
+	emb, err := withVectorTransform(&stubEmbedder{dim: 1024}, 256, "int8")
+	if err != nil {
+		t.Fatalf("withVectorTransform failed: %v", err)
+	}
+
+	f := computeFootprint(ctx, store, "coll", emb)
+
+	if f.Dims != 256 || f.Quantization != "int8" || f.BytesPerVector != 256 {
+		t.Errorf("unexpected footprint: %+v", f)
+	}
+	if f.Vectors != 2 || f.TotalBytes != 512 {
+		t.Errorf("vectors = %d, total = %d; want 2, 512", f.Vectors, f.TotalBytes)
+	}
+
+	// Missing collection: count is best-effort
+	f = computeFootprint(ctx, store, "missing", &stubEmbedder{dim: 8})
+	if f.Vectors != 0 || f.BytesPerVector != 32 || f.Quantization != "none" {
+		t.Errorf("unexpected footprint for missing collection: %+v", f)
+	}
+}
+
+func TestWithVectorTransform(t *testing.T) {
+	base := &stubEmbedder{dim: 64}
+
+	emb, err := withVectorTransform(base, 0, "none")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if emb != embedder.Embedder(base) {
+		t.Error("expected embedder to be returned unchanged without a transform")
+	}
+
+	if _, err := withVectorTransform(base, 0, "int4"); err == nil {
+		t.Error("expected error for unsupported quantization")
+	}
+	if _, err := withVectorTransform(base, -1, ""); err == nil {
+		t.Error("expected error for negative dims")
+	}
+}
+
+func TestConfigTransform(t *testing.T) {
+	oldDims, oldQuant := vectorDims, quantization
+	defer func() { vectorDims, quantization = oldDims, oldQuant }()
+	vectorDims, quantization = 512, "int8"
+
+	tests := []struct {
+		cfg       config.SimConfig
+		wantDims  int
+		wantQuant string
+	}{
+		{config.SimConfig{}, 512, "int8"},
+		{config.SimConfig{Dims: 256}, 256, "int8"},
+		{config.SimConfig{Quantization: "binary"}, 512, "binary"},
+		{config.SimConfig{Dims: 128, Quantization: "none"}, 128, "none"},
+	}
+	for _, tt := range tests {
+		dims, quant := configTransform(tt.cfg)
+		if dims != tt.wantDims || quant != tt.wantQuant {
+			t.Errorf("configTransform(%+v) = %d, %q; want %d, %q", tt.cfg, dims, quant, tt.wantDims, tt.wantQuant)
+		}
+	}
+}
+
+func TestSaveRunResult_Incomplete(t *testing.T) {
+	dir := t.TempDir()
+	run := RunResult{
+		Timestamp: "2025-01-08T12:00:00Z",
+		Configs:   []ConfigResult{{Config: config.SimConfig{Name: "default", TopK: 5}}},
+	}
+	if _, err := saveRunResult(run, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// An interrupted run gets its own file but leaves latest.json alone
+	run.Timestamp = "2025-01-08T13:00:00Z"
+	run.Incomplete = true
+	run.Configs[0].Incomplete = true
+	path, err := saveRunResult(run, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(path) != "2025-01-08T13-00-00Z.json" {
+		t.Errorf("path = %s", path)
+	}
+	latest, err := loadBaseline(filepath.Join(dir, "latest.json"))
+	if err != nil || latest.Timestamp != "2025-01-08T12:00:00Z" {
+		t.Errorf("latest.json = %+v (err %v), want the complete run", latest, err)
+	}
+
+	// Nor can it serve as a baseline
+	if _, err := loadBaseline(path); err == nil || !strings.Contains(err.Error(), "incomplete") {
+		t.Errorf("err = %v, want incomplete baseline rejected", err)
+	}
+}
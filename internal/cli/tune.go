@@ -0,0 +1,521 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/metawake/ragtune/internal/chunker"
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/metrics"
+	"github.com/metawake/ragtune/internal/tune"
+	"github.com/metawake/ragtune/internal/vectorstore"
+)
+
+// defaultTuneCollection prefixes the collections tune builds when
+// --collection is not set.
+const defaultTuneCollection = "tune"
+
+// maxTuneRows is the number of trials listed in the results table.
+const maxTuneRows = 20
+
+var (
+	tuneSpacePath   string
+	tuneDocs        string
+	tuneStrategy    string
+	tuneTrials      int
+	tuneSeed        int64
+	tuneEta         int
+	tuneMinQueries  int
+	tuneObjective   string
+	tuneConstraints []string
+	tunePareto      string
+	tuneOut         string
+	tuneCleanup     bool
+)
+
+var tuneCmd = &cobra.Command{
+	Use:   "tune",
+	Short: "Search chunking, top-k and embedder settings for the best config",
+	Long: `Search a space of configs for the one that optimizes a metric, subject to
+constraints on others, and write it as a configs file for simulate.
+
+The search space file lists the values to try for each setting:
+
+  space:
+    chunker: [fixed, sentence]
+    chunk_size: [256, 512, 1024]
+    overlap: [0, 64]
+    top_k: [3, 5, 10]
+    embedder: [ollama, tei]
+
+Settings the space leaves out keep their flag values. Every combination is
+a candidate, except those whose overlap is not smaller than their chunk
+size. hybrid_alpha is rejected: retrieval is vector-only.
+
+Candidates are run against collections built from --docs, one per distinct
+chunking and embedder setting, cached across runs as with simulate --docs.
+
+Strategies:
+  grid     Evaluate every candidate on every query
+  random   Evaluate --trials candidates sampled with --seed
+  halving  Successive halving: evaluate every candidate (or --trials of
+           them) on a few queries, keep the best 1/--eta, and repeat with
+           --eta times the queries until at most --eta remain, which are
+           evaluated on every query. Queries are shuffled with --seed.
+
+Objective and Constraints:
+  --objective names the metric to optimize: recall, mrr, ndcg, err,
+  coverage, diversity (maximized) or latency_p50, latency_p95, latency_p99,
+  latency_avg (minimized, in ms). --constraint bounds a metric, e.g.
+  "latency_p95<200" or "recall>=0.8", and may be repeated.
+
+Output:
+  A table of the trials, best first; the Pareto frontier of the objective
+  and p95 latency (or --pareto metrics) over the trials evaluated on every
+  query; the recommended config, written to --out for
+  simulate --configs; and the whole run, saved to --output.
+
+Examples:
+  ragtune tune --space space.yaml --docs ./docs --queries golden.json \
+    --embedder ollama --objective ndcg --constraint "latency_p95<200"
+
+  # Successive halving over a large space
+  ragtune tune --space space.yaml --docs ./docs --queries golden.json \
+    --strategy halving --eta 3
+
+  # Check the recommendation
+  ragtune simulate --collection tune --docs ./docs --queries golden.json \
+    --configs ragtune-tuned.yaml`,
+	RunE: runTune,
+}
+
+func init() {
+	tuneCmd.Flags().StringVar(&tuneSpacePath, "space", "", "Path to search space YAML/JSON file (required)")
+	tuneCmd.Flags().StringVar(&tuneDocs, "docs", "", "Path to documents to build each candidate's collection from (required)")
+	tuneCmd.Flags().StringVar(&queriesPath, "queries", "", "Path to queries JSON file (required)")
+	tuneCmd.Flags().StringVar(&tuneStrategy, "strategy", string(tune.StrategyGrid), "Search strategy: grid, random, halving")
+	tuneCmd.Flags().IntVar(&tuneTrials, "trials", 0, "Candidates to sample (random, halving; 0 = all for halving)")
+	tuneCmd.Flags().Int64Var(&tuneSeed, "seed", 42, "Random seed for sampling candidates and ordering queries")
+	tuneCmd.Flags().IntVar(&tuneEta, "eta", tune.DefaultEta, "Factor successive halving cuts candidates by")
+	tuneCmd.Flags().IntVar(&tuneMinQueries, "min-queries", tune.DefaultMinQueries, "Fewest queries successive halving evaluates a candidate on")
+	tuneCmd.Flags().StringVar(&tuneObjective, "objective", string(tune.MetricNDCG), "Metric to optimize")
+	tuneCmd.Flags().StringArrayVar(&tuneConstraints, "constraint", nil, `Bound on a metric, e.g. "latency_p95<200" (repeatable)`)
+	tuneCmd.Flags().StringVar(&tunePareto, "pareto", "", "Comma-separated metrics of the Pareto frontier (default: objective and latency_p95)")
+	tuneCmd.Flags().StringVar(&tuneOut, "out", "ragtune-tuned.yaml", "File to write the recommended config to")
+	tuneCmd.Flags().StringVar(&outputDir, "output", "runs", "Output directory for run artifacts")
+	tuneCmd.Flags().BoolVar(&tuneCleanup, "cleanup", false, "Delete the collections built for the candidates after the run")
+	tuneCmd.Flags().StringVar(&chunkerName, "chunker", "fixed", "Chunking strategy when the space does not vary it")
+	tuneCmd.Flags().IntVar(&chunkSize, "chunk-size", 512, "Chunk size when the space does not vary it")
+	tuneCmd.Flags().IntVar(&chunkOverlap, "chunk-overlap", 64, "Chunk overlap when the space does not vary it")
+	tuneCmd.Flags().Float64Var(&minOverlap, "min-overlap", defaultMinOverlap, "Fraction of a query passage a chunk must cover to count as a hit")
+	tuneCmd.Flags().Float64Var(&maxChunkRatio, "max-chunk-ratio", 0, "Longest a chunk may be, as a multiple of the query passage it covers, to count as a hit (0 = any)")
+	_ = tuneCmd.MarkFlagRequired("space")
+	_ = tuneCmd.MarkFlagRequired("docs")
+	_ = tuneCmd.MarkFlagRequired("queries")
+
+	rootCmd.AddCommand(tuneCmd)
+}
+
+// TuneResult is the artifact of a tune run.
+type TuneResult struct {
+	Timestamp   string       `json:"timestamp"`
+	Collection  string       `json:"collection"`
+	Store       string       `json:"store"`
+	Strategy    string       `json:"strategy"`
+	Objective   string       `json:"objective"`
+	Constraints []string     `json:"constraints,omitempty"`
+	Queries     int          `json:"queries"`
+	Candidates  int          `json:"candidates"`
+	Trials      []tune.Trial `json:"trials"`
+	// Pareto names the trials on the Pareto frontier of ParetoMetrics.
+	ParetoMetrics []string          `json:"pareto_metrics"`
+	Pareto        []string          `json:"pareto,omitempty"`
+	Recommended   *config.SimConfig `json:"recommended,omitempty"`
+	Incomplete    bool              `json:"incomplete,omitempty"`
+}
+
+func runTune(cmd *cobra.Command, args []string) error {
+	objective, err := tune.ParseMetric(tuneObjective)
+	if err != nil {
+		return fmt.Errorf("--objective: %w", err)
+	}
+	var constraints []tune.Constraint
+	for _, s := range tuneConstraints {
+		c, err := tune.ParseConstraint(s)
+		if err != nil {
+			return err
+		}
+		constraints = append(constraints, c)
+	}
+	pareto, err := paretoMetrics(objective, tunePareto)
+	if err != nil {
+		return err
+	}
+	strategy, err := tune.ParseStrategy(tuneStrategy)
+	if err != nil {
+		return err
+	}
+	if err := validateMinOverlap(minOverlap); err != nil {
+		return err
+	}
+	if err := validateMaxChunkRatio(maxChunkRatio); err != nil {
+		return err
+	}
+	if strategy == tune.StrategyGrid && tuneTrials > 0 {
+		return fmt.Errorf("--trials applies to --strategy random and halving")
+	}
+	tuner, err := tune.New(objective,
+		tune.WithStrategy(strategy),
+		tune.WithConstraints(constraints...),
+		tune.WithTrials(tuneTrials),
+		tune.WithSeed(tuneSeed),
+		tune.WithEta(tuneEta),
+		tune.WithMinQueries(tuneMinQueries),
+		tune.WithProgress(printTrial(objective)),
+	)
+	if err != nil {
+		return err
+	}
+
+	space, err := config.LoadSearchSpace(tuneSpacePath)
+	if err != nil {
+		return fmt.Errorf("failed to load search space: %w", err)
+	}
+	for _, name := range space.Chunker {
+		if !slices.Contains(chunker.StrategyNames(), name) {
+			return fmt.Errorf("search space: unsupported chunker: %s (supported: %s)", name, strings.Join(chunker.StrategyNames(), ", "))
+		}
+	}
+	// Fill in the flag values of the settings the space does not vary
+	var candidates []config.SimConfig
+	skipped := 0
+	for _, cfg := range space.Grid() {
+		if cfg.TopK == 0 {
+			cfg.TopK = topK
+		}
+		if len(space.Overlap) == 0 {
+			cfg.Overlap = chunkOverlap
+		}
+		cfg = resolveChunkConfig(cfg)
+		if cfg.Overlap >= cfg.ChunkSize {
+			skipped++
+			continue
+		}
+		candidates = append(candidates, cfg)
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("search space has no candidates: every overlap is at least its chunk size")
+	}
+
+	if collectionName == "" {
+		collectionName = defaultTuneCollection
+	}
+	ctx := commandContext(cmd)
+
+	store, err := initVectorStore(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to init vector store: %w", err)
+	}
+	defer closeWithLog(store, "vector store")
+
+	queries, err := config.LoadQueries(queriesPath)
+	if err != nil {
+		return fmt.Errorf("failed to load queries: %w", err)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries in %s", queriesPath)
+	}
+	if strategy == tune.StrategyHalving {
+		// Early rounds see a prefix of the queries: shuffle so it is a sample
+		rand.New(rand.NewSource(tuneSeed)).Shuffle(len(queries), func(i, j int) {
+			queries[i], queries[j] = queries[j], queries[i]
+		})
+	}
+
+	builder, err := newDerivedBuilder(ctx, store, tuneDocs, false)
+	if err != nil {
+		return err
+	}
+	if tuneCleanup {
+		defer func() {
+			// Clean up even after an interrupt
+			if err := builder.cleanup(context.WithoutCancel(ctx)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}()
+	}
+	eval := &tuneEvaluator{
+		store:   store,
+		builder: builder,
+		queries: queries,
+		embs:    make(map[string]embedder.Embedder),
+		results: make(map[string][]metrics.QueryResult),
+	}
+	for _, name := range space.Embedder {
+		if _, err := eval.embedder(name); err != nil {
+			return err
+		}
+	}
+
+	goal := "maximize"
+	if objective.Minimize() {
+		goal = "minimize"
+	}
+	fmt.Printf("Loaded %d queries; %d candidates\n", len(queries), len(candidates))
+	if skipped > 0 {
+		fmt.Printf("Skipped %d candidates whose overlap is not smaller than their chunk size\n", skipped)
+	}
+	fmt.Printf("Search: %s; %s %s", strategy, goal, objective)
+	for i, c := range constraints {
+		if i == 0 {
+			fmt.Print(" subject to ")
+		} else {
+			fmt.Print(", ")
+		}
+		fmt.Print(c)
+	}
+	fmt.Println()
+	fmt.Println()
+
+	result := TuneResult{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Collection: collectionName,
+		Store:      storeName,
+		Strategy:   string(strategy),
+		Objective:  string(objective),
+		Queries:    len(queries),
+		Candidates: len(candidates),
+	}
+	for _, c := range constraints {
+		result.Constraints = append(result.Constraints, c.String())
+	}
+	for _, m := range pareto {
+		result.ParetoMetrics = append(result.ParetoMetrics, string(m))
+	}
+
+	trials, err := tuner.Run(ctx, candidates, len(queries), eval.eval)
+	result.Trials = trials
+	if err != nil {
+		if interrupted := ctx.Err(); interrupted != nil {
+			result.Incomplete = true
+			runPath, saveErr := saveTuneResult(result, outputDir)
+			if saveErr != nil {
+				return saveErr
+			}
+			return fmt.Errorf("tuning interrupted after %d trials; partial run saved to %s: %w", len(trials), runPath, interrupted)
+		}
+		return err
+	}
+
+	printTuneTable(tuner.Rank(trials), objective)
+
+	front := tune.Pareto(trials, pareto...)
+	if len(front) > 0 {
+		names := make([]string, len(pareto))
+		for i, m := range pareto {
+			names[i] = string(m)
+		}
+		fmt.Printf("\nPareto frontier (%s):\n", strings.Join(names, " vs "))
+		for _, tr := range front {
+			result.Pareto = append(result.Pareto, tr.Config.Name)
+			var values []string
+			for _, m := range pareto {
+				values = append(values, formatMetric(m, tr.Metrics))
+			}
+			fmt.Printf("  %-40s %s\n", tr.Config.Name, strings.Join(values, "  "))
+		}
+	}
+
+	best, found := tuner.Best(trials)
+	if found {
+		rec := recommendedConfig(best.Config)
+		result.Recommended = &rec
+	}
+
+	runPath, err := saveTuneResult(result, outputDir)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		fmt.Printf("\n✓ Tune run saved to %s\n", runPath)
+		return fmt.Errorf("no candidate evaluated on every query meets the constraints")
+	}
+	if err := writeRecommendedConfig(tuneOut, *result.Recommended, best, objective); err != nil {
+		return err
+	}
+	fmt.Printf("\n✓ Recommended: %s (%s)\n", best.Config.Name, formatMetric(objective, best.Metrics))
+	fmt.Printf("✓ Config written to %s\n", tuneOut)
+	fmt.Printf("✓ Tune run saved to %s\n", runPath)
+	return nil
+}
+
+// paretoMetrics returns the metrics of the Pareto frontier: those listed,
+// or else the objective and p95 latency (NDCG and p95 latency if p95 latency
+// is the objective).
+func paretoMetrics(objective tune.Metric, list string) ([]tune.Metric, error) {
+	if list == "" {
+		if objective == tune.MetricLatencyP95 {
+			return []tune.Metric{tune.MetricNDCG, objective}, nil
+		}
+		return []tune.Metric{objective, tune.MetricLatencyP95}, nil
+	}
+	var ms []tune.Metric
+	for _, name := range strings.Split(list, ",") {
+		m, err := tune.ParseMetric(name)
+		if err != nil {
+			return nil, fmt.Errorf("--pareto: %w", err)
+		}
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// tuneEvaluator runs candidates for tune. It builds their collections with
+// a derivedBuilder and keeps each candidate's query results, so successive
+// halving only runs the queries a candidate has not seen yet.
+type tuneEvaluator struct {
+	store   vectorstore.Store
+	builder *derivedBuilder
+	queries []config.Query
+
+	embs    map[string]embedder.Embedder     // by embedder name
+	results map[string][]metrics.QueryResult // by candidate name
+}
+
+// eval computes the metrics of cfg over the first n queries.
+func (e *tuneEvaluator) eval(ctx context.Context, cfg config.SimConfig, n int) (metrics.Result, error) {
+	emb, err := e.embedder(cfg.Embedder)
+	if err != nil {
+		return metrics.Result{}, err
+	}
+	coll, err := e.builder.collection(ctx, resolveChunkConfig(cfg), emb)
+	if err != nil {
+		return metrics.Result{}, err
+	}
+
+	done := e.results[cfg.Name]
+	for _, q := range e.queries[len(done):n] {
+		qr, err := runQuery(ctx, e.store, emb, coll, returnChunk, cfg.TopK, q)
+		if err != nil {
+			return metrics.Result{}, err
+		}
+		done = append(done, qr)
+	}
+	e.results[cfg.Name] = done
+	return metrics.Compute(done[:n], cfg.TopK), nil
+}
+
+// embedder returns the embedder named, or the --embedder one for "".
+func (e *tuneEvaluator) embedder(name string) (embedder.Embedder, error) {
+	if name == "" {
+		name = embedderName
+	}
+	if emb, ok := e.embs[name]; ok {
+		return emb, nil
+	}
+	emb, err := configEmbedder(config.SimConfig{Embedder: name})
+	if err != nil {
+		return nil, fmt.Errorf("embedder %s: %w", name, err)
+	}
+	e.embs[name] = emb
+	return emb, nil
+}
+
+// printTrial returns a progress function printing each evaluation.
+func printTrial(objective tune.Metric) func(tune.Trial) {
+	return func(tr tune.Trial) {
+		status := ""
+		if !tr.Feasible() {
+			status = "  ✗ " + strings.Join(tr.Violated, ", ")
+		}
+		fmt.Printf("  %-40s %4d queries  %s  p95=%.1fms%s\n",
+			tr.Config.Name, tr.Queries, formatMetric(objective, tr.Metrics), tr.Metrics.LatencyP95, status)
+	}
+}
+
+// printTuneTable prints the ranked trials.
+func printTuneTable(ranked []tune.Trial, objective tune.Metric) {
+	fmt.Println()
+	fmt.Println(strings.Repeat("=", 96))
+	fmt.Println("TUNING RESULTS")
+	fmt.Println(strings.Repeat("=", 96))
+	fmt.Printf("\n| %-40s | Queries | Recall | MRR   | NDCG  | p95 ms  | Status\n", "Config")
+	fmt.Println("|" + strings.Repeat("-", 42) + "|---------|--------|-------|-------|---------|--------")
+	for i, tr := range ranked {
+		if i == maxTuneRows {
+			fmt.Printf("  ... and %d more (see the run file)\n", len(ranked)-i)
+			break
+		}
+		status := "✓"
+		switch {
+		case !tr.Complete:
+			status = fmt.Sprintf("dropped after round %d", tr.Rung+1)
+		case !tr.Feasible():
+			status = "✗ " + strings.Join(tr.Violated, ", ")
+		}
+		fmt.Printf("| %-40s | %7d | %.3f  | %.3f | %.3f | %7.1f | %s\n",
+			truncateStr(tr.Config.Name, 40), tr.Queries, tr.Metrics.RecallAtK, tr.Metrics.MRR, tr.Metrics.NDCGAtK, tr.Metrics.LatencyP95, status)
+	}
+}
+
+// formatMetric formats a metric's value in r as name=value.
+func formatMetric(m tune.Metric, r metrics.Result) string {
+	if m.Minimize() {
+		return fmt.Sprintf("%s=%.1fms", m, m.Value(r))
+	}
+	return fmt.Sprintf("%s=%.3f", m, m.Value(r))
+}
+
+// recommendedConfig returns cfg with the embedder made explicit. Its
+// chunking settings are already resolved, so simulate --docs builds (or
+// reuses) the same collection.
+func recommendedConfig(cfg config.SimConfig) config.SimConfig {
+	if cfg.Embedder == "" {
+		cfg.Embedder = embedderName
+	}
+	return cfg
+}
+
+// writeRecommendedConfig writes cfg as a configs file for simulate.
+func writeRecommendedConfig(path string, cfg config.SimConfig, best tune.Trial, objective tune.Metric) error {
+	data, err := yaml.Marshal(config.ConfigFile{Configs: []config.SimConfig{cfg}})
+	if err != nil {
+		return fmt.Errorf("failed to marshal recommended config: %w", err)
+	}
+	header := fmt.Sprintf("# Recommended by ragtune tune: %s over %d queries\n", formatMetric(objective, best.Metrics), best.Queries)
+	if err := os.WriteFile(path, append([]byte(header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write recommended config: %w", err)
+	}
+	return nil
+}
+
+// saveTuneResult saves the run as tune-<timestamp>.json under dir.
+func saveTuneResult(result TuneResult, dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	ts := strings.ReplaceAll(result.Timestamp, ":", "-")
+	runPath := filepath.Join(dir, fmt.Sprintf("tune-%s.json", ts))
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal tune result: %w", err)
+	}
+	if err := os.WriteFile(runPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write run file: %w", err)
+	}
+	return runPath, nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/embedder"
+	"github.com/metawake/ragtune/internal/metrics"
+	"github.com/metawake/ragtune/internal/tune"
+	"github.com/metawake/ragtune/internal/vectorstore/mock"
+)
+
+func TestTuneEvaluator(t *testing.T) {
+	oldColl, oldEmb := collectionName, embedderName
+	oldName, oldSize, oldOverlap := chunkerName, chunkSize, chunkOverlap
+	defer func() {
+		collectionName, embedderName = oldColl, oldEmb
+		chunkerName, chunkSize, chunkOverlap = oldName, oldSize, oldOverlap
+	}()
+	collectionName, embedderName = "tune", "stub"
+	chunkerName, chunkSize, chunkOverlap = "fixed", 512, 64
+
+	ctx := context.Background()
+	dir := writeCorpus(t, 4)
+	store := &batchRecorder{Store: mock.New()}
+	b, err := newDerivedBuilder(ctx, store, dir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.dir = t.TempDir()
+
+	var queries []config.Query
+	for i := 0; i < 6; i++ {
+		queries = append(queries, config.Query{ID: fmt.Sprintf("q%d", i), Text: "document", RelevantDocs: []string{"doc01.md"}})
+	}
+	emb := &queryCounter{stubEmbedder: stubEmbedder{dim: 4}}
+	e := &tuneEvaluator{
+		store:   store,
+		builder: b,
+		queries: queries,
+		embs:    map[string]embedder.Embedder{"stub": emb},
+		results: make(map[string][]metrics.QueryResult),
+	}
+
+	cfg := config.SimConfig{Name: "c", TopK: 3, ChunkSize: 40}
+	if _, err := e.eval(ctx, cfg, 2); err != nil {
+		t.Fatalf("eval failed: %v", err)
+	}
+	if _, err := e.eval(ctx, cfg, 6); err != nil {
+		t.Fatalf("eval failed: %v", err)
+	}
+	if got := len(e.results["c"]); got != 6 {
+		t.Errorf("kept %d query results, want 6", got)
+	}
+	// Later rounds only run the queries not seen yet
+	if emb.queries != 6 {
+		t.Errorf("embedded %d queries, want 6", emb.queries)
+	}
+	if len(b.built) != 1 {
+		t.Errorf("built %d collections, want 1", len(b.built))
+	}
+}
+
+// queryCounter counts single embeddings, which only queries use.
+type queryCounter struct {
+	stubEmbedder
+	queries int
+}
+
+func (c *queryCounter) Embed(ctx context.Context, text string) ([]float32, error) {
+	c.queries++
+	return c.stubEmbedder.Embed(ctx, text)
+}
+
+func TestParetoMetrics(t *testing.T) {
+	got, err := paretoMetrics(tune.MetricRecall, "")
+	if err != nil || len(got) != 2 || got[0] != tune.MetricRecall || got[1] != tune.MetricLatencyP95 {
+		t.Errorf("paretoMetrics(recall) = %v, %v; want recall and latency_p95", got, err)
+	}
+	got, _ = paretoMetrics(tune.MetricLatencyP95, "")
+	if len(got) != 2 || got[0] != tune.MetricNDCG {
+		t.Errorf("paretoMetrics(latency_p95) = %v, want ndcg and latency_p95", got)
+	}
+	got, err = paretoMetrics(tune.MetricNDCG, "mrr, coverage,latency_p99")
+	if err != nil || len(got) != 3 || got[1] != tune.MetricCoverage {
+		t.Errorf("paretoMetrics with a list = %v, %v", got, err)
+	}
+	if _, err := paretoMetrics(tune.MetricNDCG, "ndcg,speed"); err == nil {
+		t.Error("expected an error for an unknown metric")
+	}
+}
+
+func TestWriteRecommendedConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuned.yaml")
+	cfg := config.SimConfig{Name: "chunk_size=256,top_k=5", TopK: 5, Chunker: "fixed", ChunkSize: 256, Embedder: "tei"}
+	best := tune.Trial{Config: cfg, Queries: 40, Complete: true, Metrics: metrics.Result{NDCGAtK: 0.8123}}
+	if err := writeRecommendedConfig(path, cfg, best, tune.MetricNDCG); err != nil {
+		t.Fatalf("writeRecommendedConfig failed: %v", err)
+	}
+
+	// simulate --configs reads it back
+	configs, err := config.LoadConfigs(path)
+	if err != nil {
+		t.Fatalf("LoadConfigs failed: %v", err)
+	}
+	if len(configs) != 1 || configs[0] != cfg {
+		t.Errorf("read back %+v, want %+v", configs, cfg)
+	}
+}
@@ -17,6 +17,28 @@ type SimConfig struct {
 	TopK       int    `json:"top_k" yaml:"top_k"`
 	ChunkSize  int    `json:"chunk_size,omitempty" yaml:"chunk_size,omitempty"`
 	Overlap    int    `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+	// Chunker names the chunking strategy: fixed, sentence, paragraph, recursive, token, markdown, semantic, or code.
+	Chunker string `json:"chunker,omitempty" yaml:"chunker,omitempty"`
+	// BreakpointPercentile and MinChunkSize tune the semantic chunker; chunk_size is its maximum.
+	BreakpointPercentile float64 `json:"breakpoint_percentile,omitempty" yaml:"breakpoint_percentile,omitempty"`
+	MinChunkSize         int     `json:"min_chunk_size,omitempty" yaml:"min_chunk_size,omitempty"`
+	// ParentSize records the --parent-size the collection was ingested with.
+	ParentSize int `json:"parent_size,omitempty" yaml:"parent_size,omitempty"`
+	// Return overrides --return for this config: chunk or parent.
+	Return string `json:"return,omitempty" yaml:"return,omitempty"`
+	// Breadcrumb marks collections whose chunks embed their markdown heading path.
+	Breadcrumb bool `json:"breadcrumb,omitempty" yaml:"breadcrumb,omitempty"`
+	// Enrich records the --enrich template the collection was ingested with.
+	Enrich string `json:"enrich,omitempty" yaml:"enrich,omitempty"`
+	// Collection overrides --collection for this config, e.g. to point each
+	// variant at a collection ingested with different vector settings.
+	Collection string `json:"collection,omitempty" yaml:"collection,omitempty"`
+	// Dims truncates embeddings to the first N dimensions (Matryoshka).
+	Dims int `json:"dims,omitempty" yaml:"dims,omitempty"`
+	// Quantization compresses embeddings: none, int8, or binary.
+	Quantization string `json:"quantization,omitempty" yaml:"quantization,omitempty"`
+	// Embedder overrides --embedder for this config.
+	Embedder string `json:"embedder,omitempty" yaml:"embedder,omitempty"`
 }
 
 // ConfigFile represents the configs file structure.
@@ -30,6 +52,78 @@ type Query struct {
 	Text        string   `json:"text" yaml:"text"`
 	RelevantDocs []string `json:"relevant_docs" yaml:"relevant_docs"`
 	Notes       string   `json:"notes,omitempty" yaml:"notes,omitempty"`
+	// Judgments grade documents for graded metrics. Documents graded above
+	// 0 are relevant whether or not relevant_docs lists them; relevant_docs
+	// entries without a judgment have grade 1.
+	Judgments []Judgment `json:"judgments,omitempty" yaml:"judgments,omitempty"`
+	// Passages pin relevance to parts of documents: a document with
+	// passages counts as found only when a retrieved chunk overlaps one.
+	Passages []Passage `json:"passages,omitempty" yaml:"passages,omitempty"`
+
+	// maxGrade is the highest grade judged in the query file, set by
+	// LoadQueries (0 = not loaded from a file).
+	maxGrade int
+}
+
+// Judgment grades how relevant a document is to a query: 0 for not
+// relevant, higher for more relevant (e.g. 1 related, 3 the answer).
+type Judgment struct {
+	Doc   string `json:"doc" yaml:"doc"`
+	Grade int    `json:"grade" yaml:"grade"`
+}
+
+// Grades returns the grade of each ID of RelevantIDs, or nil if the query
+// has no judgments and relevance is binary. Passages take their document's
+// grade.
+func (q Query) Grades() map[string]float64 {
+	grades := q.docGrades()
+	if grades == nil {
+		return nil
+	}
+	for _, p := range q.Passages {
+		if g, ok := grades[p.Doc]; ok {
+			grades[p.Label()] = g
+		}
+	}
+	for _, p := range q.Passages {
+		delete(grades, p.Doc)
+	}
+	return grades
+}
+
+// GradeScale returns the top of the grade scale for q's graded metrics:
+// the highest grade judged anywhere in its query file, at least 1, so every
+// query, and any subset of them, is scored on one scale. A query not loaded
+// by LoadQueries uses its own highest grade.
+func (q Query) GradeScale() int {
+	if q.maxGrade > 0 {
+		return q.maxGrade
+	}
+	scale := 1
+	for _, j := range q.Judgments {
+		scale = max(scale, j.Grade)
+	}
+	return scale
+}
+
+// docGrades returns the grade of each relevant document, or nil if the
+// query has no judgments.
+func (q Query) docGrades() map[string]float64 {
+	if len(q.Judgments) == 0 {
+		return nil
+	}
+	grades := make(map[string]float64, len(q.RelevantDocs))
+	for _, doc := range q.RelevantDocs {
+		grades[doc] = 1
+	}
+	for _, j := range q.Judgments {
+		if j.Grade > 0 {
+			grades[j.Doc] = float64(j.Grade)
+		} else {
+			delete(grades, j.Doc)
+		}
+	}
+	return grades
 }
 
 // QueriesFile represents the queries file structure.
@@ -45,18 +139,8 @@ func LoadConfigs(path string) ([]SimConfig, error) {
 	}
 
 	var cf ConfigFile
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(data, &cf); err != nil {
-			return nil, fmt.Errorf("failed to parse YAML: %w", err)
-		}
-	case ".json":
-		if err := json.Unmarshal(data, &cf); err != nil {
-			return nil, fmt.Errorf("failed to parse JSON: %w", err)
-		}
-	default:
-		return nil, fmt.Errorf("unsupported config format: %s (use .yaml or .json)", ext)
+	if err := unmarshal(path, data, &cf); err != nil {
+		return nil, err
 	}
 
 	// Set defaults
@@ -69,6 +153,24 @@ func LoadConfigs(path string) ([]SimConfig, error) {
 	return cf.Configs, nil
 }
 
+// unmarshal decodes data as YAML or JSON, by the extension of path.
+func unmarshal(path string, data []byte, v interface{}) error {
+	ext := filepath.Ext(path)
+	switch ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to parse YAML: %w", err)
+		}
+	case ".json":
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported config format: %s (use .yaml or .json)", ext)
+	}
+	return nil
+}
+
 // LoadQueries loads queries from a JSON file.
 func LoadQueries(path string) ([]Query, error) {
 	data, err := os.ReadFile(path)
@@ -81,9 +183,65 @@ func LoadQueries(path string) ([]Query, error) {
 		return nil, fmt.Errorf("failed to parse queries JSON: %w", err)
 	}
 
+	for i := range qf.Queries {
+		if err := applyJudgments(&qf.Queries[i]); err != nil {
+			return nil, err
+		}
+		if err := applyPassages(&qf.Queries[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	scale := 1
+	for _, q := range qf.Queries {
+		scale = max(scale, q.GradeScale())
+	}
+	for i := range qf.Queries {
+		qf.Queries[i].maxGrade = scale
+	}
+
 	return qf.Queries, nil
 }
 
+// applyJudgments validates q's judgments and makes RelevantDocs list the
+// documents graded above 0, so binary metrics agree with the grades.
+func applyJudgments(q *Query) error {
+	if len(q.Judgments) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool)
+	for _, j := range q.Judgments {
+		if j.Doc == "" {
+			return fmt.Errorf("query %s: judgment without a doc", q.ID)
+		}
+		if j.Grade < 0 {
+			return fmt.Errorf("query %s: judgment for %s has negative grade %d", q.ID, j.Doc, j.Grade)
+		}
+		if seen[j.Doc] {
+			return fmt.Errorf("query %s: %s is judged more than once", q.ID, j.Doc)
+		}
+		seen[j.Doc] = true
+	}
+
+	grades := q.docGrades()
+	relevant := make([]string, 0, len(grades))
+	for _, doc := range q.RelevantDocs {
+		if _, ok := grades[doc]; ok {
+			relevant = append(relevant, doc)
+			delete(grades, doc)
+		}
+	}
+	// Judged-only docs follow in judgment order
+	for _, j := range q.Judgments {
+		if _, ok := grades[j.Doc]; ok {
+			relevant = append(relevant, j.Doc)
+			delete(grades, j.Doc)
+		}
+	}
+	q.RelevantDocs = relevant
+	return nil
+}
+
 
 
 
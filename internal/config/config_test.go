@@ -103,6 +103,55 @@ func TestLoadConfigs_JSON(t *testing.T) {
 	}
 }
 
+func TestLoadConfigs_VectorSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "configs.yaml")
+
+	content := `configs:
+  - name: full
+    top_k: 5
+    collection: docs-full
+    chunker: sentence
+  - name: small
+    top_k: 5
+    collection: docs-256
+    dims: 256
+    quantization: int8
+  - name: semantic
+    top_k: 5
+    chunker: semantic
+    breakpoint_percentile: 90
+    min_chunk_size: 200
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configs, err := LoadConfigs(path)
+	if err != nil {
+		t.Fatalf("LoadConfigs failed: %v", err)
+	}
+
+	if configs[0].Collection != "docs-full" || configs[0].Dims != 0 || configs[0].Quantization != "" {
+		t.Errorf("unexpected configs[0]: %+v", configs[0])
+	}
+	if configs[0].Chunker != "sentence" {
+		t.Errorf("configs[0].Chunker = %q, want sentence", configs[0].Chunker)
+	}
+	if configs[1].Collection != "docs-256" {
+		t.Errorf("configs[1].Collection = %q, want docs-256", configs[1].Collection)
+	}
+	if configs[1].Dims != 256 {
+		t.Errorf("configs[1].Dims = %d, want 256", configs[1].Dims)
+	}
+	if configs[1].Quantization != "int8" {
+		t.Errorf("configs[1].Quantization = %q, want int8", configs[1].Quantization)
+	}
+	if configs[2].BreakpointPercentile != 90 || configs[2].MinChunkSize != 200 {
+		t.Errorf("unexpected semantic settings: %+v", configs[2])
+	}
+}
+
 func TestLoadConfigs_Defaults(t *testing.T) {
 	tmpDir := t.TempDir()
 	path := filepath.Join(tmpDir, "configs.yaml")
@@ -324,3 +373,99 @@ func TestLoadQueries_MinimalFields(t *testing.T) {
 		t.Errorf("expected empty notes, got %q", q.Notes)
 	}
 }
+
+func TestLoadQueries_Judgments(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "graded.json")
+
+	content := `{
+  "queries": [
+    {
+      "id": "q1",
+      "text": "How do I authenticate?",
+      "relevant_docs": ["auth.md", "overview.md", "legacy.md"],
+      "judgments": [
+        {"doc": "auth.md", "grade": 3},
+        {"doc": "tokens.md", "grade": 2},
+        {"doc": "legacy.md", "grade": 0}
+      ]
+    },
+    {"id": "q2", "text": "binary", "relevant_docs": ["a.md"]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	queries, err := LoadQueries(path)
+	if err != nil {
+		t.Fatalf("LoadQueries failed: %v", err)
+	}
+
+	// Judged docs join relevant_docs; grade 0 removes one
+	q := queries[0]
+	want := []string{"auth.md", "overview.md", "tokens.md"}
+	if len(q.RelevantDocs) != len(want) {
+		t.Fatalf("RelevantDocs = %v, want %v", q.RelevantDocs, want)
+	}
+	for i := range want {
+		if q.RelevantDocs[i] != want[i] {
+			t.Errorf("RelevantDocs = %v, want %v", q.RelevantDocs, want)
+			break
+		}
+	}
+
+	grades := q.Grades()
+	if grades["auth.md"] != 3 || grades["tokens.md"] != 2 || grades["overview.md"] != 1 {
+		t.Errorf("Grades() = %v", grades)
+	}
+	if _, ok := grades["legacy.md"]; ok {
+		t.Errorf("Grades() includes legacy.md graded 0: %v", grades)
+	}
+
+	// Ungraded queries stay binary
+	if g := queries[1].Grades(); g != nil {
+		t.Errorf("Grades() of an ungraded query = %v, want nil", g)
+	}
+
+	// Every query is scored on the file's grade scale
+	for _, q := range queries {
+		if q.GradeScale() != 3 {
+			t.Errorf("query %s: GradeScale() = %d, want 3", q.ID, q.GradeScale())
+		}
+	}
+	if s := (Query{Judgments: []Judgment{{Doc: "a.md", Grade: 2}}}).GradeScale(); s != 2 {
+		t.Errorf("GradeScale() of a query not loaded from a file = %d, want 2", s)
+	}
+	if s := (Query{}).GradeScale(); s != 1 {
+		t.Errorf("GradeScale() of a binary query = %d, want 1", s)
+	}
+}
+
+func TestLoadQueries_InvalidJudgments(t *testing.T) {
+	tests := map[string]string{
+		"missing doc":    `{"grade": 1}`,
+		"negative grade": `{"doc": "a.md", "grade": -1}`,
+	}
+	for name, judgment := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "q.json")
+			content := `{"queries": [{"id": "q1", "text": "t", "judgments": [` + judgment + `]}]}`
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadQueries(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "dup.json")
+	content := `{"queries": [{"id": "q1", "text": "t", "judgments": [{"doc": "a.md", "grade": 1}, {"doc": "a.md", "grade": 2}]}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadQueries(path); err == nil {
+		t.Error("expected error for a doc judged twice")
+	}
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Passage pins a query's relevance to part of a document. Set exactly one
+// of Chunk, Quote, or Start and End.
+type Passage struct {
+	Doc string `json:"doc" yaml:"doc"`
+	// Chunk is the chunk_id (index within the document) of the relevant
+	// chunk. Chunk IDs change with the chunking settings.
+	Chunk *int `json:"chunk,omitempty" yaml:"chunk,omitempty"`
+	// Quote is text of the passage, matched with whitespace collapsed.
+	Quote string `json:"quote,omitempty" yaml:"quote,omitempty"`
+	// Start and End are byte offsets of the passage in the document, End
+	// exclusive, as ingest records them in start_offset and end_offset.
+	Start int `json:"start,omitempty" yaml:"start,omitempty"`
+	End   int `json:"end,omitempty" yaml:"end,omitempty"`
+	// MinOverlap overrides simulate --min-overlap for this passage.
+	MinOverlap float64 `json:"min_overlap,omitempty" yaml:"min_overlap,omitempty"`
+}
+
+// Label identifies the passage in results and reports, e.g. "auth.md#chunk=3",
+// "auth.md#120-480", or `auth.md#"rotate the key..."`.
+func (p Passage) Label() string {
+	switch {
+	case p.Chunk != nil:
+		return fmt.Sprintf("%s#chunk=%d", p.Doc, *p.Chunk)
+	case p.Quote != "":
+		quote := strings.Join(strings.Fields(p.Quote), " ")
+		if runes := []rune(quote); len(runes) > 40 {
+			quote = string(runes[:37]) + "..."
+		}
+		return fmt.Sprintf("%s#%q", p.Doc, quote)
+	default:
+		return fmt.Sprintf("%s#%d-%d", p.Doc, p.Start, p.End)
+	}
+}
+
+// validate checks that p names a document and exactly one kind of target.
+func (p Passage) validate() error {
+	if p.Doc == "" {
+		return fmt.Errorf("passage without a doc")
+	}
+	kinds := 0
+	if p.Chunk != nil {
+		kinds++
+		if *p.Chunk < 0 {
+			return fmt.Errorf("passage in %s: chunk cannot be negative", p.Doc)
+		}
+	}
+	if p.Quote != "" {
+		kinds++
+		if strings.TrimSpace(p.Quote) == "" {
+			return fmt.Errorf("passage in %s: quote is blank", p.Doc)
+		}
+	}
+	if p.Start != 0 || p.End != 0 {
+		kinds++
+		if p.Start < 0 || p.End <= p.Start {
+			return fmt.Errorf("passage in %s: span %d-%d must have 0 <= start < end", p.Doc, p.Start, p.End)
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("passage in %s: set exactly one of chunk, quote, or start and end", p.Doc)
+	}
+	if p.MinOverlap < 0 || p.MinOverlap > 1 {
+		return fmt.Errorf("passage in %s: min_overlap must be between 0 and 1", p.Doc)
+	}
+	return nil
+}
+
+// RelevantIDs returns the IDs retrieved results are scored against: the
+// relevant documents without passages, then the labels of the passages.
+func (q Query) RelevantIDs() []string {
+	if len(q.Passages) == 0 {
+		return q.RelevantDocs
+	}
+	pinned := make(map[string]bool)
+	for _, p := range q.Passages {
+		pinned[p.Doc] = true
+	}
+	ids := make([]string, 0, len(q.RelevantDocs)+len(q.Passages))
+	for _, doc := range q.RelevantDocs {
+		if !pinned[doc] {
+			ids = append(ids, doc)
+		}
+	}
+	seen := make(map[string]bool)
+	for _, p := range q.Passages {
+		if label := p.Label(); !seen[label] {
+			seen[label] = true
+			ids = append(ids, label)
+		}
+	}
+	return ids
+}
+
+// applyPassages validates q's passages and adds their documents to
+// RelevantDocs, so results from them resolve to the document.
+func applyPassages(q *Query) error {
+	if len(q.Passages) == 0 {
+		return nil
+	}
+	judged := make(map[string]int)
+	for _, j := range q.Judgments {
+		judged[j.Doc] = j.Grade
+	}
+	for _, p := range q.Passages {
+		if err := p.validate(); err != nil {
+			return fmt.Errorf("query %s: %w", q.ID, err)
+		}
+		if grade, ok := judged[p.Doc]; ok && grade == 0 {
+			return fmt.Errorf("query %s: passage in %s, which is judged not relevant", q.ID, p.Doc)
+		}
+		found := false
+		for _, doc := range q.RelevantDocs {
+			if doc == p.Doc {
+				found = true
+				break
+			}
+		}
+		if !found {
+			q.RelevantDocs = append(q.RelevantDocs, p.Doc)
+		}
+	}
+	return nil
+}
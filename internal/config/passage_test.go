@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadQueries_Passages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passages.json")
+	content := `{
+  "queries": [{
+    "id": "q1",
+    "text": "How often do keys rotate?",
+    "relevant_docs": ["faq.md"],
+    "judgments": [{"doc": "auth.md", "grade": 3}, {"doc": "faq.md", "grade": 1}],
+    "passages": [
+      {"doc": "auth.md", "start": 120, "end": 480},
+      {"doc": "auth.md", "quote": "Keys rotate every 90 days", "min_overlap": 0.8},
+      {"doc": "policy.md", "chunk": 0}
+    ]
+  }]
+}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	queries, err := LoadQueries(path)
+	if err != nil {
+		t.Fatalf("LoadQueries failed: %v", err)
+	}
+	q := queries[0]
+
+	// Passage documents join relevant_docs for source resolution
+	if want := []string{"faq.md", "auth.md", "policy.md"}; !slices.Equal(q.RelevantDocs, want) {
+		t.Errorf("RelevantDocs = %v, want %v", q.RelevantDocs, want)
+	}
+	want := []string{"faq.md", "auth.md#120-480", `auth.md#"Keys rotate every 90 days"`, "policy.md#chunk=0"}
+	if got := q.RelevantIDs(); !slices.Equal(got, want) {
+		t.Errorf("RelevantIDs() = %v, want %v", got, want)
+	}
+
+	// Passages take their document's grade, 1 if unjudged
+	grades := q.Grades()
+	if grades["auth.md#120-480"] != 3 || grades["faq.md"] != 1 || grades["policy.md#chunk=0"] != 1 {
+		t.Errorf("Grades() = %v", grades)
+	}
+	if _, ok := grades["auth.md"]; ok {
+		t.Errorf("Grades() keeps auth.md, which has passages: %v", grades)
+	}
+}
+
+func TestLoadQueries_InvalidPassages(t *testing.T) {
+	tests := map[string]string{
+		"no doc":          `"passages": [{"quote": "x"}]`,
+		"no target":       `"passages": [{"doc": "a.md"}]`,
+		"two targets":     `"passages": [{"doc": "a.md", "quote": "x", "chunk": 1}]`,
+		"reversed span":   `"passages": [{"doc": "a.md", "start": 50, "end": 10}]`,
+		"negative chunk":  `"passages": [{"doc": "a.md", "chunk": -1}]`,
+		"bad min_overlap": `"passages": [{"doc": "a.md", "quote": "x", "min_overlap": 2}]`,
+		"judged 0":        `"judgments": [{"doc": "a.md", "grade": 0}], "passages": [{"doc": "a.md", "quote": "x"}]`,
+	}
+	for name, fields := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "q.json")
+			content := `{"queries": [{"id": "q1", "text": "t", ` + fields + `}]}`
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadQueries(path); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPassageLabel_LongQuote(t *testing.T) {
+	p := Passage{Doc: "a.md", Quote: strings.Repeat("é", 60)}
+	label := p.Label()
+	if !strings.HasSuffix(label, `..."`) || !strings.HasPrefix(label, `a.md#"`) {
+		t.Errorf("Label() = %s, want a truncated quote", label)
+	}
+	if len([]rune(label)) != len(`a.md#""`)+40 {
+		t.Errorf("Label() = %s has %d runes", label, len([]rune(label)))
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SearchSpace lists the values `ragtune tune` tries for each setting.
+// Settings left empty keep the command's flag values.
+type SearchSpace struct {
+	Chunker   []string `json:"chunker,omitempty" yaml:"chunker,omitempty"`
+	ChunkSize []int    `json:"chunk_size,omitempty" yaml:"chunk_size,omitempty"`
+	Overlap   []int    `json:"overlap,omitempty" yaml:"overlap,omitempty"`
+	TopK      []int    `json:"top_k,omitempty" yaml:"top_k,omitempty"`
+	Embedder  []string `json:"embedder,omitempty" yaml:"embedder,omitempty"`
+	// HybridAlpha weighs keyword against vector scores in hybrid search.
+	// Retrieval is vector-only, so LoadSearchSpace rejects a space that
+	// sets it rather than silently ignoring it.
+	HybridAlpha []float64 `json:"hybrid_alpha,omitempty" yaml:"hybrid_alpha,omitempty"`
+}
+
+// SearchSpaceFile represents the search space file structure.
+type SearchSpaceFile struct {
+	Space SearchSpace `json:"space" yaml:"space"`
+}
+
+// LoadSearchSpace loads a search space from a YAML or JSON file.
+func LoadSearchSpace(path string) (SearchSpace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SearchSpace{}, fmt.Errorf("failed to read search space file: %w", err)
+	}
+
+	var sf SearchSpaceFile
+	if err := unmarshal(path, data, &sf); err != nil {
+		return SearchSpace{}, err
+	}
+	if err := sf.Space.Validate(); err != nil {
+		return SearchSpace{}, err
+	}
+	return sf.Space, nil
+}
+
+// Validate checks that the space sets at least one value and that every
+// value is usable.
+func (s SearchSpace) Validate() error {
+	if len(s.HybridAlpha) > 0 {
+		return fmt.Errorf("hybrid_alpha: hybrid search is not supported (retrieval is vector-only); remove it from the space")
+	}
+	if len(s.Chunker)+len(s.ChunkSize)+len(s.Overlap)+len(s.TopK)+len(s.Embedder) == 0 {
+		return fmt.Errorf("search space is empty: set chunker, chunk_size, overlap, top_k or embedder")
+	}
+	for _, v := range s.ChunkSize {
+		if v <= 0 {
+			return fmt.Errorf("chunk_size must be positive, got %d", v)
+		}
+	}
+	for _, v := range s.Overlap {
+		if v < 0 {
+			return fmt.Errorf("overlap cannot be negative, got %d", v)
+		}
+	}
+	for _, v := range s.TopK {
+		if v <= 0 {
+			return fmt.Errorf("top_k must be positive, got %d", v)
+		}
+	}
+	for _, names := range [][]string{s.Chunker, s.Embedder} {
+		for _, v := range names {
+			if strings.TrimSpace(v) == "" {
+				return fmt.Errorf("chunker and embedder values cannot be empty")
+			}
+		}
+	}
+	return nil
+}
+
+// Grid returns every combination of the space's values as a config, skipping
+// those whose overlap is not smaller than their chunk size. Configs that
+// differ only in top_k are adjacent, so they can share a collection. Each is
+// named after the settings the space varies.
+func (s SearchSpace) Grid() []SimConfig {
+	configs := []SimConfig{{}}
+	expand := func(n int, set func(cfg *SimConfig, i int) string) {
+		if n == 0 {
+			return
+		}
+		next := make([]SimConfig, 0, len(configs)*n)
+		for _, cfg := range configs {
+			for i := 0; i < n; i++ {
+				c := cfg
+				part := set(&c, i)
+				if n > 1 {
+					c.Name = joinName(c.Name, part)
+				}
+				next = append(next, c)
+			}
+		}
+		configs = next
+	}
+	expand(len(s.Embedder), func(c *SimConfig, i int) string {
+		c.Embedder = s.Embedder[i]
+		return "embedder=" + s.Embedder[i]
+	})
+	expand(len(s.Chunker), func(c *SimConfig, i int) string {
+		c.Chunker = s.Chunker[i]
+		return "chunker=" + s.Chunker[i]
+	})
+	expand(len(s.ChunkSize), func(c *SimConfig, i int) string {
+		c.ChunkSize = s.ChunkSize[i]
+		return fmt.Sprintf("chunk_size=%d", s.ChunkSize[i])
+	})
+	expand(len(s.Overlap), func(c *SimConfig, i int) string {
+		c.Overlap = s.Overlap[i]
+		return fmt.Sprintf("overlap=%d", s.Overlap[i])
+	})
+	expand(len(s.TopK), func(c *SimConfig, i int) string {
+		c.TopK = s.TopK[i]
+		return fmt.Sprintf("top_k=%d", s.TopK[i])
+	})
+
+	valid := configs[:0]
+	for _, cfg := range configs {
+		if cfg.ChunkSize > 0 && cfg.Overlap >= cfg.ChunkSize {
+			continue
+		}
+		if cfg.Name == "" {
+			cfg.Name = "default"
+		}
+		valid = append(valid, cfg)
+	}
+	return valid
+}
+
+func joinName(name, part string) string {
+	if name == "" {
+		return part
+	}
+	return name + "," + part
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSearchSpace_Grid(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "space.yaml")
+
+	content := `space:
+  chunk_size: [64, 256]
+  overlap: [0, 64]
+  top_k: [3, 5]
+  chunker: [fixed]
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	space, err := LoadSearchSpace(path)
+	if err != nil {
+		t.Fatalf("LoadSearchSpace failed: %v", err)
+	}
+
+	grid := space.Grid()
+	// 2 sizes × 2 overlaps × 2 top_k, less the two with overlap 64 ≥ size 64
+	if len(grid) != 6 {
+		t.Fatalf("expected 6 configs, got %d", len(grid))
+	}
+	first := grid[0]
+	if first.Name != "chunk_size=64,overlap=0,top_k=3" {
+		t.Errorf("grid[0].Name = %q", first.Name)
+	}
+	if first.Chunker != "fixed" || first.ChunkSize != 64 || first.TopK != 3 {
+		t.Errorf("grid[0] = %+v", first)
+	}
+	// top_k varies fastest, so configs sharing a collection are adjacent
+	if grid[1].ChunkSize != 64 || grid[1].TopK != 5 {
+		t.Errorf("grid[1] = %+v, want chunk_size 64 with top_k 5", grid[1])
+	}
+	for _, cfg := range grid {
+		if cfg.Overlap >= cfg.ChunkSize {
+			t.Errorf("grid kept %s with overlap ≥ chunk_size", cfg.Name)
+		}
+	}
+}
+
+func TestLoadSearchSpace_JSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "space.json")
+
+	content := `{"space": {"embedder": ["ollama", "tei"]}}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	space, err := LoadSearchSpace(path)
+	if err != nil {
+		t.Fatalf("LoadSearchSpace failed: %v", err)
+	}
+	grid := space.Grid()
+	if len(grid) != 2 || grid[1].Embedder != "tei" || grid[1].Name != "embedder=tei" {
+		t.Errorf("grid = %+v", grid)
+	}
+}
+
+func TestSearchSpace_Validate(t *testing.T) {
+	tests := []struct {
+		name  string
+		space SearchSpace
+		want  string
+	}{
+		{"empty", SearchSpace{}, "empty"},
+		{"hybrid alpha", SearchSpace{TopK: []int{5}, HybridAlpha: []float64{0.5}}, "hybrid"},
+		{"zero chunk size", SearchSpace{ChunkSize: []int{0}}, "chunk_size"},
+		{"negative overlap", SearchSpace{Overlap: []int{-1}}, "overlap"},
+		{"zero top_k", SearchSpace{TopK: []int{0}}, "top_k"},
+		{"blank chunker", SearchSpace{Chunker: []string{" "}}, "chunker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.space.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want error mentioning %q", err, tt.want)
+			}
+		})
+	}
+
+	if err := (SearchSpace{TopK: []int{5}}).Validate(); err != nil {
+		t.Errorf("valid space rejected: %v", err)
+	}
+}
@@ -0,0 +1,356 @@
+// Package dedup detects exact and near-duplicate chunks.
+//
+// Texts are compared by fingerprint: a hash of the normalized text finds
+// exact duplicates, and MinHash signatures of word shingles (estimating
+// Jaccard similarity) or 64-bit SimHash fingerprints (Hamming distance)
+// find near duplicates. Candidates are looked up by locality-sensitive
+// hashing, so checking a text costs about the same however many have been
+// seen. VectorIndex compares embeddings by cosine similarity instead.
+package dedup
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+)
+
+// Method selects how near-duplicate texts are detected.
+type Method string
+
+const (
+	// MethodExact matches texts that are equal after normalizing case and whitespace.
+	MethodExact Method = "exact"
+
+	// MethodMinHash matches texts whose word shingles have a Jaccard
+	// similarity of at least the threshold, estimated by MinHash.
+	MethodMinHash Method = "minhash"
+
+	// MethodSimHash matches texts whose 64-bit SimHash fingerprints differ
+	// in at most MaxDistance bits.
+	MethodSimHash Method = "simhash"
+)
+
+// Defaults for near-duplicate detection.
+const (
+	// DefaultThreshold is the MinHash Jaccard similarity of near duplicates.
+	DefaultThreshold = 0.8
+
+	// DefaultMaxDistance is the SimHash Hamming distance of near duplicates.
+	// Fingerprints of unrelated texts differ in about 32 bits; one changed
+	// word in a 200-word chunk flips 3 or 4.
+	DefaultMaxDistance = 6
+
+	// DefaultShingleSize is the number of words per shingle.
+	DefaultShingleSize = 3
+)
+
+// MinHash signature layout: numBands bands of bandRows rows. A pair with
+// Jaccard similarity s shares at least one band with probability
+// 1-(1-s^4)^32: 0.999 at s=0.8, 0.27 at s=0.4.
+const (
+	numHashes = 128
+	bandRows  = 4
+	numBands  = numHashes / bandRows
+)
+
+// ParseMethod parses a detection method name.
+func ParseMethod(s string) (Method, error) {
+	switch Method(strings.ToLower(strings.TrimSpace(s))) {
+	case MethodExact:
+		return MethodExact, nil
+	case MethodMinHash:
+		return MethodMinHash, nil
+	case MethodSimHash:
+		return MethodSimHash, nil
+	default:
+		return "", fmt.Errorf("unsupported dedup method: %s (supported: exact, minhash, simhash)", s)
+	}
+}
+
+// Match describes the earlier text a duplicate matched.
+type Match struct {
+	ID string // ID the earlier text was added under
+
+	// Similarity is the estimated Jaccard similarity (MinHash), the
+	// fraction of equal fingerprint bits (SimHash) or the cosine similarity
+	// (VectorIndex); 1 for exact duplicates.
+	Similarity float64
+
+	// Exact reports whether the normalized texts are equal.
+	Exact bool
+}
+
+// Option configures a Detector.
+type Option func(*Detector)
+
+// WithThreshold sets the MinHash Jaccard similarity of near duplicates.
+func WithThreshold(t float64) Option {
+	return func(d *Detector) {
+		d.threshold = t
+	}
+}
+
+// WithMaxDistance sets the SimHash Hamming distance of near duplicates.
+func WithMaxDistance(bits int) Option {
+	return func(d *Detector) {
+		d.maxDistance = bits
+	}
+}
+
+// WithShingleSize sets the number of words per shingle.
+func WithShingleSize(n int) Option {
+	return func(d *Detector) {
+		d.shingleSize = n
+	}
+}
+
+// Detector finds texts that duplicate ones checked before. The first text
+// of a group is kept; later ones match it. A Detector is not safe for
+// concurrent use.
+type Detector struct {
+	method      Method
+	threshold   float64
+	maxDistance int
+	shingleSize int
+
+	exact   map[uint64]string
+	entries []entry
+	bands   []map[uint64][]int // LSH buckets: band key → entry indexes
+}
+
+// entry is the fingerprint of a kept text.
+type entry struct {
+	id      string
+	minhash []uint64
+	simhash uint64
+}
+
+// New returns a detector using method.
+func New(method Method, opts ...Option) (*Detector, error) {
+	d := &Detector{
+		method:      method,
+		threshold:   DefaultThreshold,
+		maxDistance: DefaultMaxDistance,
+		shingleSize: DefaultShingleSize,
+		exact:       make(map[uint64]string),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	switch {
+	case d.threshold <= 0 || d.threshold > 1:
+		return nil, fmt.Errorf("dedup threshold must be in (0, 1], got %g", d.threshold)
+	case d.maxDistance < 0 || d.maxDistance > 31:
+		return nil, fmt.Errorf("simhash distance must be between 0 and 31, got %d", d.maxDistance)
+	case d.shingleSize < 1:
+		return nil, fmt.Errorf("shingle size must be at least 1, got %d", d.shingleSize)
+	}
+	switch method {
+	case MethodExact:
+	case MethodMinHash:
+		d.bands = makeBands(numBands)
+	case MethodSimHash:
+		// With maxDistance+1 bands, two fingerprints within maxDistance bits
+		// agree on at least one band (pigeonhole)
+		d.bands = makeBands(d.maxDistance + 1)
+	default:
+		return nil, fmt.Errorf("unsupported dedup method: %s", method)
+	}
+	return d, nil
+}
+
+func makeBands(n int) []map[uint64][]int {
+	bands := make([]map[uint64][]int, n)
+	for i := range bands {
+		bands[i] = make(map[uint64][]int)
+	}
+	return bands
+}
+
+// Len returns the number of texts kept.
+func (d *Detector) Len() int {
+	return len(d.exact)
+}
+
+// Check reports whether text duplicates a text checked before, and which.
+// Otherwise text is kept under id for later checks.
+func (d *Detector) Check(id, text string) (Match, bool) {
+	words := strings.Fields(strings.ToLower(text))
+	key := hashString(strings.Join(words, " "))
+	if first, ok := d.exact[key]; ok {
+		return Match{ID: first, Similarity: 1, Exact: true}, true
+	}
+
+	e := entry{id: id}
+	var match Match
+	found := false
+	switch d.method {
+	case MethodMinHash:
+		e.minhash = minhash(shingles(words, d.shingleSize))
+		match, found = d.nearestMinHash(e.minhash)
+	case MethodSimHash:
+		e.simhash = simhash(shingles(words, d.shingleSize))
+		match, found = d.nearestSimHash(e.simhash)
+	}
+	if found {
+		return match, true
+	}
+
+	d.exact[key] = id
+	if d.bands != nil {
+		d.index(e)
+	}
+	return Match{}, false
+}
+
+// index adds a kept entry to the LSH buckets.
+func (d *Detector) index(e entry) {
+	n := len(d.entries)
+	d.entries = append(d.entries, e)
+	for b := range d.bands {
+		key := d.bandKey(e, b)
+		d.bands[b][key] = append(d.bands[b][key], n)
+	}
+}
+
+// candidates returns the kept entries sharing a band with e, once each.
+func (d *Detector) candidates(e entry) []int {
+	seen := make(map[int]bool)
+	var out []int
+	for b := range d.bands {
+		for _, n := range d.bands[b][d.bandKey(e, b)] {
+			if !seen[n] {
+				seen[n] = true
+				out = append(out, n)
+			}
+		}
+	}
+	return out
+}
+
+// bandKey returns the LSH bucket key of e in band b.
+func (d *Detector) bandKey(e entry, b int) uint64 {
+	if d.method == MethodMinHash {
+		var key uint64 = 14695981039346656037
+		for _, v := range e.minhash[b*bandRows : (b+1)*bandRows] {
+			key = mix64(key ^ v)
+		}
+		return key
+	}
+	lo, hi := simhashBand(b, len(d.bands))
+	return (e.simhash >> lo) & (1<<(hi-lo) - 1)
+}
+
+// simhashBand returns the bit range [lo, hi) of band b of n.
+func simhashBand(b, n int) (lo, hi uint) {
+	return uint(64 * b / n), uint(64 * (b + 1) / n)
+}
+
+// nearestMinHash returns the most similar kept entry at or above the threshold.
+func (d *Detector) nearestMinHash(sig []uint64) (Match, bool) {
+	best := Match{}
+	for _, n := range d.candidates(entry{minhash: sig}) {
+		equal := 0
+		for i, v := range d.entries[n].minhash {
+			if v == sig[i] {
+				equal++
+			}
+		}
+		if s := float64(equal) / numHashes; s >= d.threshold && s > best.Similarity {
+			best = Match{ID: d.entries[n].id, Similarity: s}
+		}
+	}
+	return best, best.ID != ""
+}
+
+// nearestSimHash returns the closest kept entry within maxDistance bits.
+func (d *Detector) nearestSimHash(fp uint64) (Match, bool) {
+	best, bestDist := Match{}, d.maxDistance+1
+	for _, n := range d.candidates(entry{simhash: fp}) {
+		if dist := bits.OnesCount64(fp ^ d.entries[n].simhash); dist < bestDist {
+			best, bestDist = Match{ID: d.entries[n].id, Similarity: 1 - float64(dist)/64}, dist
+		}
+	}
+	return best, best.ID != ""
+}
+
+// shingles returns the hashes of the overlapping n-word windows of words.
+// Texts shorter than n words yield a single shingle.
+func shingles(words []string, n int) []uint64 {
+	if len(words) <= n {
+		return []uint64{hashString(strings.Join(words, " "))}
+	}
+	out := make([]uint64, 0, len(words)-n+1)
+	for i := 0; i+n <= len(words); i++ {
+		out = append(out, hashString(strings.Join(words[i:i+n], " ")))
+	}
+	return out
+}
+
+// minhash returns the MinHash signature of a shingle set: for each of
+// numHashes hash functions, the minimum hash over the shingles.
+func minhash(shingles []uint64) []uint64 {
+	sig := make([]uint64, numHashes)
+	for i := range sig {
+		sig[i] = ^uint64(0)
+	}
+	for _, s := range shingles {
+		for i := range sig {
+			if h := mix64(s ^ seeds[i]); h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// simhash returns the 64-bit SimHash of a shingle set: each bit is set if
+// more shingle hashes have it set than not.
+func simhash(shingles []uint64) uint64 {
+	var votes [64]int
+	for _, s := range shingles {
+		h := mix64(s)
+		for b := 0; b < 64; b++ {
+			if h&(1<<b) != 0 {
+				votes[b]++
+			} else {
+				votes[b]--
+			}
+		}
+	}
+	var fp uint64
+	for b, v := range votes {
+		if v > 0 {
+			fp |= 1 << b
+		}
+	}
+	return fp
+}
+
+// seeds derive the MinHash hash functions from one mixing function.
+var seeds = func() [numHashes]uint64 {
+	var s [numHashes]uint64
+	x := uint64(0x9E3779B97F4A7C15)
+	for i := range s {
+		x = mix64(x + 0x9E3779B97F4A7C15)
+		s[i] = x
+	}
+	return s
+}()
+
+// mix64 is the SplitMix64 finalizer, a fast well-distributed 64-bit mix.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xBF58476D1CE4E5B9
+	x ^= x >> 27
+	x *= 0x94D049BB133111EB
+	x ^= x >> 31
+	return x
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
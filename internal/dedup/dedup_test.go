@@ -0,0 +1,144 @@
+package dedup
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// paragraph returns a distinct 200-word text for seed.
+func paragraph(seed int) string {
+	words := make([]string, 200)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", (seed*7919+i*104729)%100003)
+	}
+	return strings.Join(words, " ")
+}
+
+// edit replaces every nth word of text.
+func edit(text string, every int) string {
+	words := strings.Fields(text)
+	for i := every / 2; i < len(words); i += every {
+		words[i] = "changed"
+	}
+	return strings.Join(words, " ")
+}
+
+func TestParseMethod(t *testing.T) {
+	for _, s := range []string{"exact", "MinHash", " simhash "} {
+		if _, err := ParseMethod(s); err != nil {
+			t.Errorf("ParseMethod(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := ParseMethod("cosine"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestDetector_Exact(t *testing.T) {
+	d, err := New(MethodExact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, dup := d.Check("a", "Rotate API keys  every 90 days."); dup {
+		t.Fatal("first text reported as a duplicate")
+	}
+	m, dup := d.Check("b", "rotate api keys\nevery 90 days.")
+	if !dup || m.ID != "a" || !m.Exact || m.Similarity != 1 {
+		t.Errorf("case and whitespace variant: match = %+v, dup = %v", m, dup)
+	}
+	if _, dup := d.Check("c", "Rotate API keys every 30 days."); dup {
+		t.Error("exact method matched a different text")
+	}
+	if d.Len() != 2 {
+		t.Errorf("Len = %d, want 2 kept", d.Len())
+	}
+}
+
+func TestDetector_NearDuplicates(t *testing.T) {
+	for _, method := range []Method{MethodMinHash, MethodSimHash} {
+		t.Run(string(method), func(t *testing.T) {
+			d, err := New(method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 50; i++ {
+				if m, dup := d.Check(fmt.Sprint("doc", i), paragraph(i)); dup {
+					t.Fatalf("distinct paragraph %d matched %+v", i, m)
+				}
+			}
+
+			// One word changed: a near duplicate of doc7
+			m, dup := d.Check("copy", edit(paragraph(7), 200))
+			if !dup || m.ID != "doc7" || m.Exact {
+				t.Errorf("near duplicate: match = %+v, dup = %v", m, dup)
+			}
+			if m.Similarity <= 0.8 || m.Similarity >= 1 {
+				t.Errorf("similarity = %.3f, want high but below 1", m.Similarity)
+			}
+
+			// Every third word changed: a different text
+			if m, dup := d.Check("rewrite", edit(paragraph(9), 3)); dup {
+				t.Errorf("heavily edited text matched %+v", m)
+			}
+		})
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	if _, err := New(MethodMinHash, WithThreshold(1.5)); err == nil {
+		t.Error("expected an error for a threshold above 1")
+	}
+	if _, err := New(MethodSimHash, WithMaxDistance(40)); err == nil {
+		t.Error("expected an error for a distance above 31")
+	}
+	if _, err := New("cosine"); err == nil {
+		t.Error("expected an error for an unknown method")
+	}
+}
+
+func TestVectorIndex(t *testing.T) {
+	x, err := NewVectorIndex(0.95, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Check("a", []float32{1, 0, 0})
+	x.Check("b", []float32{0, 1, 0})
+
+	m, dup := x.Check("c", []float32{2, 0.1, 0}) // scale does not matter
+	if !dup || m.ID != "a" || m.Similarity < 0.99 {
+		t.Errorf("match = %+v, dup = %v", m, dup)
+	}
+	if _, dup := x.Check("d", []float32{1, 1, 0}); dup {
+		t.Error("vector at 45° matched")
+	}
+	if x.Len() != 3 {
+		t.Errorf("Len = %d, want 3 kept", x.Len())
+	}
+	if _, err := NewVectorIndex(0, 0); err == nil {
+		t.Error("expected an error for a zero threshold")
+	}
+}
+
+func TestVectorIndex_Limit(t *testing.T) {
+	x, err := NewVectorIndex(0.95, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Check("a", []float32{1, 0, 0})
+	x.Check("b", []float32{0, 1, 0})
+	if !x.Full() {
+		t.Fatal("index with 2 of 2 vectors is not full")
+	}
+
+	// A full index still matches, but keeps nothing new
+	if _, dup := x.Check("c", []float32{0, 0, 1}); dup || x.Len() != 2 {
+		t.Errorf("dup = %v, Len = %d; want no match and 2 kept", dup, x.Len())
+	}
+	if m, dup := x.Check("d", []float32{0, 0.01, 1}); dup {
+		t.Errorf("matched %+v, which was never kept", m)
+	}
+	if m, dup := x.Check("e", []float32{0, 1, 0}); !dup || m.ID != "b" {
+		t.Errorf("match = %+v, dup = %v; want b", m, dup)
+	}
+}
@@ -0,0 +1,88 @@
+package dedup
+
+import (
+	"fmt"
+	"math"
+)
+
+// VectorIndex finds vectors whose cosine similarity to a vector checked
+// before is at least a threshold. It compares against every kept vector,
+// so checking n vectors costs O(n²) and holds all kept vectors in memory,
+// up to an optional limit. A VectorIndex is not safe for concurrent use.
+type VectorIndex struct {
+	threshold float64
+	limit     int
+	ids       []string
+	vecs      [][]float32 // unit length
+}
+
+// NewVectorIndex returns an index matching vectors at or above threshold
+// that keeps at most limit vectors (0 = no limit).
+func NewVectorIndex(threshold float64, limit int) (*VectorIndex, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("cosine threshold must be in (0, 1], got %g", threshold)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("vector limit must be non-negative, got %d", limit)
+	}
+	return &VectorIndex{threshold: threshold, limit: limit}, nil
+}
+
+// Len returns the number of vectors kept.
+func (x *VectorIndex) Len() int {
+	return len(x.ids)
+}
+
+// Full reports whether the index holds its limit of vectors.
+func (x *VectorIndex) Full() bool {
+	return x.limit > 0 && len(x.ids) >= x.limit
+}
+
+// Check reports whether vec is at least threshold-similar to a vector
+// checked before, returning the most similar. Otherwise vec is kept under
+// id, unless the index is full.
+func (x *VectorIndex) Check(id string, vec []float32) (Match, bool) {
+	unit := normalize(vec)
+	best := Match{}
+	for i, kept := range x.vecs {
+		if s := dot(unit, kept); s >= x.threshold && s > best.Similarity {
+			best = Match{ID: x.ids[i], Similarity: s}
+		}
+	}
+	if best.ID != "" {
+		return best, true
+	}
+	if x.Full() {
+		return Match{}, false
+	}
+	x.ids = append(x.ids, id)
+	x.vecs = append(x.vecs, unit)
+	return Match{}, false
+}
+
+// normalize returns a unit-length copy of v (v itself if it is zero).
+func normalize(v []float32) []float32 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	if sum == 0 {
+		return v
+	}
+	inv := 1 / math.Sqrt(sum)
+	out := make([]float32, len(v))
+	for i, f := range v {
+		out[i] = float32(float64(f) * inv)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		if i < len(b) {
+			sum += float64(a[i]) * float64(b[i])
+		}
+	}
+	return sum
+}
@@ -0,0 +1,148 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/tokenizer"
+)
+
+// ErrPayloadTooLarge marks provider errors caused by a batch exceeding request limits.
+// Callers can retry with smaller batches.
+var ErrPayloadTooLarge = errors.New("batch payload too large")
+
+// DefaultBatchItems is used when an embedder does not advertise an item limit.
+const DefaultBatchItems = 64
+
+// batchTokenHeadroom leaves room for the approximate tokenizer undercounting.
+const batchTokenHeadroom = 0.85
+
+// payloadErrorHints are substrings providers use when rejecting an oversized
+// batch. Messages about a single input being too long do not match, since
+// splitting the batch cannot fix them.
+var payloadErrorHints = []string{
+	"batch size",
+	"max_client_batch_size",
+	"too many inputs",
+	"too many texts",
+	"per request",
+}
+
+// apiError builds a provider error for a non-OK response, marking it with
+// ErrPayloadTooLarge when the status or message indicates an oversized batch.
+func apiError(provider string, status int, msg string) error {
+	err := fmt.Errorf("%s error (status %d): %s", provider, status, msg)
+	if isPayloadTooLarge(status, msg) {
+		return fmt.Errorf("%w (%w)", err, ErrPayloadTooLarge)
+	}
+	return err
+}
+
+func isPayloadTooLarge(status int, msg string) bool {
+	if status == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	if status != http.StatusBadRequest && status != http.StatusUnprocessableEntity {
+		return false
+	}
+	msg = strings.ToLower(msg)
+	for _, hint := range payloadErrorHints {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+// Batch is a half-open range [Start, End) of inputs sent in one request.
+type Batch struct {
+	Start int
+	End   int
+}
+
+// PackBatches groups consecutive texts into batches that respect an item
+// limit and a total token budget. A text larger than the budget on its own
+// still gets a batch of one. maxItems <= 0 means DefaultBatchItems;
+// maxTokens <= 0 means no token budget.
+func PackBatches(texts []string, maxItems, maxTokens int) []Batch {
+	if maxItems <= 0 {
+		maxItems = DefaultBatchItems
+	}
+
+	var batches []Batch
+	start, tokens := 0, 0
+	for i, text := range texts {
+		n := 0
+		if maxTokens > 0 {
+			n = tokenizer.Count(text)
+		}
+		full := i-start >= maxItems || (maxTokens > 0 && tokens+n > maxTokens)
+		if full && i > start {
+			batches = append(batches, Batch{Start: start, End: i})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	if start < len(texts) {
+		batches = append(batches, Batch{Start: start, End: len(texts)})
+	}
+	return batches
+}
+
+// PlanBatches packs texts using the batch limits e advertises. Inputs are
+// counted at most at the per-input limit, since the token guard truncates
+// longer ones before they are sent.
+func PlanBatches(e Embedder, texts []string) []Batch {
+	info := Describe(e)
+	maxTokens := int(float64(info.MaxBatchTokens) * batchTokenHeadroom)
+	limit := inputLimit(e)
+	if info.MaxBatchTokens <= 0 || limit <= 0 {
+		return PackBatches(texts, info.MaxBatchItems, maxTokens)
+	}
+
+	capped := make([]string, len(texts))
+	for i, text := range texts {
+		capped[i] = tokenizer.Truncate(text, limit)
+	}
+	return PackBatches(capped, info.MaxBatchItems, maxTokens)
+}
+
+// inputLimit returns the per-input token limit enforced for e: that of a
+// token guard in its wrapper chain, which honors overrides, or else the
+// model's.
+func inputLimit(e Embedder) int {
+	for x := e; x != nil; {
+		if g, ok := x.(*TokenGuard); ok {
+			return g.MaxTokens()
+		}
+		w, ok := x.(Wrapper)
+		if !ok {
+			break
+		}
+		x = w.Unwrap()
+	}
+	return Describe(e).MaxInputTokens
+}
+
+// EmbedSplitting calls EmbedBatch and, if the provider rejects the request as
+// too large, halves the batch and retries each half recursively.
+func EmbedSplitting(ctx context.Context, e Embedder, texts []string) ([][]float32, error) {
+	vectors, err := e.EmbedBatch(ctx, texts)
+	if err == nil || len(texts) < 2 || !errors.Is(err, ErrPayloadTooLarge) {
+		return vectors, err
+	}
+
+	mid := len(texts) / 2
+	left, err := EmbedSplitting(ctx, e, texts[:mid])
+	if err != nil {
+		return nil, err
+	}
+	right, err := EmbedSplitting(ctx, e, texts[mid:])
+	if err != nil {
+		return nil, err
+	}
+	return append(left, right...), nil
+}
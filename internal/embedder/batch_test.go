@@ -0,0 +1,186 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// limitedEmbedder rejects batches larger than max with ErrPayloadTooLarge.
+type limitedEmbedder struct {
+	max   int
+	calls []int
+}
+
+func (l *limitedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	return []float32{1}, nil
+}
+
+func (l *limitedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	l.calls = append(l.calls, len(texts))
+	if len(texts) > l.max {
+		return nil, apiError("Test API", http.StatusRequestEntityTooLarge, "request too large")
+	}
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i] = []float32{float32(i)}
+	}
+	return out, nil
+}
+
+func (l *limitedEmbedder) Dim() int { return 1 }
+
+func TestPackBatches_ItemLimit(t *testing.T) {
+	texts := make([]string, 10)
+	batches := PackBatches(texts, 4, 0)
+
+	want := []Batch{{0, 4}, {4, 8}, {8, 10}}
+	if len(batches) != len(want) {
+		t.Fatalf("got %d batches, want %d: %v", len(batches), len(want), batches)
+	}
+	for i := range want {
+		if batches[i] != want[i] {
+			t.Errorf("batch %d = %v, want %v", i, batches[i], want[i])
+		}
+	}
+}
+
+func TestPackBatches_TokenBudget(t *testing.T) {
+	// Each text is 3 tokens; a budget of 7 fits two per batch
+	texts := []string{"a b c", "d e f", "g h i", "j k l", "m n o"}
+	batches := PackBatches(texts, 100, 7)
+
+	if len(batches) != 3 {
+		t.Fatalf("got %d batches, want 3: %v", len(batches), batches)
+	}
+	if batches[2] != (Batch{4, 5}) {
+		t.Errorf("last batch = %v, want {4 5}", batches[2])
+	}
+}
+
+func TestPackBatches_OversizedTextGetsOwnBatch(t *testing.T) {
+	texts := []string{"a", "one two three four five six", "b"}
+	batches := PackBatches(texts, 100, 3)
+
+	want := []Batch{{0, 1}, {1, 2}, {2, 3}}
+	for i := range want {
+		if i >= len(batches) || batches[i] != want[i] {
+			t.Fatalf("batches = %v, want %v", batches, want)
+		}
+	}
+}
+
+func TestPackBatches_Empty(t *testing.T) {
+	if batches := PackBatches(nil, 10, 10); len(batches) != 0 {
+		t.Errorf("expected no batches, got %v", batches)
+	}
+}
+
+func TestPlanBatches_UsesAdvertisedLimits(t *testing.T) {
+	inner := &recordingEmbedder{info: ModelInfo{MaxBatchItems: 3}}
+	batches := PlanBatches(NewTokenGuard(inner), make([]string, 7))
+
+	if len(batches) != 3 {
+		t.Errorf("got %d batches, want 3 with a 3-item limit: %v", len(batches), batches)
+	}
+}
+
+func TestPlanBatches_HonorsMaxTokensOverride(t *testing.T) {
+	inner := &recordingEmbedder{info: ModelInfo{MaxInputTokens: 1000, MaxBatchTokens: 100}}
+	texts := make([]string, 8)
+	for i := range texts {
+		texts[i] = strings.Repeat("word ", 200)
+	}
+
+	// At the model limit each long text fills a batch on its own
+	if got := PlanBatches(NewTokenGuard(inner), texts); len(got) != len(texts) {
+		t.Errorf("got %d batches at the model limit, want %d", len(got), len(texts))
+	}
+	// The guard truncates to 10 tokens, so several fit per batch
+	if got := PlanBatches(NewTokenGuard(inner, WithMaxTokens(10)), texts); len(got) > 2 {
+		t.Errorf("got %d batches with a 10-token override, want at most 2: %v", len(got), got)
+	}
+}
+
+func TestEmbedSplitting_RetriesHalves(t *testing.T) {
+	e := &limitedEmbedder{max: 2}
+	texts := []string{"a", "b", "c", "d", "e"}
+
+	vectors, err := EmbedSplitting(context.Background(), e, texts)
+	if err != nil {
+		t.Fatalf("EmbedSplitting failed: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		t.Fatalf("got %d vectors, want %d", len(vectors), len(texts))
+	}
+	// 5 -> (2, 3 -> (1, 2))
+	want := []int{5, 2, 3, 1, 2}
+	if len(e.calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", e.calls, want)
+	}
+	for i := range want {
+		if e.calls[i] != want[i] {
+			t.Errorf("calls = %v, want %v", e.calls, want)
+			break
+		}
+	}
+}
+
+func TestEmbedSplitting_SingleTextFails(t *testing.T) {
+	e := &limitedEmbedder{max: 0}
+
+	_, err := EmbedSplitting(context.Background(), e, []string{"a"})
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
+
+func TestAPIError_Classification(t *testing.T) {
+	tests := []struct {
+		status int
+		msg    string
+		want   bool
+	}{
+		{413, "", true},
+		{400, "Batch size exceeds the limit of 96", true},
+		{400, "Too many inputs. The max number of inputs is 2048.", true},
+		{400, "Requested 400000 tokens, max 300000 tokens per request", true},
+		{400, "invalid model", false},
+		{400, "input exceeds the context length", false},
+		{400, "This model's maximum context length is 8192 tokens", false},
+		{413, "text too long", true},
+		{422, "Input validation error: inputs must have less than 512 tokens. Given: 600", false},
+		{401, "too many keys", false},
+		{500, "internal error", false},
+	}
+
+	for _, tt := range tests {
+		err := apiError("Test API", tt.status, tt.msg)
+		if got := errors.Is(err, ErrPayloadTooLarge); got != tt.want {
+			t.Errorf("apiError(%d, %q) payload-too-large = %v, want %v", tt.status, tt.msg, got, tt.want)
+		}
+	}
+}
+
+func TestOpenAIEmbedder_PayloadTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "Requested 400000 tokens, max 300000 tokens per request"}}`))
+	}))
+	defer server.Close()
+
+	original := os.Getenv("OPENAI_API_KEY")
+	defer os.Setenv("OPENAI_API_KEY", original)
+	os.Setenv("OPENAI_API_KEY", "test-key")
+
+	e := NewOpenAIEmbedder(WithOpenAIURL(server.URL))
+	_, err := e.EmbedBatch(context.Background(), []string{"a", "b"})
+
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("expected ErrPayloadTooLarge, got %v", err)
+	}
+}
@@ -80,6 +80,11 @@ func (e *CohereEmbedder) Dim() int {
 	return e.dim
 }
 
+// Info returns the model's input limit and pricing.
+func (e *CohereEmbedder) Info() ModelInfo {
+	return lookupModel("cohere", e.model)
+}
+
 // Embed generates an embedding for a single text.
 func (e *CohereEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	embeddings, err := e.EmbedBatch(ctx, []string{text})
@@ -126,7 +131,7 @@ func (e *CohereEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]fl
 	if resp.StatusCode != http.StatusOK {
 		var errResp cohereErrorResponse
 		_ = json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("Cohere API error (status %d): %s", resp.StatusCode, errResp.Message)
+		return nil, apiError("Cohere API", resp.StatusCode, errResp.Message)
 	}
 
 	var embResp cohereEmbedResponse
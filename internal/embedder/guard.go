@@ -0,0 +1,225 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/metawake/ragtune/internal/tokenizer"
+)
+
+// ErrInputTooLong is returned under PolicyError when an input exceeds the token limit.
+var ErrInputTooLong = errors.New("input exceeds model token limit")
+
+// TokenPolicy controls what happens to inputs longer than the model's limit.
+type TokenPolicy string
+
+const (
+	// PolicyTruncate cuts inputs to the token limit (default).
+	PolicyTruncate TokenPolicy = "truncate"
+
+	// PolicySplit embeds each token-limited piece and averages the vectors.
+	PolicySplit TokenPolicy = "split"
+
+	// PolicyError rejects over-long inputs with ErrInputTooLong.
+	PolicyError TokenPolicy = "error"
+)
+
+// ParseTokenPolicy parses a policy name. An empty string means truncate.
+func ParseTokenPolicy(s string) (TokenPolicy, error) {
+	switch TokenPolicy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", PolicyTruncate:
+		return PolicyTruncate, nil
+	case PolicySplit:
+		return PolicySplit, nil
+	case PolicyError:
+		return PolicyError, nil
+	default:
+		return "", fmt.Errorf("unsupported token policy: %s (supported: truncate, split, error)", s)
+	}
+}
+
+// TokenUsage summarizes the tokens a TokenGuard has sent to the model.
+type TokenUsage struct {
+	Tokens    int64 // Tokens actually embedded (after truncation)
+	Inputs    int64 // Texts received
+	Truncated int64 // Texts cut to the limit
+	Split     int64 // Texts embedded as multiple pieces
+}
+
+// Compile-time interface compliance check.
+var _ Embedder = (*TokenGuard)(nil)
+
+// TokenGuard wraps an Embedder, enforces the model's input token limit
+// client-side, and counts tokens embedded. Counting uses the approximate
+// tokenizer, so totals are estimates rather than billed amounts.
+type TokenGuard struct {
+	inner     Embedder
+	maxTokens int
+	policy    TokenPolicy
+
+	tokens    atomic.Int64
+	inputs    atomic.Int64
+	truncated atomic.Int64
+	split     atomic.Int64
+}
+
+// GuardOption configures the token guard.
+type GuardOption func(*TokenGuard)
+
+// WithMaxTokens overrides the model's input token limit. Values <= 0 keep the model default.
+func WithMaxTokens(n int) GuardOption {
+	return func(g *TokenGuard) {
+		if n > 0 {
+			g.maxTokens = n
+		}
+	}
+}
+
+// WithTokenPolicy sets how over-long inputs are handled.
+func WithTokenPolicy(p TokenPolicy) GuardOption {
+	return func(g *TokenGuard) {
+		g.policy = p
+	}
+}
+
+// NewTokenGuard wraps inner. The limit defaults to the model's MaxInputTokens;
+// if that is unknown and not overridden, inputs pass through and are only counted.
+func NewTokenGuard(inner Embedder, opts ...GuardOption) *TokenGuard {
+	g := &TokenGuard{
+		inner:     inner,
+		maxTokens: Describe(inner).MaxInputTokens,
+		policy:    PolicyTruncate,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Unwrap returns the wrapped embedder.
+func (g *TokenGuard) Unwrap() Embedder {
+	return g.inner
+}
+
+// Dim returns the embedding dimension.
+func (g *TokenGuard) Dim() int {
+	return g.inner.Dim()
+}
+
+// MaxTokens returns the enforced per-input limit (0 = none).
+func (g *TokenGuard) MaxTokens() int {
+	return g.maxTokens
+}
+
+// Usage returns a snapshot of token counters.
+func (g *TokenGuard) Usage() TokenUsage {
+	return TokenUsage{
+		Tokens:    g.tokens.Load(),
+		Inputs:    g.inputs.Load(),
+		Truncated: g.truncated.Load(),
+		Split:     g.split.Load(),
+	}
+}
+
+// Embed generates an embedding for a single text.
+func (g *TokenGuard) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := g.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embeddings returned")
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch applies the token policy to each text and embeds the result.
+func (g *TokenGuard) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	// pieces[i] are the texts sent for input owner[i]
+	var pieces []string
+	var owner []int
+	var tokens int64
+
+	for i, text := range texts {
+		n := tokenizer.Count(text)
+		if g.maxTokens <= 0 || n <= g.maxTokens {
+			pieces = append(pieces, text)
+			owner = append(owner, i)
+			tokens += int64(n)
+			continue
+		}
+
+		switch g.policy {
+		case PolicyError:
+			return nil, fmt.Errorf("text %d has ~%d tokens (limit %d): %w", i, n, g.maxTokens, ErrInputTooLong)
+		case PolicySplit:
+			for _, p := range tokenizer.Split(text, g.maxTokens) {
+				pieces = append(pieces, p)
+				owner = append(owner, i)
+			}
+			tokens += int64(n)
+			g.split.Add(1)
+		default:
+			pieces = append(pieces, tokenizer.Truncate(text, g.maxTokens))
+			owner = append(owner, i)
+			tokens += int64(g.maxTokens)
+			g.truncated.Add(1)
+		}
+	}
+
+	vectors, err := g.inner.EmbedBatch(ctx, pieces)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(pieces) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d inputs", len(vectors), len(pieces))
+	}
+
+	g.inputs.Add(int64(len(texts)))
+	g.tokens.Add(tokens)
+
+	if len(pieces) == len(texts) {
+		return vectors, nil
+	}
+	return mergePieces(vectors, owner, len(texts)), nil
+}
+
+// mergePieces averages the vectors of split inputs and renormalizes them.
+func mergePieces(vectors [][]float32, owner []int, n int) [][]float32 {
+	out := make([][]float32, n)
+	counts := make([]int, n)
+	for j, vec := range vectors {
+		i := owner[j]
+		if out[i] == nil {
+			out[i] = make([]float32, len(vec))
+		}
+		for d := range vec {
+			out[i][d] += vec[d]
+		}
+		counts[i]++
+	}
+	for i := range out {
+		if counts[i] > 1 {
+			normalize(out[i])
+		}
+	}
+	return out
+}
+
+// UsageOf returns the token usage of the first TokenGuard in e's wrapper chain.
+func UsageOf(e Embedder) (TokenUsage, bool) {
+	for e != nil {
+		if g, ok := e.(*TokenGuard); ok {
+			return g.Usage(), true
+		}
+		w, ok := e.(Wrapper)
+		if !ok {
+			break
+		}
+		e = w.Unwrap()
+	}
+	return TokenUsage{}, false
+}
@@ -0,0 +1,159 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/tokenizer"
+)
+
+// recordingEmbedder records the texts it receives and returns one-hot vectors
+// keyed by call order, so averaged vectors are easy to check.
+type recordingEmbedder struct {
+	received []string
+	info     ModelInfo
+}
+
+func (r *recordingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := r.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func (r *recordingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, 4)
+		vec[len(r.received)%4] = 1
+		r.received = append(r.received, text)
+		out[i] = vec
+	}
+	return out, nil
+}
+
+func (r *recordingEmbedder) Dim() int { return 4 }
+
+func (r *recordingEmbedder) Info() ModelInfo { return r.info }
+
+func TestParseTokenPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    TokenPolicy
+		wantErr bool
+	}{
+		{"", PolicyTruncate, false},
+		{"truncate", PolicyTruncate, false},
+		{"SPLIT", PolicySplit, false},
+		{"error", PolicyError, false},
+		{"drop", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTokenPolicy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTokenPolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTokenPolicy(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestDescribe_LooksThroughWrappers(t *testing.T) {
+	inner := NewOpenAIEmbedder()
+	wrapped := NewTokenGuard(NewTransformEmbedder(inner, WithDims(256)))
+
+	info := Describe(wrapped)
+	if info.Model != "text-embedding-3-small" {
+		t.Errorf("Model = %q, want text-embedding-3-small", info.Model)
+	}
+	if info.MaxInputTokens != 8191 {
+		t.Errorf("MaxInputTokens = %d, want 8191", info.MaxInputTokens)
+	}
+	if got := info.Cost(1_000_000); math.Abs(got-0.02) > 1e-9 {
+		t.Errorf("Cost(1M) = %v, want 0.02", got)
+	}
+}
+
+func TestTokenGuard_Truncate(t *testing.T) {
+	inner := &recordingEmbedder{info: ModelInfo{MaxInputTokens: 5}}
+	g := NewTokenGuard(inner)
+
+	long := strings.Repeat("word ", 20)
+	if _, err := g.EmbedBatch(context.Background(), []string{"short", long}); err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+
+	if n := tokenizer.Count(inner.received[1]); n != 5 {
+		t.Errorf("truncated input has %d tokens, want 5", n)
+	}
+	usage := g.Usage()
+	if usage.Inputs != 2 || usage.Truncated != 1 || usage.Tokens != 6 {
+		t.Errorf("usage = %+v, want 2 inputs, 1 truncated, 6 tokens", usage)
+	}
+}
+
+func TestTokenGuard_Split(t *testing.T) {
+	inner := &recordingEmbedder{}
+	g := NewTokenGuard(inner, WithMaxTokens(2), WithTokenPolicy(PolicySplit))
+
+	vectors, err := g.EmbedBatch(context.Background(), []string{"a b c d", "x"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+
+	if len(vectors) != 2 {
+		t.Fatalf("expected 2 vectors, got %d", len(vectors))
+	}
+	if len(inner.received) != 3 {
+		t.Fatalf("expected 3 pieces sent, got %d: %q", len(inner.received), inner.received)
+	}
+	// Two one-hot pieces average to a unit vector with two equal components
+	want := float32(1 / math.Sqrt2)
+	if math.Abs(float64(vectors[0][0]-want)) > 1e-6 || math.Abs(float64(vectors[0][1]-want)) > 1e-6 {
+		t.Errorf("merged vector = %v, want [%v %v 0 0]", vectors[0], want, want)
+	}
+	if vectors[1][2] != 1 {
+		t.Errorf("unsplit vector = %v, want one-hot at index 2", vectors[1])
+	}
+	if got := g.Usage().Split; got != 1 {
+		t.Errorf("Split = %d, want 1", got)
+	}
+}
+
+func TestTokenGuard_Error(t *testing.T) {
+	inner := &recordingEmbedder{}
+	g := NewTokenGuard(inner, WithMaxTokens(2), WithTokenPolicy(PolicyError))
+
+	_, err := g.EmbedBatch(context.Background(), []string{"ok", "far too many tokens"})
+	if !errors.Is(err, ErrInputTooLong) {
+		t.Fatalf("expected ErrInputTooLong, got %v", err)
+	}
+	if len(inner.received) != 0 {
+		t.Errorf("expected nothing sent to the model, got %q", inner.received)
+	}
+}
+
+func TestTokenGuard_UnknownLimitPassesThrough(t *testing.T) {
+	inner := &recordingEmbedder{}
+	g := NewTokenGuard(inner)
+
+	long := strings.Repeat("word ", 100)
+	if _, err := g.Embed(context.Background(), long); err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if inner.received[0] != long {
+		t.Error("input modified despite unknown limit")
+	}
+
+	usage, ok := UsageOf(NewTransformEmbedder(g))
+	if !ok || usage.Tokens != 100 {
+		t.Errorf("UsageOf = %+v, %v; want 100 tokens", usage, ok)
+	}
+}
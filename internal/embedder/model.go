@@ -0,0 +1,138 @@
+package embedder
+
+// ModelInfo describes the model behind an embedder: its input limit and list price.
+type ModelInfo struct {
+	Provider string
+	Model    string
+
+	// MaxInputTokens is the per-input token limit (0 = unknown).
+	MaxInputTokens int
+
+	// PricePerMTokens is the list price in USD per million input tokens (0 = free/local).
+	PricePerMTokens float64
+
+	// MaxBatchItems is the most inputs accepted per request (0 = unknown).
+	MaxBatchItems int
+
+	// MaxBatchTokens is the most total tokens accepted per request (0 = unlimited/unknown).
+	MaxBatchTokens int
+}
+
+// Cost returns the estimated USD cost of embedding the given number of tokens.
+func (m ModelInfo) Cost(tokens int64) float64 {
+	return float64(tokens) / 1e6 * m.PricePerMTokens
+}
+
+// Describer is implemented by embedders that know their model's limits and pricing.
+type Describer interface {
+	Info() ModelInfo
+}
+
+// Wrapper is implemented by embedders that decorate another Embedder.
+type Wrapper interface {
+	Unwrap() Embedder
+}
+
+// Describe returns the ModelInfo of e, looking through wrappers.
+// Returns the zero value if no embedder in the chain describes itself.
+func Describe(e Embedder) ModelInfo {
+	for e != nil {
+		if d, ok := e.(Describer); ok {
+			return d.Info()
+		}
+		w, ok := e.(Wrapper)
+		if !ok {
+			break
+		}
+		e = w.Unwrap()
+	}
+	return ModelInfo{}
+}
+
+// modelMaxTokens lists per-input token limits of known models.
+var modelMaxTokens = map[string]int{
+	// OpenAI
+	"text-embedding-3-small": 8191,
+	"text-embedding-3-large": 8191,
+	"text-embedding-ada-002": 8191,
+	// Cohere
+	"embed-english-v3.0":            512,
+	"embed-multilingual-v3.0":       512,
+	"embed-english-light-v3.0":      512,
+	"embed-multilingual-light-v3.0": 512,
+	// Voyage
+	"voyage-2":                4000,
+	"voyage-lite-02-instruct": 4000,
+	"voyage-large-2":          16000,
+	"voyage-code-2":           16000,
+	"voyage-law-2":            16000,
+	"voyage-finance-2":        32000,
+	// Ollama
+	"nomic-embed-text":  8192,
+	"mxbai-embed-large": 512,
+	"all-minilm":        256,
+	// TEI (Hugging Face model IDs)
+	"BAAI/bge-small-en-v1.5":                  512,
+	"BAAI/bge-small-en":                       512,
+	"BAAI/bge-base-en-v1.5":                   512,
+	"BAAI/bge-base-en":                        512,
+	"BAAI/bge-large-en-v1.5":                  512,
+	"BAAI/bge-large-en":                       512,
+	"sentence-transformers/all-MiniLM-L6-v2":  256,
+	"sentence-transformers/all-mpnet-base-v2": 384,
+	"nomic-ai/nomic-embed-text-v1.5":          8192,
+	"nomic-ai/nomic-embed-text-v1":            8192,
+	"thenlper/gte-small":                      512,
+	"thenlper/gte-base":                       512,
+	"thenlper/gte-large":                      512,
+	"Alibaba-NLP/gte-Qwen2-1.5B-instruct":     32768,
+	"intfloat/e5-small-v2":                    512,
+	"intfloat/e5-base-v2":                     512,
+	"intfloat/e5-large-v2":                    512,
+}
+
+// modelPrices lists USD per million input tokens for hosted models.
+// List prices as published by each provider; verify before budgeting.
+var modelPrices = map[string]float64{
+	"text-embedding-3-small":        0.02,
+	"text-embedding-3-large":        0.13,
+	"text-embedding-ada-002":        0.10,
+	"embed-english-v3.0":            0.10,
+	"embed-multilingual-v3.0":       0.10,
+	"embed-english-light-v3.0":      0.10,
+	"embed-multilingual-light-v3.0": 0.10,
+	"voyage-2":                      0.10,
+	"voyage-lite-02-instruct":       0.10,
+	"voyage-large-2":                0.12,
+	"voyage-code-2":                 0.12,
+	"voyage-law-2":                  0.12,
+	"voyage-finance-2":              0.12,
+}
+
+// batchLimits are per-request limits of a provider's embedding endpoint.
+type batchLimits struct {
+	items  int
+	tokens int
+}
+
+// providerBatchLimits lists documented per-request limits. Ollama is absent:
+// it embeds one text per request, so the batch size only bounds concurrency.
+var providerBatchLimits = map[string]batchLimits{
+	"openai": {items: 2048, tokens: 300000},
+	"cohere": {items: 96},
+	"voyage": {items: 128, tokens: 120000},
+	"tei":    {items: 32}, // TEI's default --max-client-batch-size
+}
+
+// lookupModel builds a ModelInfo from the known-model tables.
+func lookupModel(provider, model string) ModelInfo {
+	limits := providerBatchLimits[provider]
+	return ModelInfo{
+		Provider:        provider,
+		Model:           model,
+		MaxInputTokens:  modelMaxTokens[model],
+		PricePerMTokens: modelPrices[model],
+		MaxBatchItems:   limits.items,
+		MaxBatchTokens:  limits.tokens,
+	}
+}
@@ -84,6 +84,11 @@ func (e *OllamaEmbedder) Dim() int {
 	return e.dim
 }
 
+// Info returns the model's input limit and pricing.
+func (e *OllamaEmbedder) Info() ModelInfo {
+	return lookupModel("ollama", e.model)
+}
+
 // Embed generates an embedding for a single text.
 func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	reqBody := ollamaEmbedRequest{
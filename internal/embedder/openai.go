@@ -59,6 +59,11 @@ func (e *OpenAIEmbedder) Dim() int {
 	return e.dim
 }
 
+// Info returns the model's input limit and pricing.
+func (e *OpenAIEmbedder) Info() ModelInfo {
+	return lookupModel("openai", e.model)
+}
+
 // Embed generates an embedding for a single text.
 func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	embeddings, err := e.EmbedBatch(ctx, []string{text})
@@ -104,7 +109,7 @@ func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]fl
 	if resp.StatusCode != http.StatusOK {
 		var errResp openaiErrorResponse
 		_ = json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, errResp.Error.Message)
+		return nil, apiError("API", resp.StatusCode, errResp.Error.Message)
 	}
 
 	var embResp openaiEmbeddingResponse
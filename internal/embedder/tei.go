@@ -113,6 +113,11 @@ func (e *TEIEmbedder) Dim() int {
 	return e.dim
 }
 
+// Info returns the model's input limit and pricing.
+func (e *TEIEmbedder) Info() ModelInfo {
+	return lookupModel("tei", e.model)
+}
+
 // Embed generates an embedding for a single text.
 func (e *TEIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	embeddings, err := e.EmbedBatch(ctx, []string{text})
@@ -155,7 +160,7 @@ func (e *TEIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("TEI API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, apiError("TEI API", resp.StatusCode, string(body))
 	}
 
 	// TEI returns array of arrays: [[0.1, 0.2, ...], [0.3, 0.4, ...]]
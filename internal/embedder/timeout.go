@@ -0,0 +1,53 @@
+package embedder
+
+import (
+	"context"
+	"time"
+)
+
+// Compile-time interface compliance checks.
+var (
+	_ Embedder = (*TimeoutEmbedder)(nil)
+	_ Wrapper  = (*TimeoutEmbedder)(nil)
+)
+
+// TimeoutEmbedder wraps another Embedder and bounds each call with a
+// timeout, so a hung provider fails the call instead of blocking forever.
+// The providers' own HTTP client timeouts still apply.
+type TimeoutEmbedder struct {
+	inner   Embedder
+	timeout time.Duration
+}
+
+// WithTimeout returns inner with each Embed and EmbedBatch call limited to
+// timeout. A timeout of zero or less returns inner unchanged.
+func WithTimeout(inner Embedder, timeout time.Duration) Embedder {
+	if timeout <= 0 {
+		return inner
+	}
+	return &TimeoutEmbedder{inner: inner, timeout: timeout}
+}
+
+// Unwrap returns the wrapped embedder.
+func (e *TimeoutEmbedder) Unwrap() Embedder {
+	return e.inner
+}
+
+// Dim returns the embedding dimension.
+func (e *TimeoutEmbedder) Dim() int {
+	return e.inner.Dim()
+}
+
+// Embed generates an embedding for a single text.
+func (e *TimeoutEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	return e.inner.Embed(ctx, text)
+}
+
+// EmbedBatch generates embeddings for multiple texts.
+func (e *TimeoutEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	return e.inner.EmbedBatch(ctx, texts)
+}
@@ -0,0 +1,47 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// hangingEmbedder blocks until its context is done, like a stalled provider.
+type hangingEmbedder struct {
+	fixedEmbedder
+}
+
+func (h *hangingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (h *hangingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestWithTimeout(t *testing.T) {
+	inner := &hangingEmbedder{fixedEmbedder{vec: []float32{1, 0}}}
+	if WithTimeout(inner, 0) != Embedder(inner) {
+		t.Error("zero timeout should return the embedder unchanged")
+	}
+
+	emb := WithTimeout(inner, 10*time.Millisecond)
+	if _, err := emb.Embed(context.Background(), "a"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Embed err = %v, want deadline exceeded", err)
+	}
+	if _, err := emb.EmbedBatch(context.Background(), []string{"a", "b"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EmbedBatch err = %v, want deadline exceeded", err)
+	}
+	if emb.Dim() != 2 {
+		t.Errorf("Dim = %d, want 2", emb.Dim())
+	}
+
+	// The wrapper stays transparent to Describe
+	guard := NewTokenGuard(WithTimeout(NewOpenAIEmbedder(), time.Second))
+	if Describe(guard).Provider != "openai" {
+		t.Error("Describe should look through the timeout wrapper")
+	}
+}
@@ -0,0 +1,209 @@
+package embedder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Quantization selects how vectors are compressed before storage.
+type Quantization string
+
+const (
+	// QuantizationNone keeps full float32 precision.
+	QuantizationNone Quantization = "none"
+
+	// QuantizationInt8 maps each component to one of 255 levels (scalar quantization).
+	QuantizationInt8 Quantization = "int8"
+
+	// QuantizationBinary keeps only the sign of each component (1 bit per dimension).
+	QuantizationBinary Quantization = "binary"
+)
+
+// ParseQuantization parses a quantization name. An empty string means none.
+func ParseQuantization(s string) (Quantization, error) {
+	switch Quantization(strings.ToLower(strings.TrimSpace(s))) {
+	case "", QuantizationNone, "float32":
+		return QuantizationNone, nil
+	case QuantizationInt8:
+		return QuantizationInt8, nil
+	case QuantizationBinary:
+		return QuantizationBinary, nil
+	default:
+		return "", fmt.Errorf("unsupported quantization: %s (supported: none, int8, binary)", s)
+	}
+}
+
+// BytesPerVector returns the storage footprint of a dim-dimensional vector
+// under this quantization, ignoring per-vector index overhead.
+func (q Quantization) BytesPerVector(dim int) int {
+	switch q {
+	case QuantizationInt8:
+		return dim
+	case QuantizationBinary:
+		return (dim + 7) / 8
+	default:
+		return dim * 4
+	}
+}
+
+// Compile-time interface compliance check.
+var _ Embedder = (*TransformEmbedder)(nil)
+
+// TransformEmbedder wraps another Embedder and post-processes its vectors.
+// It truncates to the first N dimensions and renormalizes (Matryoshka
+// truncation), then optionally applies int8 or binary quantization.
+//
+// Quantized vectors are returned dequantized as float32 so they can be stored
+// in any backend; the precision loss is what gets measured, while the
+// theoretical storage footprint is reported via Quantization.BytesPerVector.
+type TransformEmbedder struct {
+	inner        Embedder
+	dims         int
+	quantization Quantization
+}
+
+// TransformOption configures the transform embedder.
+type TransformOption func(*TransformEmbedder)
+
+// WithDims truncates vectors to the first n dimensions.
+// Values <= 0 or >= the inner dimension leave vectors at full length.
+func WithDims(n int) TransformOption {
+	return func(e *TransformEmbedder) {
+		e.dims = n
+	}
+}
+
+// WithQuantization sets the quantization applied after truncation.
+func WithQuantization(q Quantization) TransformOption {
+	return func(e *TransformEmbedder) {
+		e.quantization = q
+	}
+}
+
+// NewTransformEmbedder wraps inner with truncation and quantization.
+func NewTransformEmbedder(inner Embedder, opts ...TransformOption) *TransformEmbedder {
+	e := &TransformEmbedder{
+		inner:        inner,
+		quantization: QuantizationNone,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Dim returns the embedding dimension after truncation.
+func (e *TransformEmbedder) Dim() int {
+	innerDim := e.inner.Dim()
+	if e.dims > 0 && e.dims < innerDim {
+		return e.dims
+	}
+	return innerDim
+}
+
+// Unwrap returns the wrapped embedder.
+func (e *TransformEmbedder) Unwrap() Embedder {
+	return e.inner
+}
+
+// Quantization returns the configured quantization.
+func (e *TransformEmbedder) Quantization() Quantization {
+	return e.quantization
+}
+
+// Embed generates a transformed embedding for a single text.
+func (e *TransformEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec, err := e.inner.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	return e.Transform(vec), nil
+}
+
+// EmbedBatch generates transformed embeddings for multiple texts.
+func (e *TransformEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors, err := e.inner.EmbedBatch(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([][]float32, len(vectors))
+	for i, vec := range vectors {
+		out[i] = e.Transform(vec)
+	}
+	return out, nil
+}
+
+// Transform applies truncation, renormalization and quantization to a vector.
+// The input slice is not modified.
+func (e *TransformEmbedder) Transform(vec []float32) []float32 {
+	n := len(vec)
+	if e.dims > 0 && e.dims < n {
+		n = e.dims
+	}
+	out := make([]float32, n)
+	copy(out, vec[:n])
+	normalize(out)
+
+	switch e.quantization {
+	case QuantizationInt8:
+		quantizeInt8(out)
+	case QuantizationBinary:
+		binarize(out)
+	}
+	return out
+}
+
+// normalize scales v to unit L2 norm in place. Zero vectors are left unchanged.
+func normalize(v []float32) {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if sum == 0 {
+		return
+	}
+	inv := float32(1 / math.Sqrt(sum))
+	for i := range v {
+		v[i] *= inv
+	}
+}
+
+// quantizeInt8 rounds each component to one of 255 symmetric levels scaled by
+// the vector's max absolute value, then maps back to float32.
+// Per-vector scaling keeps full int8 resolution regardless of dimension.
+func quantizeInt8(v []float32) {
+	var maxAbs float32
+	for _, x := range v {
+		if x < 0 {
+			x = -x
+		}
+		if x > maxAbs {
+			maxAbs = x
+		}
+	}
+	if maxAbs == 0 {
+		return
+	}
+	scale := maxAbs / 127
+	for i, x := range v {
+		v[i] = float32(math.Round(float64(x/scale))) * scale
+	}
+}
+
+// binarize replaces each component with ±1/sqrt(d) according to its sign.
+// Cosine similarity between such vectors is a linear function of Hamming distance.
+func binarize(v []float32) {
+	if len(v) == 0 {
+		return
+	}
+	unit := float32(1 / math.Sqrt(float64(len(v))))
+	for i, x := range v {
+		if x > 0 {
+			v[i] = unit
+		} else {
+			v[i] = -unit
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package embedder
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// fixedEmbedder returns the same vector for every input.
+type fixedEmbedder struct {
+	vec []float32
+}
+
+func (f *fixedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	out := make([]float32, len(f.vec))
+	copy(out, f.vec)
+	return out, nil
+}
+
+func (f *fixedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i := range texts {
+		out[i], _ = f.Embed(ctx, texts[i])
+	}
+	return out, nil
+}
+
+func (f *fixedEmbedder) Dim() int {
+	return len(f.vec)
+}
+
+func l2Norm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+func TestParseQuantization(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Quantization
+		wantErr bool
+	}{
+		{"", QuantizationNone, false},
+		{"none", QuantizationNone, false},
+		{"float32", QuantizationNone, false},
+		{"INT8", QuantizationInt8, false},
+		{"binary", QuantizationBinary, false},
+		{"int4", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuantization(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseQuantization(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuantization(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestQuantization_BytesPerVector(t *testing.T) {
+	tests := []struct {
+		q    Quantization
+		dim  int
+		want int
+	}{
+		{QuantizationNone, 1536, 6144},
+		{QuantizationInt8, 1536, 1536},
+		{QuantizationBinary, 1536, 192},
+		{QuantizationBinary, 100, 13}, // rounds up to whole bytes
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.BytesPerVector(tt.dim); got != tt.want {
+			t.Errorf("%s.BytesPerVector(%d) = %d, want %d", tt.q, tt.dim, got, tt.want)
+		}
+	}
+}
+
+func TestTransformEmbedder_Dim(t *testing.T) {
+	inner := &fixedEmbedder{vec: make([]float32, 8)}
+
+	if got := NewTransformEmbedder(inner).Dim(); got != 8 {
+		t.Errorf("Dim() without truncation = %d, want 8", got)
+	}
+	if got := NewTransformEmbedder(inner, WithDims(4)).Dim(); got != 4 {
+		t.Errorf("Dim() with dims=4 = %d, want 4", got)
+	}
+	if got := NewTransformEmbedder(inner, WithDims(16)).Dim(); got != 8 {
+		t.Errorf("Dim() with dims larger than inner = %d, want 8", got)
+	}
+}
+
+func TestTransformEmbedder_TruncateRenormalizes(t *testing.T) {
+	inner := &fixedEmbedder{vec: []float32{3, 4, 100, 100}}
+	e := NewTransformEmbedder(inner, WithDims(2))
+
+	vec, err := e.Embed(context.Background(), "text")
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if len(vec) != 2 {
+		t.Fatalf("expected 2 dims, got %d", len(vec))
+	}
+	if math.Abs(float64(vec[0])-0.6) > 1e-6 || math.Abs(float64(vec[1])-0.8) > 1e-6 {
+		t.Errorf("truncated vector = %v, want [0.6 0.8]", vec)
+	}
+}
+
+func TestTransformEmbedder_DoesNotMutateInput(t *testing.T) {
+	e := NewTransformEmbedder(&fixedEmbedder{vec: []float32{1}}, WithDims(2))
+	in := []float32{3, 4, 5}
+
+	_ = e.Transform(in)
+
+	if in[0] != 3 || in[1] != 4 || in[2] != 5 {
+		t.Errorf("input mutated: %v", in)
+	}
+}
+
+func TestTransformEmbedder_Int8(t *testing.T) {
+	vec := []float32{0.11, -0.52, 0.33, 0.07, -0.9, 0.001}
+	e := NewTransformEmbedder(&fixedEmbedder{vec: vec}, WithQuantization(QuantizationInt8))
+
+	got := e.Transform(vec)
+
+	// Quantization error per component is bounded by half a level
+	ref := make([]float32, len(vec))
+	copy(ref, vec)
+	normalize(ref)
+	maxAbs := 0.0
+	for _, x := range ref {
+		maxAbs = math.Max(maxAbs, math.Abs(float64(x)))
+	}
+	step := maxAbs / 127
+	for i := range ref {
+		if diff := math.Abs(float64(got[i] - ref[i])); diff > step/2+1e-6 {
+			t.Errorf("component %d: error %.6f exceeds half step %.6f", i, diff, step/2)
+		}
+	}
+}
+
+func TestTransformEmbedder_Binary(t *testing.T) {
+	vec := []float32{0.5, -0.2, 0, 0.9}
+	e := NewTransformEmbedder(&fixedEmbedder{vec: vec}, WithQuantization(QuantizationBinary))
+
+	got := e.Transform(vec)
+
+	wantSigns := []float32{1, -1, -1, 1}
+	for i, v := range got {
+		if (v > 0) != (wantSigns[i] > 0) {
+			t.Errorf("component %d = %v, want sign %v", i, v, wantSigns[i])
+		}
+	}
+	if math.Abs(l2Norm(got)-1) > 1e-5 {
+		t.Errorf("binary vector norm = %v, want 1", l2Norm(got))
+	}
+}
+
+func TestTransformEmbedder_EmbedBatch(t *testing.T) {
+	e := NewTransformEmbedder(&fixedEmbedder{vec: []float32{1, 2, 3, 4}}, WithDims(3), WithQuantization(QuantizationInt8))
+
+	vectors, err := e.EmbedBatch(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	if len(vectors) != 2 {
+		t.Fatalf("expected 2 vectors, got %d", len(vectors))
+	}
+	for i, v := range vectors {
+		if len(v) != 3 {
+			t.Errorf("vector %d has %d dims, want 3", i, len(v))
+		}
+	}
+}
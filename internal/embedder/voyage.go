@@ -81,6 +81,11 @@ func (e *VoyageEmbedder) Dim() int {
 	return e.dim
 }
 
+// Info returns the model's input limit and pricing.
+func (e *VoyageEmbedder) Info() ModelInfo {
+	return lookupModel("voyage", e.model)
+}
+
 // Embed generates an embedding for a single text.
 func (e *VoyageEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
 	embeddings, err := e.EmbedBatch(ctx, []string{text})
@@ -127,7 +132,7 @@ func (e *VoyageEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]fl
 	if resp.StatusCode != http.StatusOK {
 		var errResp voyageErrorResponse
 		_ = json.NewDecoder(resp.Body).Decode(&errResp)
-		return nil, fmt.Errorf("Voyage API error (status %d): %s", resp.StatusCode, errResp.Detail)
+		return nil, apiError("Voyage API", resp.StatusCode, errResp.Detail)
 	}
 
 	var embResp voyageEmbedResponse
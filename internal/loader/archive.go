@@ -0,0 +1,156 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IsArchive reports whether path names an archive WalkArchive can read:
+// .zip, .tar.gz or .tgz.
+func IsArchive(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// WalkArchive loads the documents of each file in the archive at path, in
+// archive order, and calls fn with them. Document paths are the archive
+// path joined with the entry name, e.g. export.zip/guides/setup.md.
+//
+// Entries without a loader, and entries filter (which may be nil) does not
+// select, are skipped. A .ragtuneignore at the archive root adds exclude
+// patterns, and metadata sidecars apply as they do on disk.
+func (r *Registry) WalkArchive(path string, filter *Filter, fn func(docs []Document) error) error {
+	// First pass: the ignore file and sidecars, which may come after the
+	// files they apply to
+	f := &Filter{}
+	if filter != nil {
+		*f = *filter
+		f.exclude = append([]globPattern(nil), filter.exclude...)
+	}
+	sidecars := make(map[string]map[string]interface{})
+	err := eachEntry(path, func(name string, rd io.Reader) error {
+		switch {
+		case name == IgnoreFile:
+			return f.ReadIgnore(rd, path+"/"+name)
+		case IsSidecar(name):
+			data, err := io.ReadAll(rd)
+			if err != nil {
+				return err
+			}
+			meta, err := ParseSidecar(path+"/"+name, data)
+			if err != nil {
+				return err
+			}
+			sidecars[strings.TrimSuffix(name, SidecarSuffix)] = meta
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return eachEntry(path, func(name string, rd io.Reader) error {
+		if _, ok := r.For(name); !ok || IsSidecar(name) || !f.Selects(name) {
+			return nil
+		}
+		data, err := io.ReadAll(rd)
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %w", name, path, err)
+		}
+		docs, err := r.Load(filepath.Join(path, filepath.FromSlash(name)), data)
+		if err != nil {
+			return err
+		}
+		for i := range docs {
+			docs[i].Metadata = MergeMetadata(docs[i].Metadata, sidecars[name])
+		}
+		return fn(docs)
+	})
+}
+
+// eachEntry calls fn with the cleaned name and contents of each regular
+// file in the archive, in archive order.
+func eachEntry(archive string, fn func(name string, r io.Reader) error) error {
+	lower := strings.ToLower(archive)
+	if strings.HasSuffix(lower, ".zip") {
+		return eachZipEntry(archive, fn)
+	}
+	return eachTarEntry(archive, fn)
+}
+
+func eachZipEntry(archive string, fn func(name string, r io.Reader) error) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer zr.Close()
+
+	for _, file := range zr.File {
+		name, ok := entryName(file.Name)
+		if !ok || !file.Mode().IsRegular() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read %s in %s: %w", name, archive, err)
+		}
+		err = fn(name, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eachTarEntry(archive string, fn func(name string, r io.Reader) error) error {
+	file, err := os.Open(archive)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", archive, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", archive, err)
+		}
+		name, ok := entryName(hdr.Name)
+		if !ok || !hdr.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if err := fn(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// entryName cleans an archive entry name to a relative slash-separated
+// path. It reports false for directories and names outside the archive root.
+func entryName(name string) (string, bool) {
+	if strings.HasSuffix(name, "/") {
+		return "", false
+	}
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveFiles are the entries written to test archives, in order. The
+// sidecar comes after its file, and the ignore file after what it excludes.
+var archiveFiles = []struct{ name, content string }{
+	{"export/guide.md", "# Guide\n\nRead me."},
+	{"export/guide.md.meta.json", `{"team": "docs"}`},
+	{"export/node_modules/pkg/README.md", "# Vendored"},
+	{"export/image.png", "binary"},
+	{"./export/kb.jsonl", `{"id": "kb-1", "text": "Reset passwords."}` + "\n"},
+	{IgnoreFile, "node_modules/\n"},
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for _, file := range archiveFiles {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(file.content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "export/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, file := range archiveFiles {
+		hdr := &tar.Header{Name: file.name, Mode: 0644, Size: int64(len(file.content)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(file.content))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name  string
+		write func(*testing.T, string)
+	}{
+		{"docs.zip", writeZip},
+		{"docs.tar.gz", writeTarGz},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			tt.write(t, path)
+			if !IsArchive(path) {
+				t.Fatalf("IsArchive(%s) = false", path)
+			}
+
+			var docs []Document
+			err := Default(DefaultOptions()).WalkArchive(path, nil, func(loaded []Document) error {
+				docs = append(docs, loaded...)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(docs) != 2 {
+				t.Fatalf("got %d docs, want guide.md and one kb.jsonl record", len(docs))
+			}
+			if want := filepath.Join(path, "export", "guide.md"); docs[0].Path != want {
+				t.Errorf("path = %s, want %s", docs[0].Path, want)
+			}
+			if docs[0].Metadata["team"] != "docs" {
+				t.Errorf("sidecar metadata missing: %v", docs[0].Metadata)
+			}
+			if want := filepath.Join(path, "export", "kb.jsonl#kb-1"); docs[1].Path != want {
+				t.Errorf("record path = %s, want %s", docs[1].Path, want)
+			}
+
+			// Caller patterns combine with the archive's ignore file
+			filter, _ := NewFilter(nil, []string{"*.jsonl"})
+			docs = nil
+			Default(DefaultOptions()).WalkArchive(path, filter, func(loaded []Document) error {
+				docs = append(docs, loaded...)
+				return nil
+			})
+			if len(docs) != 1 || len(filter.exclude) != 1 {
+				t.Errorf("got %d docs, want guide.md only, without changing the caller's filter", len(docs))
+			}
+		})
+	}
+
+	if IsArchive("notes.md") || !IsArchive("BACKUP.TGZ") {
+		t.Error("IsArchive misclassifies by extension")
+	}
+}
@@ -0,0 +1,78 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+)
+
+// CSV returns a loader that makes one document per row of a CSV file with
+// a header row. The text is the TextField column; files without a column of
+// the default name ("text") render every column but the ID column as
+// "name: value" lines instead. Rows with empty text are skipped.
+func CSV(opts Options) Loader {
+	return LoaderFunc(func(path string, data []byte) ([]Document, error) {
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		rows, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(rows) == 0 {
+			return nil, nil
+		}
+
+		header := rows[0]
+		col := make(map[string]int, len(header))
+		for i, name := range header {
+			col[strings.TrimSpace(name)] = i
+		}
+		textCol, hasText := col[opts.TextField]
+		if !hasText && opts.TextField != "" && opts.TextField != DefaultOptions().TextField {
+			return nil, fmt.Errorf("no %q column", opts.TextField)
+		}
+		cell := func(row []string, name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+
+		var docs []Document
+		for n, row := range rows[1:] {
+			var text string
+			if hasText {
+				if textCol < len(row) {
+					text = row[textCol]
+				}
+			} else {
+				var lines []string
+				for i, name := range header {
+					if name == opts.IDField {
+						continue
+					}
+					if i < len(row) && strings.TrimSpace(row[i]) != "" {
+						lines = append(lines, name+": "+row[i])
+					}
+				}
+				text = strings.Join(lines, "\n")
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+
+			doc := Document{Path: recordPath(path, cell(row, opts.IDField), n+1), Content: text}
+			for _, field := range opts.MetadataFields {
+				if v := cell(row, field); v != "" {
+					if doc.Metadata == nil {
+						doc.Metadata = make(map[string]interface{})
+					}
+					doc.Metadata[field] = v
+				}
+			}
+			docs = append(docs, doc)
+		}
+		return docs, nil
+	})
+}
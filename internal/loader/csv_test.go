@@ -0,0 +1,36 @@
+package loader
+
+import (
+	"testing"
+)
+
+func TestCSV_AllColumns(t *testing.T) {
+	r := Default(Options{TextField: "text", IDField: "id", MetadataFields: []string{"product"}})
+	docs := loadFixture(t, r, "faq.csv")
+
+	// The third row has only an ID and is skipped
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 docs, got %d", len(docs))
+	}
+	want := "question: How do I reset my password?\nanswer: Use the \"Forgot password\" link.\nproduct: auth"
+	if docs[0].Content != want {
+		t.Errorf("Content = %q, want %q", docs[0].Content, want)
+	}
+	if docs[0].Path != "testdata/faq.csv#q1" || docs[0].Metadata["product"] != "auth" {
+		t.Errorf("unexpected doc: %+v", docs[0])
+	}
+}
+
+func TestCSV_TextColumn(t *testing.T) {
+	r := Default(Options{TextField: "answer"})
+	docs := loadFixture(t, r, "faq.csv")
+
+	if len(docs) != 2 || docs[1].Content != "Monthly" || docs[1].Path != "testdata/faq.csv#2" {
+		t.Errorf("unexpected docs: %+v", docs)
+	}
+
+	r = Default(Options{TextField: "body"})
+	if _, err := r.Load("faq.csv", []byte("id,answer\n1,x\n")); err == nil {
+		t.Error("expected error for a missing text column")
+	}
+}
@@ -0,0 +1,119 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOCX extracts the text of a Word document's body, one paragraph per
+// block. A "Title" paragraph becomes a level-1 markdown heading and
+// "Heading N" paragraphs level N+1 headings below it; each table cell
+// paragraph is a block of its own.
+var DOCX Loader = LoaderFunc(func(path string, data []byte) ([]Document, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCX archive: %w", err)
+	}
+	var body io.ReadCloser
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			if body, err = f.Open(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if body == nil {
+		return nil, fmt.Errorf("invalid DOCX archive: missing word/document.xml")
+	}
+	defer body.Close()
+
+	text, err := docxText(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DOCX document: %w", err)
+	}
+	return []Document{{Path: path, Content: text}}, nil
+})
+
+// docxText renders WordprocessingML paragraphs as text. Elements are
+// matched by local name, so any namespace prefix works.
+func docxText(r io.Reader) (string, error) {
+	dec := xml.NewDecoder(r)
+	var (
+		paras []string
+		para  strings.Builder
+		level int  // heading level of the current paragraph (0 = body text)
+		inT   bool // inside <w:t>
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				level = 0
+			case "pStyle":
+				level = headingStyleLevel(attr(t, "val"))
+			case "t":
+				inT = true
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inT = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				if level > 0 {
+					text = strings.Repeat("#", level) + " " + text
+				}
+				paras = append(paras, text)
+			}
+		case xml.CharData:
+			if inT {
+				para.Write(t)
+			}
+		}
+	}
+	return strings.Join(paras, "\n\n"), nil
+}
+
+// headingStyleLevel maps a paragraph style ID such as "Heading2" or
+// "Title" to a markdown heading level, or 0 for other styles.
+func headingStyleLevel(style string) int {
+	s := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	if s == "title" {
+		return 1
+	}
+	if rest, ok := strings.CutPrefix(s, "heading"); ok && len(rest) == 1 && rest[0] >= '1' && rest[0] <= '6' {
+		return min(int(rest[0]-'0')+1, 6)
+	}
+	return 0
+}
+
+// attr returns the value of the attribute with the given local name.
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package loader
+
+import (
+	"testing"
+)
+
+func TestDOCX(t *testing.T) {
+	docs := loadFixture(t, Default(DefaultOptions()), "guide.docx")
+
+	want := "# Onboarding Guide\n\n## Accounts\n\nCreate an account.\tThen verify your email.\n\nPlan\n\nSeats"
+	if len(docs) != 1 || docs[0].Content != want {
+		t.Errorf("DOCX content = %q, want %q", docs[0].Content, want)
+	}
+
+	if _, err := DOCX.Load("bad.docx", []byte("not a zip")); err == nil {
+		t.Error("expected error for an invalid archive")
+	}
+}
+
+func TestHeadingStyleLevel(t *testing.T) {
+	tests := map[string]int{"Title": 1, "Heading1": 2, "heading 3": 4, "Heading9": 0, "Normal": 0}
+	for style, want := range tests {
+		if got := headingStyleLevel(style); got != want {
+			t.Errorf("headingStyleLevel(%q) = %d, want %d", style, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package loader
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// IgnoreFile is the name of the file, at the root of a docs directory or
+// archive, whose patterns exclude files from ingestion.
+const IgnoreFile = ".ragtuneignore"
+
+// Filter selects files by glob patterns on their slash-separated path
+// relative to the docs root. Patterns follow .gitignore conventions:
+//
+//   - "*", "?" and "[...]" match within one path segment; "**" matches any
+//     number of segments
+//   - a pattern without a slash, such as "*.md" or "node_modules", matches
+//     at any depth; a leading slash anchors it to the root
+//   - a trailing slash, as in "drafts/", matches only directories
+//   - in ignore files, a leading "!" re-includes what an earlier pattern
+//     excluded, and lines starting with "#" are comments
+//
+// A file is excluded if the last exclude pattern matching it or one of its
+// directories is not negated. If there are include patterns, a file must
+// also match one of them, or be under a directory that does.
+type Filter struct {
+	include []globPattern
+	exclude []globPattern
+}
+
+// globPattern is one parsed pattern.
+type globPattern struct {
+	segments []string
+	dirOnly  bool
+	negate   bool
+}
+
+// NewFilter returns a filter with the given include and exclude patterns.
+func NewFilter(include, exclude []string) (*Filter, error) {
+	f := &Filter{}
+	for _, p := range include {
+		g, err := parsePattern(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %q: %w", p, err)
+		}
+		f.include = append(f.include, g)
+	}
+	for _, p := range exclude {
+		if err := f.Exclude(p); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Exclude adds an exclude pattern. A leading "!" negates it.
+func (f *Filter) Exclude(pattern string) error {
+	negate := strings.HasPrefix(pattern, "!")
+	g, err := parsePattern(strings.TrimPrefix(pattern, "!"))
+	if err != nil {
+		return fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+	}
+	g.negate = negate
+	f.exclude = append(f.exclude, g)
+	return nil
+}
+
+// ReadIgnore adds the exclude patterns of an ignore file, one per line,
+// read from r. Blank lines and lines starting with "#" are skipped.
+func (f *Filter) ReadIgnore(r io.Reader, name string) error {
+	lines := bufio.NewScanner(r)
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(lines.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := f.Exclude(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", name, n, err)
+		}
+	}
+	return lines.Err()
+}
+
+// ReadIgnoreFile adds the patterns of the ignore file at path, if it exists.
+func (f *Filter) ReadIgnoreFile(path string) error {
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.ReadIgnore(file, path)
+}
+
+// SkipsDir reports whether the directory at rel, and everything under it,
+// is excluded.
+func (f *Filter) SkipsDir(rel string) bool {
+	return f.excluded(strings.Split(rel, "/"), true)
+}
+
+// Selects reports whether the file at rel is selected for ingestion.
+func (f *Filter) Selects(rel string) bool {
+	if f == nil {
+		return true
+	}
+	segs := strings.Split(rel, "/")
+	for i := 1; i < len(segs); i++ {
+		if f.excluded(segs[:i], true) {
+			return false
+		}
+	}
+	if f.excluded(segs, false) {
+		return false
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, g := range f.include {
+		for i := 1; i <= len(segs); i++ {
+			// Directories match any include; files only non-dir-only ones
+			if (i < len(segs) || !g.dirOnly) && g.match(segs[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// excluded applies the exclude patterns to one path: the last match wins.
+func (f *Filter) excluded(segs []string, isDir bool) bool {
+	if f == nil {
+		return false
+	}
+	excluded := false
+	for _, g := range f.exclude {
+		if (isDir || !g.dirOnly) && g.match(segs) {
+			excluded = !g.negate
+		}
+	}
+	return excluded
+}
+
+// parsePattern splits a pattern into segments, anchoring it to the root if
+// it has a slash other than a trailing one, and to any depth otherwise.
+func parsePattern(p string) (globPattern, error) {
+	p = strings.TrimSpace(p)
+	var g globPattern
+	if strings.HasSuffix(p, "/") {
+		g.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if p == "" {
+		return g, fmt.Errorf("empty pattern")
+	}
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if !anchored {
+		p = "**/" + p
+	}
+	g.segments = strings.Split(p, "/")
+	for _, seg := range g.segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+// match reports whether the pattern matches the whole path.
+func (g globPattern) match(segs []string) bool {
+	return matchSegments(g.segments, segs)
+}
+
+// matchSegments matches pattern segments against path segments, with "**"
+// matching zero or more path segments.
+func matchSegments(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pattern[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
@@ -0,0 +1,90 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	f, err := NewFilter(nil, []string{"node_modules/", "CHANGELOG.md", "/drafts", "docs/**/*.tmp.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"guide.md", true},
+		{"node_modules/pkg/README.md", false},
+		{"web/node_modules/pkg/README.md", false},
+		{"CHANGELOG.md", false},
+		{"pkg/CHANGELOG.md", false},
+		{"drafts/idea.md", false},
+		{"notes/drafts/idea.md", true}, // /drafts is anchored to the root
+		{"docs/a/b/page.tmp.md", false},
+		{"docs/page.md", true},
+	}
+	for _, tt := range tests {
+		if got := f.Selects(tt.path); got != tt.want {
+			t.Errorf("Selects(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	if !f.SkipsDir("web/node_modules") || f.SkipsDir("web") {
+		t.Error("SkipsDir should prune node_modules only")
+	}
+	// A trailing slash matches directories, not files
+	if !f.Selects("node_modules") {
+		t.Error("node_modules/ should not match a file")
+	}
+}
+
+func TestFilter_Include(t *testing.T) {
+	f, err := NewFilter([]string{"*.md", "api"}, []string{"internal.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{
+		"guide.md":        true,
+		"sub/guide.md":    true,
+		"notes.txt":       false,
+		"api/spec.json":   true, // under an included directory
+		"api/internal.md": false,
+		"other/spec.json": false,
+	} {
+		if got := f.Selects(path); got != want {
+			t.Errorf("Selects(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestFilter_IgnoreFile(t *testing.T) {
+	f := &Filter{}
+	ignore := "# build output\n\n*.log.md\n!keep.log.md\ntmp/\n"
+	if err := f.ReadIgnore(strings.NewReader(ignore), IgnoreFile); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]bool{
+		"run.log.md":  false,
+		"keep.log.md": true,
+		"tmp/a.md":    false,
+		"a.md":        true,
+	} {
+		if got := f.Selects(path); got != want {
+			t.Errorf("Selects(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	if err := f.ReadIgnore(strings.NewReader("ok\n[bad\n"), IgnoreFile); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("err = %v, want the bad pattern's line", err)
+	}
+	if _, err := NewFilter([]string{"/"}, nil); err == nil {
+		t.Error("expected an error for an empty pattern")
+	}
+}
+
+func TestFilter_Nil(t *testing.T) {
+	var f *Filter
+	if !f.Selects("any/file.md") || f.SkipsDir("any") {
+		t.Error("a nil filter should select everything")
+	}
+}
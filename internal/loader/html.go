@@ -0,0 +1,163 @@
+package loader
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// boilerplate elements are dropped with their content.
+var boilerplate = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Nav: true, atom.Header: true, atom.Footer: true, atom.Aside: true,
+	atom.Form: true, atom.Button: true, atom.Iframe: true, atom.Svg: true,
+}
+
+// blockElements end the current paragraph.
+var blockElements = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.Main: true, atom.Ul: true, atom.Ol: true, atom.Li: true,
+	atom.Table: true, atom.Tr: true, atom.Blockquote: true, atom.Pre: true,
+	atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Figure: true,
+	atom.Figcaption: true, atom.Hr: true,
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+var (
+	whitespaceRe = regexp.MustCompile(`\s+`)
+	spaceRunRe   = regexp.MustCompile(`[ \t\f\r]+`)
+	blankLinesRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTML extracts the readable text of a page. Scripts, styles, navigation,
+// headers, footers, sidebars and forms are dropped; if the page has a <main>
+// or <article> element, only its content is kept, and headers inside it
+// (which usually hold the article title) are kept too. Headings become markdown
+// headings and list items "- " lines. A page without an <h1> gets its
+// <title> as the first heading.
+var HTML Loader = LoaderFunc(func(path string, data []byte) ([]Document, error) {
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	body := findElement(root, atom.Main)
+	if body == nil {
+		body = findElement(root, atom.Article)
+	}
+	if body == nil {
+		body = root
+	}
+
+	w := htmlWriter{keepHeader: body != root}
+	if findElement(body, atom.H1) == nil {
+		if title := findElement(root, atom.Title); title != nil {
+			if t := strings.TrimSpace(nodeText(title)); t != "" {
+				w.block()
+				w.b.WriteString("# " + t)
+				w.block()
+			}
+		}
+	}
+	w.walk(body)
+	return []Document{{Path: path, Content: w.String()}}, nil
+})
+
+// htmlWriter renders nodes as text with markdown headings.
+type htmlWriter struct {
+	b          strings.Builder
+	pre        int  // depth of enclosing <pre> elements
+	keepHeader bool // render <header> elements (inside <main> or <article>)
+}
+
+// block starts a new paragraph.
+func (w *htmlWriter) block() {
+	w.b.WriteString("\n\n")
+}
+
+func (w *htmlWriter) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if w.pre > 0 {
+			w.b.WriteString(n.Data)
+		} else {
+			w.b.WriteString(whitespaceRe.ReplaceAllString(n.Data, " "))
+		}
+		return
+	case html.ElementNode:
+		if n.DataAtom == atom.Head || boilerplate[n.DataAtom] && !(n.DataAtom == atom.Header && w.keepHeader) {
+			return
+		}
+		if level, ok := headingLevels[n.DataAtom]; ok {
+			if t := strings.TrimSpace(nodeText(n)); t != "" {
+				w.block()
+				w.b.WriteString(strings.Repeat("#", level) + " " + strings.Join(strings.Fields(t), " "))
+				w.block()
+			}
+			return
+		}
+		switch n.DataAtom {
+		case atom.Br:
+			w.b.WriteString("\n")
+			return
+		case atom.Li:
+			w.b.WriteString("\n- ")
+		case atom.Td, atom.Th:
+			w.b.WriteString(" ")
+		case atom.Pre:
+			w.pre++
+			defer func() { w.pre-- }()
+		}
+	}
+
+	block := n.Type == html.ElementNode && blockElements[n.DataAtom] && n.DataAtom != atom.Li
+	if block {
+		w.block()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		w.walk(c)
+	}
+	if block {
+		w.block()
+	}
+}
+
+// String returns the rendered text with blank-line runs and spaces collapsed.
+func (w *htmlWriter) String() string {
+	lines := strings.Split(w.b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(spaceRunRe.ReplaceAllString(line, " "))
+	}
+	return strings.TrimSpace(blankLinesRe.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// findElement returns the first element of type a under n, depth first.
+func findElement(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// nodeText concatenates the text under n.
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(nodeText(c))
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package loader
+
+import (
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	docs := loadFixture(t, Default(DefaultOptions()), "help.html")
+
+	want := "# Rotating API keys\n\nKeys identify a project. Rotate them every 90 days.\n\n## Steps\n\n- Create a new key.\n- Revoke the old key."
+	if len(docs) != 1 || docs[0].Content != want {
+		t.Errorf("HTML content = %q, want %q", docs[0].Content, want)
+	}
+}
+
+func TestHTML_TitleFallback(t *testing.T) {
+	page := `<html><head><title>Billing FAQ</title></head><body><nav>Menu</nav><div>Invoices<br>are monthly.</div></body></html>`
+	docs, err := HTML.Load("faq.html", []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Billing FAQ\n\nInvoices\nare monthly."; docs[0].Content != want {
+		t.Errorf("HTML content = %q, want %q", docs[0].Content, want)
+	}
+}
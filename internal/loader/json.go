@@ -0,0 +1,177 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNoText is returned for JSON and JSONL files in which no record is an
+// object with a string text field, such as package.json or other JSON that
+// is not a document export.
+var ErrNoText = errors.New("no record has the text field")
+
+// JSON returns a loader for .json files holding one record or an array of
+// records. Each record becomes a document; records with empty text, and
+// records without the text field, are skipped. A file in which no record
+// has the text field fails with ErrNoText.
+func JSON(opts Options) Loader {
+	return LoaderFunc(func(path string, data []byte) ([]Document, error) {
+		var v interface{}
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		records, ok := v.([]interface{})
+		if !ok {
+			records = []interface{}{v}
+		}
+
+		var docs []Document
+		found := false
+		for i, rec := range records {
+			doc, ok := jsonRecord(path, rec, i+1, opts)
+			if !ok {
+				continue
+			}
+			found = true
+			if strings.TrimSpace(doc.Content) != "" {
+				docs = append(docs, doc)
+			}
+		}
+		if !found && len(records) > 0 {
+			return nil, noTextError(opts)
+		}
+		return docs, nil
+	})
+}
+
+// JSONL returns a loader for newline-delimited JSON, one record per line.
+// Blank lines are ignored.
+func JSONL(opts Options) Loader {
+	return LoaderFunc(func(path string, data []byte) ([]Document, error) {
+		var docs []Document
+		err := ReadJSONL(bytes.NewReader(data), path, opts, func(doc Document) error {
+			docs = append(docs, doc)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return docs, nil
+	})
+}
+
+// ReadJSONL streams newline-delimited JSON records from r, calling fn with
+// each record's document as it is read, so a stream of any length can be
+// ingested. Documents are named like those of a .jsonl file at path. Records
+// are skipped as by JSON, and a stream in which no record has the text field
+// fails with ErrNoText.
+func ReadJSONL(r io.Reader, path string, opts Options, fn func(doc Document) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	found, records := false, 0
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec interface{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("line %d: invalid JSON: %w", n, err)
+		}
+		records++
+		doc, ok := jsonRecord(path, rec, n, opts)
+		if !ok {
+			continue
+		}
+		found = true
+		if strings.TrimSpace(doc.Content) == "" {
+			continue
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if !found && records > 0 {
+		return noTextError(opts)
+	}
+	return nil
+}
+
+// jsonRecord converts record n of a JSON file into a document. It reports
+// false for records that are not objects with a string text field.
+func jsonRecord(path string, rec interface{}, n int, opts Options) (Document, bool) {
+	obj, ok := rec.(map[string]interface{})
+	if !ok {
+		return Document{}, false
+	}
+	raw, ok := lookupField(obj, textField(opts))
+	if !ok {
+		return Document{}, false
+	}
+	text, ok := raw.(string)
+	if !ok {
+		return Document{}, false
+	}
+
+	var id string
+	if opts.IDField != "" {
+		if v, ok := lookupField(obj, opts.IDField); ok && v != nil {
+			id = scalarString(v)
+		}
+	}
+	doc := Document{Path: recordPath(path, id, n), Content: text}
+	for _, field := range opts.MetadataFields {
+		if v, ok := lookupField(obj, field); ok && v != nil {
+			if doc.Metadata == nil {
+				doc.Metadata = make(map[string]interface{})
+			}
+			doc.Metadata[field] = v
+		}
+	}
+	return doc, true
+}
+
+// textField returns the field records take their text from.
+func textField(opts Options) string {
+	if opts.TextField == "" {
+		return "text"
+	}
+	return opts.TextField
+}
+
+// noTextError returns ErrNoText naming the text field.
+func noTextError(opts Options) error {
+	return fmt.Errorf("%w %q", ErrNoText, textField(opts))
+}
+
+// lookupField resolves a dotted path such as "body.text" in obj.
+func lookupField(obj map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = obj
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// scalarString formats a decoded JSON scalar, printing integral numbers
+// without a decimal point.
+func scalarString(v interface{}) string {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return fmt.Sprint(int64(f))
+	}
+	return fmt.Sprint(v)
+}
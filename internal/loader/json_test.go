@@ -0,0 +1,75 @@
+package loader
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestJSONL(t *testing.T) {
+	r := Default(Options{TextField: "body.text", IDField: "id", MetadataFields: []string{"product", "version"}})
+	docs := loadFixture(t, r, "kb.jsonl")
+
+	want := []Document{
+		{Path: "testdata/kb.jsonl#kb-1", Content: "Reset your password from the login page.", Metadata: map[string]interface{}{"product": "auth", "version": float64(2)}},
+		{Path: "testdata/kb.jsonl#kb-2", Content: "Invoices are emailed monthly.", Metadata: map[string]interface{}{"product": "billing"}},
+		// Line 4 has empty text and is skipped; line 5 has no ID
+		{Path: "testdata/kb.jsonl#5", Content: "Untitled record."},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("JSONL docs = %+v, want %+v", docs, want)
+	}
+}
+
+func TestJSON_Array(t *testing.T) {
+	r := Default(Options{TextField: "text", IDField: "id", MetadataFields: []string{"tags"}})
+	docs := loadFixture(t, r, "kb.json")
+
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 docs, got %d", len(docs))
+	}
+	if docs[0].Path != "testdata/kb.json#7" || docs[1].Path != "testdata/kb.json#8" {
+		t.Errorf("numeric IDs should render without decimals: %s, %s", docs[0].Path, docs[1].Path)
+	}
+	if tags := docs[1].Metadata["tags"]; !reflect.DeepEqual(tags, []interface{}{"api", "limits"}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestJSON_Errors(t *testing.T) {
+	l := JSON(DefaultOptions())
+	if _, err := l.Load("a.json", []byte(`{"body": "no text field"}`)); !errors.Is(err, ErrNoText) {
+		t.Errorf("expected ErrNoText for a record without the text field, got %v", err)
+	}
+	if _, err := l.Load("a.json", []byte(`[1, 2]`)); !errors.Is(err, ErrNoText) {
+		t.Errorf("expected ErrNoText for non-object records, got %v", err)
+	}
+	if _, err := JSONL(DefaultOptions()).Load("a.jsonl", []byte("{\"id\": 1}\n{\"text\": 2}\n")); !errors.Is(err, ErrNoText) {
+		t.Errorf("expected ErrNoText for JSONL without string text, got %v", err)
+	}
+	if _, err := JSONL(DefaultOptions()).Load("a.jsonl", []byte("{\"text\": \"ok\"}\n{broken")); err == nil {
+		t.Error("expected error for an invalid line")
+	}
+}
+
+func TestJSON_SkipsRecordsWithoutText(t *testing.T) {
+	docs, err := JSON(DefaultOptions()).Load("a.json", []byte(`[{"id": 1, "title": "x"}, {"id": 2, "text": "Kept."}, "stray"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Path != "a.json#2" {
+		t.Errorf("docs = %+v, want only record 2", docs)
+	}
+
+	// With Skipped set, the registry loads such files as no documents
+	r := Default(DefaultOptions())
+	var skipped []string
+	r.Skipped = func(path string, err error) { skipped = append(skipped, path) }
+	docs, err = r.Load("package.json", []byte(`{"name": "site"}`))
+	if err != nil || len(docs) != 0 {
+		t.Errorf("Load = %v, %v, want no documents and no error", docs, err)
+	}
+	if len(skipped) != 1 || skipped[0] != "package.json" {
+		t.Errorf("skipped = %v, want package.json", skipped)
+	}
+}
@@ -0,0 +1,187 @@
+// Package loader extracts plain text documents from files for ingestion.
+// Each file format implements Loader; a Registry maps file extensions to
+// loaders. Formats that hold many records per file (JSONL, CSV) yield one
+// Document per record.
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Document is a unit of text extracted from a file.
+type Document struct {
+	// Path identifies the document. It is the file path, or the file path
+	// followed by "#" and the record ID for multi-record files.
+	Path string
+
+	// Content is the extracted text. Loaders for structured formats render
+	// headings as markdown ("# Title") so heading-aware chunkers can use them.
+	Content string
+
+	// Metadata holds fields extracted alongside the text (nil if none).
+	Metadata map[string]interface{}
+
+	// Offset and LineOffset locate Content in the file when it is a verbatim
+	// part of it that does not start the file, e.g. after front matter: the
+	// byte offset and the number of lines before it.
+	Offset     int
+	LineOffset int
+}
+
+// Loader extracts documents from the contents of one file.
+type Loader interface {
+	// Load extracts documents from data, read from path.
+	Load(path string, data []byte) ([]Document, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface.
+type LoaderFunc func(path string, data []byte) ([]Document, error)
+
+// Load calls f(path, data).
+func (f LoaderFunc) Load(path string, data []byte) ([]Document, error) {
+	return f(path, data)
+}
+
+// Options configures the record-based loaders (JSON, JSONL and CSV).
+type Options struct {
+	// TextField names the field (or CSV column) holding the text. Dotted
+	// paths select nested JSON fields, e.g. "body.text". For CSV, an empty
+	// TextField renders every column as "name: value" lines.
+	TextField string
+
+	// IDField names the field used in the document path ("file#id").
+	// Records without it are numbered from 1.
+	IDField string
+
+	// MetadataFields lists fields copied into Document.Metadata.
+	MetadataFields []string
+}
+
+// DefaultOptions returns the options used when no flags are given.
+func DefaultOptions() Options {
+	return Options{TextField: "text", IDField: "id"}
+}
+
+// Registry maps lowercase file extensions, including the dot, to loaders.
+type Registry struct {
+	loaders map[string]Loader
+
+	// Skipped, if set, is called for each file that holds no records with
+	// the text field (ErrNoText), which then loads as no documents instead
+	// of failing, so stray JSON files do not stop a directory walk.
+	Skipped func(path string, err error)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{loaders: make(map[string]Loader)}
+}
+
+// Default returns a registry with every built-in format: plain text and
+// markdown (with front matter), HTML, JSON and JSONL, CSV, DOCX and PDF.
+// Options configure the record-based loaders.
+func Default(opts Options) *Registry {
+	r := NewRegistry()
+	r.Register(Markdown, ".md", ".markdown", ".txt")
+	r.Register(HTML, ".html", ".htm")
+	r.Register(JSON(opts), ".json")
+	r.Register(JSONL(opts), ".jsonl", ".ndjson")
+	r.Register(CSV(opts), ".csv")
+	r.Register(DOCX, ".docx")
+	r.Register(PDF, ".pdf")
+	return r
+}
+
+// Register sets l as the loader for each extension, replacing any existing one.
+func (r *Registry) Register(l Loader, exts ...string) {
+	for _, ext := range exts {
+		r.loaders[strings.ToLower(ext)] = l
+	}
+}
+
+// For returns the loader registered for path's extension.
+func (r *Registry) For(path string) (Loader, bool) {
+	l, ok := r.loaders[strings.ToLower(filepath.Ext(path))]
+	return l, ok
+}
+
+// Extensions returns the registered extensions in sorted order.
+func (r *Registry) Extensions() []string {
+	exts := make([]string, 0, len(r.loaders))
+	for ext := range r.loaders {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// Load extracts documents from data with the loader for path's extension.
+func (r *Registry) Load(path string, data []byte) ([]Document, error) {
+	l, ok := r.For(path)
+	if !ok {
+		return nil, fmt.Errorf("no loader for %s", path)
+	}
+	docs, err := l.Load(path, data)
+	if errors.Is(err, ErrNoText) && r.Skipped != nil {
+		r.Skipped(path, err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return docs, nil
+}
+
+// LoadFile reads the file at path and extracts its documents. Fields from a
+// metadata sidecar (path + SidecarSuffix) are added to every document's
+// metadata, overriding fields of the same name from the file itself.
+func (r *Registry) LoadFile(path string) ([]Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	docs, err := r.Load(path, data)
+	if err != nil {
+		return nil, err
+	}
+	sidecar, err := ReadSidecar(path)
+	if err != nil {
+		return nil, err
+	}
+	for i := range docs {
+		docs[i].Metadata = MergeMetadata(docs[i].Metadata, sidecar)
+	}
+	return docs, nil
+}
+
+// Text loads a file as a single document, unchanged.
+var Text Loader = LoaderFunc(func(path string, data []byte) ([]Document, error) {
+	return []Document{{Path: path, Content: string(data)}}, nil
+})
+
+// Markdown loads a text or markdown file as a single document, moving YAML
+// front matter out of the text into Metadata.
+var Markdown Loader = LoaderFunc(func(path string, data []byte) ([]Document, error) {
+	text := string(data)
+	meta, body, offset := SplitFrontMatter(text)
+	return []Document{{
+		Path:       path,
+		Content:    body,
+		Metadata:   meta,
+		Offset:     offset,
+		LineOffset: strings.Count(text[:offset], "\n"),
+	}}, nil
+})
+
+// recordPath returns the path of record n (1-based) of a multi-record file.
+func recordPath(path, id string, n int) string {
+	if id == "" {
+		id = fmt.Sprint(n)
+	}
+	return path + "#" + id
+}
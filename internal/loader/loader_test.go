@@ -0,0 +1,54 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// loadFixture loads testdata/name with the registry's loader.
+func loadFixture(t *testing.T, r *Registry, name string) []Document {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := r.Load(path, data)
+	if err != nil {
+		t.Fatalf("Load(%s) failed: %v", name, err)
+	}
+	return docs
+}
+
+func TestRegistry(t *testing.T) {
+	r := Default(DefaultOptions())
+	for _, path := range []string{"a.md", "b.TXT", "c.html", "d.jsonl", "e.csv", "f.docx", "g.pdf"} {
+		if _, ok := r.For(path); !ok {
+			t.Errorf("no loader for %s", path)
+		}
+	}
+	if _, ok := r.For("image.png"); ok {
+		t.Error("unexpected loader for .png")
+	}
+	if _, err := r.Load("image.png", nil); err == nil {
+		t.Error("expected error loading an unregistered extension")
+	}
+
+	r.Register(Text, ".rst")
+	if _, ok := r.For("notes.rst"); !ok {
+		t.Error("registered extension not found")
+	}
+}
+
+func TestText(t *testing.T) {
+	docs, err := Text.Load("a.md", []byte("# Title\n\nbody"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Document{{Path: "a.md", Content: "# Title\n\nbody"}}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("Text.Load() = %v, want %v", docs, want)
+	}
+}
@@ -0,0 +1,116 @@
+package loader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SidecarSuffix is appended to a file's name to form its metadata sidecar,
+// e.g. guide.md.meta.json holds metadata for guide.md.
+const SidecarSuffix = ".meta.json"
+
+// IsSidecar reports whether path is a metadata sidecar.
+func IsSidecar(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), SidecarSuffix)
+}
+
+// SplitFrontMatter separates a leading YAML front matter block, delimited by
+// "---" lines, from the body. It returns the parsed fields (nil if there is no
+// front matter), the body, and the byte offset of the body in text. Dates are
+// returned as "2006-01-02" strings (RFC 3339 if they have a time of day).
+//
+// A block that is not a YAML mapping, such as a horizontal rule followed
+// later by another, is not front matter: the text is returned unchanged.
+func SplitFrontMatter(text string) (map[string]interface{}, string, int) {
+	first, _, ok := strings.Cut(text, "\n")
+	if !ok || strings.TrimRight(first, " \t\r") != "---" {
+		return nil, text, 0
+	}
+	blockStart := len(first) + 1
+	for offset := blockStart; offset < len(text); {
+		line, next := text[offset:], len(text)
+		if end := strings.IndexByte(line, '\n'); end >= 0 {
+			line, next = line[:end], offset+end+1
+		}
+		if delim := strings.TrimRight(line, " \t\r"); delim == "---" || delim == "..." {
+			var meta map[string]interface{}
+			if err := yaml.Unmarshal([]byte(text[blockStart:offset]), &meta); err != nil {
+				return nil, text, 0
+			}
+			return normalizeMetadata(meta), text[next:], next
+		}
+		offset = next
+	}
+	return nil, text, 0 // unterminated: not front matter
+}
+
+// ReadSidecar reads the metadata sidecar of the file at path. It returns nil
+// without an error if there is no sidecar.
+func ReadSidecar(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path + SidecarSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseSidecar(path+SidecarSuffix, data)
+}
+
+// ParseSidecar parses the contents of the metadata sidecar at path.
+func ParseSidecar(path string, data []byte) (map[string]interface{}, error) {
+	var meta map[string]interface{}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, fmt.Errorf("invalid metadata sidecar %s: %w", path, err)
+	}
+	return meta, nil
+}
+
+// MergeMetadata returns the fields of base overlaid with those of over.
+// Either may be nil.
+func MergeMetadata(base, over map[string]interface{}) map[string]interface{} {
+	if len(over) == 0 {
+		return base
+	}
+	out := make(map[string]interface{}, len(base)+len(over))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range over {
+		out[k] = v
+	}
+	return out
+}
+
+// normalizeMetadata converts YAML values that don't survive JSON payloads:
+// timestamps become strings.
+func normalizeMetadata(meta map[string]interface{}) map[string]interface{} {
+	for k, v := range meta {
+		meta[k] = normalizeValue(v)
+	}
+	return meta
+}
+
+func normalizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case time.Time:
+		if val.Hour() == 0 && val.Minute() == 0 && val.Second() == 0 && val.Nanosecond() == 0 {
+			return val.Format("2006-01-02")
+		}
+		return val.Format(time.RFC3339)
+	case []interface{}:
+		for i := range val {
+			val[i] = normalizeValue(val[i])
+		}
+	case map[string]interface{}:
+		return normalizeMetadata(val)
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	text := "---\ntitle: Rotation\nproduct: auth\nversion: 2\ntags: [keys, security]\nupdated: 2026-03-01\n---\n# Keys\n\nRotate often."
+	meta, body, offset := SplitFrontMatter(text)
+	want := map[string]interface{}{
+		"title":   "Rotation",
+		"product": "auth",
+		"version": 2,
+		"tags":    []interface{}{"keys", "security"},
+		"updated": "2026-03-01",
+	}
+	if !reflect.DeepEqual(meta, want) {
+		t.Errorf("meta = %v, want %v", meta, want)
+	}
+	if body != "# Keys\n\nRotate often." || text[offset:] != body {
+		t.Errorf("body = %q (offset %d)", body, offset)
+	}
+}
+
+func TestSplitFrontMatter_NotFrontMatter(t *testing.T) {
+	for _, text := range []string{
+		"# Title\n---\nbody",
+		"---\nunterminated: block",
+		"---",
+		"---\ntitle: [unclosed\n---\nbody",
+		"---\nRelease notes\n---\nbody",
+		"---\n\nIntro paragraph: see below.\nMore text\n---\nbody",
+		"---\n- a\n- b\n---\nbody",
+	} {
+		meta, body, offset := SplitFrontMatter(text)
+		if meta != nil || body != text || offset != 0 {
+			t.Errorf("SplitFrontMatter(%q) = %v, %q, %d; want text unchanged", text, meta, body, offset)
+		}
+	}
+}
+
+func TestMarkdown_HorizontalRules(t *testing.T) {
+	// Horizontal rules around a banner are not front matter; the file still loads
+	text := "---\nWelcome to the guide!\n---\n\n# Setup\n"
+	docs, err := Markdown.Load("guide.md", []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docs[0].Content != text || docs[0].Metadata != nil {
+		t.Errorf("content = %q, metadata = %v; want the file unchanged", docs[0].Content, docs[0].Metadata)
+	}
+}
+
+func TestLoadFile_Sidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "guide.md")
+	if err := os.WriteFile(path, []byte("---\nproduct: auth\naudience: admins\n---\n\nBody"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+SidecarSuffix, []byte(`{"product": "billing", "version": "3.1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	docs, err := Default(DefaultOptions()).LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"product": "billing", "audience": "admins", "version": "3.1"}
+	if !reflect.DeepEqual(docs[0].Metadata, want) {
+		t.Errorf("metadata = %v, want %v (sidecar should override front matter)", docs[0].Metadata, want)
+	}
+	if docs[0].Content != "\nBody" || docs[0].LineOffset != 4 {
+		t.Errorf("content = %q, line offset = %d", docs[0].Content, docs[0].LineOffset)
+	}
+	if !IsSidecar(path + SidecarSuffix) {
+		t.Error("IsSidecar should match the sidecar path")
+	}
+}
@@ -0,0 +1,831 @@
+package loader
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PDF extracts the text of a PDF's pages in page order, with a blank line
+// between pages. It reads uncompressed and Flate-compressed content streams,
+// object streams, and ToUnicode maps for composite fonts. Scanned pages
+// (images only) and encrypted files yield no text.
+var PDF Loader = LoaderFunc(func(path string, data []byte) ([]Document, error) {
+	text, err := pdfText(data)
+	if err != nil {
+		return nil, err
+	}
+	return []Document{{Path: path, Content: text}}, nil
+})
+
+// ErrPDFEncrypted is returned for password-protected PDFs.
+var ErrPDFEncrypted = errors.New("encrypted PDFs are not supported")
+
+// PDF object types. Numbers are float64, booleans bool and null nil.
+type (
+	pdfName    string
+	pdfString  string
+	pdfKeyword string
+	pdfArray   []interface{}
+	pdfDict    map[string]interface{}
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+// pdfFile indexes the objects of a PDF by number.
+type pdfFile struct {
+	objects map[int]interface{}
+	fonts   map[pdfRef]*pdfFont
+}
+
+var objHeaderRe = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+func pdfText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF")) {
+		return "", errors.New("not a PDF file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return "", ErrPDFEncrypted
+	}
+
+	f := &pdfFile{objects: make(map[int]interface{}), fonts: make(map[pdfRef]*pdfFont)}
+	f.scanObjects(data)
+	f.expandObjectStreams()
+
+	var pages []string
+	for _, page := range f.pages() {
+		if text := f.pageText(page); text != "" {
+			pages = append(pages, text)
+		}
+	}
+	return strings.Join(pages, "\n\n"), nil
+}
+
+// scanObjects parses every "N G obj" in file order, so objects redefined by
+// incremental updates keep their last definition.
+func (f *pdfFile) scanObjects(data []byte) {
+	for _, m := range objHeaderRe.FindAllSubmatchIndex(data, -1) {
+		if m[0] > 0 && !isPDFDelimiter(data[m[0]-1]) && !isPDFSpace(data[m[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		lx := &pdfLexer{b: data, pos: m[1]}
+		v, err := lx.value()
+		if err != nil {
+			continue
+		}
+		if dict, ok := v.(pdfDict); ok {
+			if raw, ok := lx.streamData(dict); ok {
+				v = &pdfStream{dict: dict, raw: raw}
+			}
+		}
+		f.objects[num] = v
+	}
+}
+
+// expandObjectStreams adds the objects packed in /Type /ObjStm streams.
+func (f *pdfFile) expandObjectStreams() {
+	var streams []*pdfStream
+	for _, v := range f.objects {
+		if s, ok := v.(*pdfStream); ok && s.dict["Type"] == pdfName("ObjStm") {
+			streams = append(streams, s)
+		}
+	}
+	for _, s := range streams {
+		data, err := f.decode(s)
+		if err != nil {
+			continue
+		}
+		n, _ := f.resolve(s.dict["N"]).(float64)
+		first, _ := f.resolve(s.dict["First"]).(float64)
+		header := &pdfLexer{b: data}
+		for i := 0; i < int(n); i++ {
+			num, err1 := header.value()
+			off, err2 := header.value()
+			numF, ok1 := num.(float64)
+			offF, ok2 := off.(float64)
+			if err1 != nil || err2 != nil || !ok1 || !ok2 {
+				break
+			}
+			if _, exists := f.objects[int(numF)]; exists {
+				continue
+			}
+			pos := first + offF
+			if !(pos >= 0 && pos < float64(len(data))) {
+				continue // offset outside the stream, or not finite
+			}
+			lx := &pdfLexer{b: data, pos: int(pos)}
+			if v, err := lx.value(); err == nil {
+				f.objects[int(numF)] = v
+			}
+		}
+	}
+}
+
+// resolve follows references.
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+func (f *pdfFile) dict(v interface{}) pdfDict {
+	switch d := f.resolve(v).(type) {
+	case pdfDict:
+		return d
+	case *pdfStream:
+		return d.dict
+	}
+	return nil
+}
+
+// pdfPage is a page dictionary with its inherited resources.
+type pdfPage struct {
+	dict      pdfDict
+	resources pdfDict
+}
+
+// pages returns the pages in document order by walking the page tree from
+// the catalog, or every page object in object order if there is no catalog.
+func (f *pdfFile) pages() []pdfPage {
+	for _, v := range f.objects {
+		if d, ok := v.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+			var pages []pdfPage
+			f.walkPages(d["Pages"], nil, &pages, 0)
+			if len(pages) > 0 {
+				return pages
+			}
+		}
+	}
+
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	var pages []pdfPage
+	for _, num := range nums {
+		if d, ok := f.objects[num].(pdfDict); ok && d["Type"] == pdfName("Page") {
+			pages = append(pages, pdfPage{dict: d, resources: f.dict(d["Resources"])})
+		}
+	}
+	return pages
+}
+
+func (f *pdfFile) walkPages(node interface{}, inherited pdfDict, pages *[]pdfPage, depth int) {
+	d := f.dict(node)
+	if d == nil || depth > 64 {
+		return
+	}
+	res := inherited
+	if r := f.dict(d["Resources"]); r != nil {
+		res = r
+	}
+	if d["Type"] == pdfName("Page") {
+		*pages = append(*pages, pdfPage{dict: d, resources: res})
+		return
+	}
+	kids, _ := f.resolve(d["Kids"]).(pdfArray)
+	for _, kid := range kids {
+		f.walkPages(kid, res, pages, depth+1)
+	}
+}
+
+// decode returns the stream's data with its filters applied.
+func (f *pdfFile) decode(s *pdfStream) ([]byte, error) {
+	var filters []interface{}
+	switch v := f.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{v}
+	case pdfArray:
+		filters = v
+	}
+	data := s.raw
+	for _, filter := range filters {
+		switch f.resolve(filter) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			out, err := io.ReadAll(zr)
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		default:
+			return nil, fmt.Errorf("unsupported PDF filter %v", filter)
+		}
+	}
+	return data, nil
+}
+
+// pageText runs the page's content streams and returns the shown text.
+func (f *pdfFile) pageText(page pdfPage) string {
+	var content []byte
+	contents := f.resolve(page.dict["Contents"])
+	var streams []interface{}
+	if arr, ok := contents.(pdfArray); ok {
+		streams = arr
+	} else {
+		streams = []interface{}{contents}
+	}
+	for _, v := range streams {
+		s, ok := f.resolve(v).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := f.decode(s)
+		if err != nil {
+			continue
+		}
+		content = append(content, data...)
+		content = append(content, '\n')
+	}
+
+	fonts := f.dict(page.resources["Font"])
+	tw := &pdfTextWriter{}
+	var font *pdfFont
+	var operands []interface{}
+	lx := &pdfLexer{b: content}
+	for {
+		v, err := lx.value()
+		if err != nil {
+			break
+		}
+		op, ok := v.(pdfKeyword)
+		if !ok {
+			operands = append(operands, v)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					font = f.font(fonts[string(name)])
+				}
+			}
+		case "Tj":
+			if s, ok := lastOperand(operands).(pdfString); ok {
+				tw.show(font.decode(s))
+			}
+		case "'", `"`:
+			tw.newline()
+			if s, ok := lastOperand(operands).(pdfString); ok {
+				tw.show(font.decode(s))
+			}
+		case "TJ":
+			arr, _ := lastOperand(operands).(pdfArray)
+			for _, item := range arr {
+				switch it := item.(type) {
+				case pdfString:
+					tw.show(font.decode(it))
+				case float64:
+					// Kerning in thousandths of an em; a large gap is a space
+					if it < -250 {
+						tw.space()
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				tx, _ := operands[len(operands)-2].(float64)
+				ty, _ := operands[len(operands)-1].(float64)
+				tw.move(tx, ty)
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				x, _ := operands[len(operands)-2].(float64)
+				y, _ := operands[len(operands)-1].(float64)
+				tw.moveTo(x, y)
+			}
+		case "T*":
+			tw.newline()
+		case "BI":
+			lx.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return tw.String()
+}
+
+func lastOperand(operands []interface{}) interface{} {
+	if len(operands) == 0 {
+		return nil
+	}
+	return operands[len(operands)-1]
+}
+
+// pdfTextWriter lays shown strings out as lines. A vertical move starts a
+// new line; a horizontal move on the same line inserts a space.
+type pdfTextWriter struct {
+	b       strings.Builder
+	x, y    float64
+	pending string // separator to write before the next shown text
+}
+
+func (w *pdfTextWriter) show(s string) {
+	if s == "" {
+		return
+	}
+	if w.b.Len() > 0 {
+		w.b.WriteString(w.pending)
+	}
+	w.pending = ""
+	w.b.WriteString(s)
+}
+
+func (w *pdfTextWriter) newline() {
+	w.pending = "\n"
+}
+
+func (w *pdfTextWriter) space() {
+	if w.pending == "" {
+		w.pending = " "
+	}
+}
+
+func (w *pdfTextWriter) move(tx, ty float64) {
+	w.moveTo(w.x+tx, w.y+ty)
+}
+
+func (w *pdfTextWriter) moveTo(x, y float64) {
+	switch {
+	case y != w.y:
+		w.newline()
+	case x != w.x:
+		w.space()
+	}
+	w.x, w.y = x, y
+}
+
+// String returns the text with trailing spaces and space runs removed.
+func (w *pdfTextWriter) String() string {
+	lines := strings.Split(w.b.String(), "\n")
+	var out []string
+	for _, line := range lines {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// pdfFont decodes the bytes of shown strings to text.
+type pdfFont struct {
+	codeLen int               // bytes per character code
+	toUni   map[uint32]string // from the ToUnicode CMap (nil = none)
+}
+
+// font returns the decoder for a font dictionary, cached by reference.
+func (f *pdfFile) font(v interface{}) *pdfFont {
+	ref, isRef := v.(pdfRef)
+	if isRef {
+		if font, ok := f.fonts[ref]; ok {
+			return font
+		}
+	}
+	font := &pdfFont{codeLen: 1}
+	if d := f.dict(v); d != nil {
+		if d["Subtype"] == pdfName("Type0") {
+			font.codeLen = 2
+		}
+		if s, ok := f.resolve(d["ToUnicode"]).(*pdfStream); ok {
+			if data, err := f.decode(s); err == nil {
+				font.parseCMap(data)
+			}
+		}
+	}
+	if isRef {
+		f.fonts[ref] = font
+	}
+	return font
+}
+
+// parseCMap reads the codespace, bfchar and bfrange sections of a ToUnicode CMap.
+func (font *pdfFont) parseCMap(data []byte) {
+	font.toUni = make(map[uint32]string)
+	lx := &pdfLexer{b: data}
+	var operands []interface{}
+	section := ""
+	for {
+		v, err := lx.value()
+		if err != nil {
+			return
+		}
+		kw, ok := v.(pdfKeyword)
+		if !ok {
+			if section != "" {
+				operands = append(operands, v)
+			}
+			continue
+		}
+		switch kw {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			section = string(kw)
+			operands = operands[:0]
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if s, ok := operands[0].(pdfString); ok && len(s) > 0 {
+					font.codeLen = len(s)
+				}
+			}
+			section = ""
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, _ := operands[i].(pdfString)
+				dst, _ := operands[i+1].(pdfString)
+				font.toUni[codeOf(src)] = utf16BE(dst)
+			}
+			section = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, _ := operands[i].(pdfString)
+				hi, _ := operands[i+1].(pdfString)
+				start, end := codeOf(lo), codeOf(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []rune(utf16BE(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for c := start; c <= end; c++ {
+						r := append([]rune(nil), base...)
+						r[len(r)-1] += rune(c - start)
+						font.toUni[c] = string(r)
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok && start+uint32(j) <= end {
+							font.toUni[start+uint32(j)] = utf16BE(s)
+						}
+					}
+				}
+			}
+			section = ""
+		}
+	}
+}
+
+// decode maps the bytes of a shown string to text. Without a ToUnicode map,
+// single-byte codes are read as WinAnsi (Latin-1 with typographic quotes
+// and dashes) and multi-byte codes are dropped.
+func (font *pdfFont) decode(s pdfString) string {
+	if font == nil {
+		font = &pdfFont{codeLen: 1}
+	}
+	var b strings.Builder
+	n := font.codeLen
+	for i := 0; i+n <= len(s); i += n {
+		code := codeOf(s[i : i+n])
+		if font.toUni != nil {
+			if t, ok := font.toUni[code]; ok {
+				b.WriteString(t)
+				continue
+			}
+		}
+		if n == 1 {
+			b.WriteRune(winAnsiRune(byte(code)))
+		}
+	}
+	return b.String()
+}
+
+func codeOf(s pdfString) uint32 {
+	var c uint32
+	for i := 0; i < len(s); i++ {
+		c = c<<8 | uint32(s[i])
+	}
+	return c
+}
+
+// utf16BE decodes a UTF-16BE string, as used for ToUnicode destinations.
+func utf16BE(s pdfString) string {
+	u := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		u = append(u, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+var winAnsiSpecials = map[byte]rune{
+	0x80: '€', 0x85: '…', 0x91: '‘', 0x92: '’', 0x93: '“', 0x94: '”',
+	0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™',
+}
+
+func winAnsiRune(c byte) rune {
+	if r, ok := winAnsiSpecials[c]; ok {
+		return r
+	}
+	return rune(c)
+}
+
+// pdfLexer reads PDF values and keywords from b.
+type pdfLexer struct {
+	b   []byte
+	pos int
+}
+
+var errPDFEOF = errors.New("unexpected end of PDF data")
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (lx *pdfLexer) skipSpace() {
+	for lx.pos < len(lx.b) {
+		c := lx.b[lx.pos]
+		if c == '%' {
+			for lx.pos < len(lx.b) && lx.b[lx.pos] != '\n' && lx.b[lx.pos] != '\r' {
+				lx.pos++
+			}
+			continue
+		}
+		if !isPDFSpace(c) {
+			return
+		}
+		lx.pos++
+	}
+}
+
+// value reads the next value. References ("1 0 R") are returned as pdfRef;
+// operators and other bare words as pdfKeyword.
+func (lx *pdfLexer) value() (interface{}, error) {
+	lx.skipSpace()
+	if lx.pos >= len(lx.b) {
+		return nil, errPDFEOF
+	}
+	switch c := lx.b[lx.pos]; {
+	case c == '/':
+		return lx.name(), nil
+	case c == '(':
+		return lx.literalString(), nil
+	case c == '<' && lx.pos+1 < len(lx.b) && lx.b[lx.pos+1] == '<':
+		return lx.dictionary()
+	case c == '<':
+		return lx.hexString(), nil
+	case c == '[':
+		lx.pos++
+		var arr pdfArray
+		for {
+			lx.skipSpace()
+			if lx.pos >= len(lx.b) {
+				return nil, errPDFEOF
+			}
+			if lx.b[lx.pos] == ']' {
+				lx.pos++
+				return arr, nil
+			}
+			v, err := lx.value()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		lx.pos++
+		return pdfKeyword(string(c)), nil
+	}
+
+	word := lx.word()
+	if n, err := strconv.ParseFloat(word, 64); err == nil {
+		// "num gen R" is a reference
+		save := lx.pos
+		lx.skipSpace()
+		gen := lx.word()
+		lx.skipSpace()
+		if _, err := strconv.Atoi(gen); err == nil && lx.pos < len(lx.b) && lx.b[lx.pos] == 'R' &&
+			(lx.pos+1 == len(lx.b) || isPDFSpace(lx.b[lx.pos+1]) || isPDFDelimiter(lx.b[lx.pos+1])) {
+			lx.pos++
+			g, _ := strconv.Atoi(gen)
+			return pdfRef{num: int(n), gen: g}, nil
+		}
+		lx.pos = save
+		return n, nil
+	}
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+// word reads a run of regular characters.
+func (lx *pdfLexer) word() string {
+	start := lx.pos
+	for lx.pos < len(lx.b) && !isPDFSpace(lx.b[lx.pos]) && !isPDFDelimiter(lx.b[lx.pos]) {
+		lx.pos++
+	}
+	if lx.pos == start && lx.pos < len(lx.b) {
+		lx.pos++ // never stall on a stray delimiter
+	}
+	return string(lx.b[start:lx.pos])
+}
+
+func (lx *pdfLexer) name() pdfName {
+	lx.pos++ // '/'
+	start := lx.pos
+	for lx.pos < len(lx.b) && !isPDFSpace(lx.b[lx.pos]) && !isPDFDelimiter(lx.b[lx.pos]) {
+		lx.pos++
+	}
+	raw := string(lx.b[start:lx.pos])
+	if !strings.Contains(raw, "#") {
+		return pdfName(raw)
+	}
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if v, err := strconv.ParseUint(raw[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(raw[i])
+	}
+	return pdfName(b.String())
+}
+
+func (lx *pdfLexer) literalString() pdfString {
+	lx.pos++ // '('
+	var b []byte
+	depth := 1
+	for lx.pos < len(lx.b) {
+		c := lx.b[lx.pos]
+		lx.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(b)
+			}
+		case '\\':
+			if lx.pos >= len(lx.b) {
+				return pdfString(b)
+			}
+			e := lx.b[lx.pos]
+			lx.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if lx.pos < len(lx.b) && lx.b[lx.pos] == '\n' {
+					lx.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for k := 0; k < 2 && lx.pos < len(lx.b) && lx.b[lx.pos] >= '0' && lx.b[lx.pos] <= '7'; k++ {
+						v = v*8 + int(lx.b[lx.pos]-'0')
+						lx.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return pdfString(b)
+}
+
+func (lx *pdfLexer) hexString() pdfString {
+	lx.pos++ // '<'
+	var digits []byte
+	for lx.pos < len(lx.b) && lx.b[lx.pos] != '>' {
+		if c := lx.b[lx.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		lx.pos++
+	}
+	lx.pos++ // '>'
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			continue
+		}
+		out = append(out, byte(v))
+	}
+	return pdfString(out)
+}
+
+func (lx *pdfLexer) dictionary() (pdfDict, error) {
+	lx.pos += 2 // "<<"
+	d := make(pdfDict)
+	for {
+		lx.skipSpace()
+		if lx.pos+1 >= len(lx.b) {
+			return nil, errPDFEOF
+		}
+		if lx.b[lx.pos] == '>' && lx.b[lx.pos+1] == '>' {
+			lx.pos += 2
+			return d, nil
+		}
+		key, err := lx.value()
+		if err != nil {
+			return nil, err
+		}
+		name, ok := key.(pdfName)
+		if !ok {
+			continue // skip malformed entries
+		}
+		v, err := lx.value()
+		if err != nil {
+			return nil, err
+		}
+		d[string(name)] = v
+	}
+}
+
+// streamData reads the stream body following a stream dictionary, if any.
+func (lx *pdfLexer) streamData(dict pdfDict) ([]byte, bool) {
+	lx.skipSpace()
+	if !bytes.HasPrefix(lx.b[lx.pos:], []byte("stream")) {
+		return nil, false
+	}
+	start := lx.pos + len("stream")
+	if start < len(lx.b) && lx.b[start] == '\r' {
+		start++
+	}
+	if start < len(lx.b) && lx.b[start] == '\n' {
+		start++
+	}
+	// Negative, non-finite or past-the-end lengths fall through to the scan
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && n <= float64(len(lx.b)-start) {
+		end := start + int(n)
+		rest := bytes.TrimLeft(lx.b[end:], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			return lx.b[start:end], true
+		}
+	}
+	// Indirect or wrong /Length: scan for the end marker
+	end := bytes.Index(lx.b[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, false
+	}
+	return bytes.TrimRight(lx.b[start:start+end], "\r\n"), true
+}
+
+// skipInlineImage skips the data of an inline image (BI ... ID data EI).
+func (lx *pdfLexer) skipInlineImage() {
+	id := bytes.Index(lx.b[lx.pos:], []byte("ID"))
+	if id < 0 {
+		lx.pos = len(lx.b)
+		return
+	}
+	lx.pos += id + 2
+	for lx.pos+2 <= len(lx.b) {
+		if lx.b[lx.pos] == 'E' && lx.b[lx.pos+1] == 'I' && isPDFSpace(lx.b[lx.pos-1]) &&
+			(lx.pos+2 == len(lx.b) || isPDFSpace(lx.b[lx.pos+2])) {
+			lx.pos += 2
+			return
+		}
+		lx.pos++
+	}
+	lx.pos = len(lx.b)
+}
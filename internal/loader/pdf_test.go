@@ -0,0 +1,65 @@
+package loader
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPDF(t *testing.T) {
+	// Page 1 uses a WinAnsi font in a plain stream; page 2 a composite font
+	// with a ToUnicode map in a Flate stream. The page tree and fonts live
+	// in a compressed object stream.
+	docs := loadFixture(t, Default(DefaultOptions()), "manual.pdf")
+
+	want := "API Keys\nRotate every 90 days.\n\nHi\nABC H"
+	if len(docs) != 1 || docs[0].Content != want {
+		t.Errorf("PDF content = %q, want %q", docs[0].Content, want)
+	}
+}
+
+func TestPDF_Errors(t *testing.T) {
+	if _, err := PDF.Load("a.pdf", []byte("plain text")); err == nil {
+		t.Error("expected error for non-PDF data")
+	}
+	encrypted := []byte("%PDF-1.4\ntrailer << /Root 1 0 R /Encrypt 2 0 R >>")
+	if _, err := PDF.Load("a.pdf", encrypted); !errors.Is(err, ErrPDFEncrypted) {
+		t.Errorf("expected ErrPDFEncrypted, got %v", err)
+	}
+}
+
+func TestPDF_Malformed(t *testing.T) {
+	page := "1 0 obj << /Type /Page /Contents 2 0 R >> endobj\n"
+	content := "BT (Hello) Tj ET"
+	tests := map[string]string{
+		"negative length": page + "2 0 obj << /Length -100000 >> stream\n" + content + "\nendstream endobj",
+		"huge length":     page + "2 0 obj << /Length 1e300 >> stream\n" + content + "\nendstream endobj",
+		"wrong length":    page + "2 0 obj << /Length 5000 >> stream\n" + content + "\nendstream endobj",
+		"negative first":  page + "2 0 obj << /Length 16 >> stream\n" + content + "\nendstream endobj\n" + "3 0 obj << /Type /ObjStm /N 1 /First -500 >> stream\n4 0 << /A 1 >>\nendstream endobj",
+		"offset past end": page + "2 0 obj << /Length 16 >> stream\n" + content + "\nendstream endobj\n" + "3 0 obj << /Type /ObjStm /N 1 /First 4 >> stream\n4 900 << /A 1 >>\nendstream endobj",
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			docs, err := PDF.Load("a.pdf", []byte("%PDF-1.4\n"+body))
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if docs[0].Content != "Hello" {
+				t.Errorf("content = %q, want %q", docs[0].Content, "Hello")
+			}
+		})
+	}
+}
+
+func TestPDFLexer_Strings(t *testing.T) {
+	lx := &pdfLexer{b: []byte(`(a\(b\) \101\nc) <48 65 6C6C6F> /A#20B 3 0 R`)}
+	want := []interface{}{pdfString("a(b) A\nc"), pdfString("Hello"), pdfName("A B"), pdfRef{num: 3}}
+	for i, w := range want {
+		v, err := lx.value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v != w {
+			t.Errorf("value %d = %#v, want %#v", i, v, w)
+		}
+	}
+}
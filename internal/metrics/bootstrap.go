@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// BootstrapConfidence is the confidence level of bootstrap intervals.
+const BootstrapConfidence = 0.95
+
+// maxJackknifeGroups caps the leave-out groups used to estimate the BCa
+// acceleration, so large query sets do not cost a Compute per query.
+const maxJackknifeGroups = 200
+
+// Interval is the bootstrap distribution of one metric.
+type Interval struct {
+	Estimate float64 `json:"estimate"` // Metric on all queries
+	Mean     float64 `json:"mean"`     // Mean over resamples
+	Std      float64 `json:"std"`      // Standard deviation over resamples (standard error)
+	// Percentile interval: the resample quantiles around the confidence level.
+	PercentileLo float64 `json:"percentile_lo"`
+	PercentileHi float64 `json:"percentile_hi"`
+	// BCa interval: percentiles corrected for bias and skew, which keeps
+	// coverage closer to nominal for bounded metrics such as recall near 1.
+	BCaLo float64 `json:"bca_lo"`
+	BCaHi float64 `json:"bca_hi"`
+}
+
+// BootstrapResult holds bootstrap estimates for the metrics of Compute.
+// The flat fields repeat the headline metrics, with BCa bounds as CI95.
+type BootstrapResult struct {
+	N          int   `json:"n"`           // Resamples
+	SampleSize int   `json:"sample_size"` // Queries per resample
+	Seed       int64 `json:"seed"`
+
+	RecallMean   float64 `json:"recall_mean"`
+	RecallStd    float64 `json:"recall_std"`
+	RecallCI95Lo float64 `json:"recall_ci95_lo"`
+	RecallCI95Hi float64 `json:"recall_ci95_hi"`
+	MRRMean      float64 `json:"mrr_mean"`
+	MRRStd       float64 `json:"mrr_std"`
+	MRRCI95Lo    float64 `json:"mrr_ci95_lo"`
+	MRRCI95Hi    float64 `json:"mrr_ci95_hi"`
+	NDCGMean     float64 `json:"ndcg_mean"`
+	NDCGStd      float64 `json:"ndcg_std"`
+	CoverageMean float64 `json:"coverage_mean"`
+	CoverageStd  float64 `json:"coverage_std"`
+
+	// Intervals holds every metric of Result by its JSON name, e.g.
+	// "recall_at_k" or "latency_p95_ms".
+	Intervals map[string]Interval `json:"intervals"`
+}
+
+// bootstrapMetrics lists the metrics of Result by JSON name.
+var bootstrapMetrics = []struct {
+	name  string
+	value func(Result) float64
+}{
+	{"recall_at_k", func(r Result) float64 { return r.RecallAtK }},
+	{"mrr", func(r Result) float64 { return r.MRR }},
+	{"ndcg_at_k", func(r Result) float64 { return r.NDCGAtK }},
+	{"coverage", func(r Result) float64 { return r.Coverage }},
+	{"redundancy", func(r Result) float64 { return r.Redundancy }},
+	{"diversity_at_k", func(r Result) float64 { return r.DiversityAtK }},
+	{"err_at_k", func(r Result) float64 { return r.ERRAtK }},
+	{"graded_precision_at_k", func(r Result) float64 { return r.GradedPrecisionAtK }},
+	{"latency_p50_ms", func(r Result) float64 { return r.LatencyP50 }},
+	{"latency_p95_ms", func(r Result) float64 { return r.LatencyP95 }},
+	{"latency_p99_ms", func(r Result) float64 { return r.LatencyP99 }},
+	{"latency_avg_ms", func(r Result) float64 { return r.LatencyAvg }},
+}
+
+// Bootstrap estimates the sampling distribution of the metrics by
+// recomputing them on n resamples of the queries, drawn with replacement.
+// sampleSize sets the queries per resample (0 = all); BCa intervals assume
+// all, and equal the percentile intervals otherwise.
+//
+// Resample i draws from its own generator seeded by (seed, i), so results
+// depend only on the seed, and parallel resampling across CPUs returns the
+// same intervals as sequential.
+func Bootstrap(results []QueryResult, k, n, sampleSize int, seed int64, parallel bool) BootstrapResult {
+	if len(results) == 0 || n <= 0 {
+		return BootstrapResult{}
+	}
+	if sampleSize <= 0 {
+		sampleSize = len(results)
+	}
+
+	stats := make([]Result, n)
+	resample := func(i int) {
+		rng := rand.New(rand.NewPCG(uint64(seed), uint64(i)))
+		sample := make([]QueryResult, sampleSize)
+		for j := range sample {
+			sample[j] = results[rng.IntN(len(results))]
+		}
+		stats[i] = Compute(sample, k)
+	}
+	forEach(n, parallel, resample)
+
+	estimate := Compute(results, k)
+	var jackknife []Result
+	if sampleSize == len(results) {
+		jackknife = jackknifeResults(results, k, parallel)
+	}
+
+	out := BootstrapResult{
+		N:          n,
+		SampleSize: sampleSize,
+		Seed:       seed,
+		Intervals:  make(map[string]Interval, len(bootstrapMetrics)),
+	}
+	values := make([]float64, n)
+	for _, m := range bootstrapMetrics {
+		for i, s := range stats {
+			values[i] = m.value(s)
+		}
+		var jack []float64
+		for _, r := range jackknife {
+			jack = append(jack, m.value(r))
+		}
+		out.Intervals[m.name] = interval(m.value(estimate), values, jack)
+	}
+
+	recall, mrr := out.Intervals["recall_at_k"], out.Intervals["mrr"]
+	ndcg, coverage := out.Intervals["ndcg_at_k"], out.Intervals["coverage"]
+	out.RecallMean, out.RecallStd = recall.Mean, recall.Std
+	out.RecallCI95Lo, out.RecallCI95Hi = recall.BCaLo, recall.BCaHi
+	out.MRRMean, out.MRRStd = mrr.Mean, mrr.Std
+	out.MRRCI95Lo, out.MRRCI95Hi = mrr.BCaLo, mrr.BCaHi
+	out.NDCGMean, out.NDCGStd = ndcg.Mean, ndcg.Std
+	out.CoverageMean, out.CoverageStd = coverage.Mean, coverage.Std
+	return out
+}
+
+// forEach calls fn for 0..n-1, spread over the CPUs if parallel.
+func forEach(n int, parallel bool, fn func(i int)) {
+	workers := 1
+	if parallel {
+		workers = min(runtime.GOMAXPROCS(0), n)
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// jackknifeResults computes the metrics with each group of queries left
+// out: each query alone, or for large sets maxJackknifeGroups contiguous
+// groups.
+func jackknifeResults(results []QueryResult, k int, parallel bool) []Result {
+	groups := min(len(results), maxJackknifeGroups)
+	if groups < 2 {
+		return nil
+	}
+	out := make([]Result, groups)
+	forEach(groups, parallel, func(g int) {
+		lo, hi := g*len(results)/groups, (g+1)*len(results)/groups
+		rest := make([]QueryResult, 0, len(results)-(hi-lo))
+		rest = append(rest, results[:lo]...)
+		rest = append(rest, results[hi:]...)
+		out[g] = Compute(rest, k)
+	})
+	return out
+}
+
+// interval summarizes the resample values of a metric with the given
+// estimate on all queries and jackknife values (nil to skip BCa).
+func interval(estimate float64, values, jackknife []float64) Interval {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	alpha := (1 - BootstrapConfidence) / 2
+	iv := Interval{
+		Estimate:     estimate,
+		Mean:         mean(sorted),
+		Std:          stddev(sorted),
+		PercentileLo: quantile(sorted, alpha),
+		PercentileHi: quantile(sorted, 1-alpha),
+	}
+	iv.BCaLo, iv.BCaHi = iv.PercentileLo, iv.PercentileHi
+
+	// Bias correction: how far the estimate sits from the resample median
+	below := 0.0
+	for _, v := range sorted {
+		switch {
+		case v < estimate:
+			below++
+		case v == estimate:
+			below += 0.5
+		}
+	}
+	p := below / float64(len(sorted))
+	if jackknife == nil || p <= 0 || p >= 1 {
+		return iv // Degenerate: every resample on one side of the estimate
+	}
+	z0 := normalQuantile(p)
+
+	// Acceleration: skewness of the jackknife values
+	jm := mean(jackknife)
+	var num, den float64
+	for _, v := range jackknife {
+		d := jm - v
+		num += d * d * d
+		den += d * d
+	}
+	a := 0.0
+	if den > 0 {
+		a = num / (6 * math.Pow(den, 1.5))
+	}
+
+	adjust := func(q float64) float64 {
+		z := z0 + normalQuantile(q)
+		return normalCDF(z0 + z/(1-a*z))
+	}
+	iv.BCaLo = quantile(sorted, adjust(alpha))
+	iv.BCaHi = quantile(sorted, adjust(1-alpha))
+	return iv
+}
+
+// quantile returns the q-quantile of sorted values, interpolating linearly.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if math.IsNaN(q) {
+		q = 0.5
+	}
+	rank := math.Max(0, math.Min(1, q)) * float64(len(sorted)-1)
+	lower := int(rank)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	weight := rank - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[lower+1]*weight
+}
+
+func mean(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
+
+// stddev returns the sample standard deviation of vs.
+func stddev(vs []float64) float64 {
+	if len(vs) < 2 {
+		return 0
+	}
+	m := mean(vs)
+	var ss float64
+	for _, v := range vs {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(vs)-1))
+}
+
+// normalCDF is the standard normal cumulative distribution function.
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// normalQuantile is the inverse of normalCDF.
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
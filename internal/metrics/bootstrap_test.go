@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+// syntheticResults returns n queries with one relevant doc each, found at
+// a random rank up to 5 (recall@5 hit) with probability pHit.
+func syntheticResults(rng *rand.Rand, n int, pHit float64) []QueryResult {
+	results := make([]QueryResult, n)
+	for i := range results {
+		retrieved := []string{"x1", "x2", "x3", "x4", "x5"}
+		if rng.Float64() < pHit {
+			retrieved[rng.IntN(5)] = "rel"
+		}
+		results[i] = QueryResult{
+			QueryID:      fmt.Sprintf("q%d", i),
+			RetrievedIDs: retrieved,
+			RelevantIDs:  []string{"rel"},
+			LatencyMs:    10 + rng.Float64()*20,
+		}
+	}
+	return results
+}
+
+func TestBootstrap_Deterministic(t *testing.T) {
+	results := syntheticResults(rand.New(rand.NewPCG(1, 2)), 50, 0.6)
+
+	a := Bootstrap(results, 5, 200, 0, 42, false)
+	b := Bootstrap(results, 5, 200, 0, 42, false)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("same seed gave different results")
+	}
+	if p := Bootstrap(results, 5, 200, 0, 42, true); !reflect.DeepEqual(a, p) {
+		t.Error("parallel resampling differs from sequential")
+	}
+	if c := Bootstrap(results, 5, 200, 0, 7, false); c.RecallStd == a.RecallStd {
+		t.Error("different seeds gave identical resamples")
+	}
+}
+
+func TestBootstrap_Fields(t *testing.T) {
+	results := syntheticResults(rand.New(rand.NewPCG(3, 4)), 60, 0.7)
+	bs := Bootstrap(results, 5, 500, 0, 42, true)
+
+	if bs.N != 500 || bs.SampleSize != 60 || bs.Seed != 42 {
+		t.Errorf("N, SampleSize, Seed = %d, %d, %d", bs.N, bs.SampleSize, bs.Seed)
+	}
+	if len(bs.Intervals) != len(bootstrapMetrics) {
+		t.Errorf("got %d intervals, want %d", len(bs.Intervals), len(bootstrapMetrics))
+	}
+	for name, iv := range bs.Intervals {
+		if iv.PercentileLo > iv.PercentileHi || iv.BCaLo > iv.BCaHi {
+			t.Errorf("%s: reversed interval %+v", name, iv)
+		}
+		if iv.Std < 0 {
+			t.Errorf("%s: negative std %v", name, iv.Std)
+		}
+	}
+
+	recall := bs.Intervals["recall_at_k"]
+	if recall.Estimate != Compute(results, 5).RecallAtK {
+		t.Errorf("recall estimate %v differs from Compute", recall.Estimate)
+	}
+	if bs.RecallMean != recall.Mean || bs.RecallCI95Lo != recall.BCaLo || bs.RecallCI95Hi != recall.BCaHi {
+		t.Errorf("flat recall fields %+v do not match interval %+v", bs, recall)
+	}
+	if recall.BCaLo > recall.Estimate || recall.BCaHi < recall.Estimate {
+		t.Errorf("recall interval [%v, %v] excludes the estimate %v", recall.BCaLo, recall.BCaHi, recall.Estimate)
+	}
+
+	// The standard error of a mean of Bernoulli(p) hits is sqrt(p(1-p)/n)
+	p := recall.Estimate
+	if se := math.Sqrt(p * (1 - p) / 60); math.Abs(recall.Std-se) > 0.25*se {
+		t.Errorf("recall std = %v, want about %v", recall.Std, se)
+	}
+
+	if lat := bs.Intervals["latency_p95_ms"]; lat.Mean < 10 || lat.Mean > 30 {
+		t.Errorf("latency p95 mean = %v, want within the synthetic 10-30ms", lat.Mean)
+	}
+}
+
+func TestBootstrap_Degenerate(t *testing.T) {
+	results := make([]QueryResult, 10)
+	for i := range results {
+		results[i] = QueryResult{RetrievedIDs: []string{"a"}, RelevantIDs: []string{"a"}}
+	}
+	bs := Bootstrap(results, 5, 100, 0, 1, false)
+	recall := bs.Intervals["recall_at_k"]
+	if recall.Std != 0 || recall.BCaLo != 1 || recall.BCaHi != 1 || recall.PercentileLo != 1 {
+		t.Errorf("identical queries: %+v, want a zero-width interval at 1", recall)
+	}
+
+	if empty := Bootstrap(nil, 5, 100, 0, 1, false); empty.N != 0 || empty.Intervals != nil {
+		t.Errorf("no results: %+v, want zero", empty)
+	}
+}
+
+func TestBootstrap_SampleSize(t *testing.T) {
+	results := syntheticResults(rand.New(rand.NewPCG(5, 6)), 100, 0.5)
+	full := Bootstrap(results, 5, 400, 0, 42, false)
+	small := Bootstrap(results, 5, 400, 25, 42, false)
+
+	if small.SampleSize != 25 {
+		t.Errorf("SampleSize = %d, want 25", small.SampleSize)
+	}
+	// A quarter of the queries per resample doubles the standard error
+	if ratio := small.RecallStd / full.RecallStd; ratio < 1.6 || ratio > 2.4 {
+		t.Errorf("std ratio = %v, want about 2", ratio)
+	}
+	// BCa needs full-size resamples
+	recall := small.Intervals["recall_at_k"]
+	if recall.BCaLo != recall.PercentileLo || recall.BCaHi != recall.PercentileHi {
+		t.Errorf("BCa with sampleSize < n = %+v, want the percentile interval", recall)
+	}
+}
+
+// TestBootstrap_Coverage checks that 95% intervals contain the true metric
+// in about 95% of synthetic experiments.
+func TestBootstrap_Coverage(t *testing.T) {
+	experiments, resamples := 200, 300
+	if testing.Short() {
+		experiments = 60
+	}
+	const queries, pHit = 40, 0.7
+
+	// True values: recall is pHit; the hit rank is uniform on 1..5
+	trueRecall := pHit
+	trueMRR := pHit * (1 + 1.0/2 + 1.0/3 + 1.0/4 + 1.0/5) / 5
+
+	rng := rand.New(rand.NewPCG(2024, 10))
+	covered := map[string]int{}
+	for e := 0; e < experiments; e++ {
+		bs := Bootstrap(syntheticResults(rng, queries, pHit), 5, resamples, 0, int64(e), true)
+		for metric, truth := range map[string]float64{"recall_at_k": trueRecall, "mrr": trueMRR} {
+			iv := bs.Intervals[metric]
+			if iv.PercentileLo <= truth && truth <= iv.PercentileHi {
+				covered[metric+" percentile"]++
+			}
+			if iv.BCaLo <= truth && truth <= iv.BCaHi {
+				covered[metric+" bca"]++
+			}
+		}
+	}
+
+	// Binomial noise over 200 experiments is about ±3%; small samples undercover slightly
+	for _, name := range []string{"recall_at_k percentile", "recall_at_k bca", "mrr percentile", "mrr bca"} {
+		rate := float64(covered[name]) / float64(experiments)
+		if rate < 0.85 || rate > 0.995 {
+			t.Errorf("%s coverage = %.3f, want about %.2f", name, rate, BootstrapConfidence)
+		}
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4, 5}
+	tests := []struct {
+		q, want float64
+	}{
+		{0, 1}, {1, 5}, {0.5, 3}, {0.125, 1.5}, {-1, 1}, {2, 5},
+	}
+	for _, tt := range tests {
+		if got := quantile(sorted, tt.q); got != tt.want {
+			t.Errorf("quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestNormalQuantile(t *testing.T) {
+	for _, p := range []float64{0.025, 0.5, 0.9, 0.975} {
+		if got := normalCDF(normalQuantile(p)); math.Abs(got-p) > 1e-12 {
+			t.Errorf("normalCDF(normalQuantile(%v)) = %v", p, got)
+		}
+	}
+	if z := normalQuantile(0.975); math.Abs(z-1.959964) > 1e-6 {
+		t.Errorf("normalQuantile(0.975) = %v, want 1.959964", z)
+	}
+}
@@ -0,0 +1,213 @@
+package metrics
+
+import (
+	"sort"
+)
+
+// KPoint holds metrics averaged over queries at one cutoff k.
+type KPoint struct {
+	K         int     `json:"k"`
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+	F1        float64 `json:"f1"` // harmonic mean of Recall and Precision
+	MRR       float64 `json:"mrr"`
+	NDCG      float64 `json:"ndcg"`
+}
+
+// Curve computes metrics at each cutoff in ks from one set of results
+// retrieved at least max(ks) deep, so a range of k costs one search per
+// query. MRR counts only relevant documents within the top k.
+func Curve(results []QueryResult, ks []int) []KPoint {
+	if len(results) == 0 {
+		return nil
+	}
+	points := make([]KPoint, 0, len(ks))
+	n := float64(len(results))
+	for _, k := range ks {
+		p := KPoint{K: k}
+		for _, r := range results {
+			p.Recall += RecallAtK(r.RetrievedIDs, r.RelevantIDs, k)
+			p.Precision += PrecisionAtK(r.RetrievedIDs, r.RelevantIDs, k)
+			p.MRR += ReciprocalRank(firstK(r.RetrievedIDs, k), r.RelevantIDs)
+			p.NDCG += ndcg(r, k)
+		}
+		p.Recall /= n
+		p.Precision /= n
+		p.MRR /= n
+		p.NDCG /= n
+		p.F1 = f1(p.Precision, p.Recall)
+		points = append(points, p)
+	}
+	return points
+}
+
+// PrecisionAtK computes precision for a single query: the fraction of the
+// top-k slots holding a relevant document. Every chunk of a relevant
+// document counts, and missing results count as misses.
+func PrecisionAtK(retrieved, relevant []string, k int) float64 {
+	if len(relevant) == 0 {
+		return 1.0 // No relevant docs = nothing to get wrong, as for recall
+	}
+	if k <= 0 {
+		return 0
+	}
+
+	relevantSet := make(map[string]struct{})
+	for _, id := range relevant {
+		relevantSet[id] = struct{}{}
+	}
+
+	hits := 0
+	for _, id := range firstK(retrieved, k) {
+		if _, ok := relevantSet[id]; ok {
+			hits++
+		}
+	}
+	return float64(hits) / float64(k)
+}
+
+// ThresholdFit is a cutoff k and minimum score fitted to a set of results:
+// a query keeps its top-k results scoring at least MinScore.
+type ThresholdFit struct {
+	K        int     `json:"k"`
+	MinScore float64 `json:"min_score"`
+	// Precision is the fraction of all kept results that are relevant,
+	// over the queries that have relevant documents.
+	Precision float64 `json:"precision"`
+	// Recall is the mean fraction of each query's relevant documents kept.
+	Recall float64 `json:"recall"`
+	F1     float64 `json:"f1"`
+	// AvgResults is the mean number of results a query keeps.
+	AvgResults float64 `json:"avg_results"`
+	// TargetPrecision is the precision the fit had to reach; 0 when it
+	// maximized F1.
+	TargetPrecision float64 `json:"target_precision,omitempty"`
+}
+
+// FitThreshold finds the cutoff in ks and the minimum score that maximize
+// F1 or, if targetPrecision is positive, that maximize recall with at least
+// that precision. Results must be retrieved at least max(ks) deep, with
+// scores where higher is more similar. Ties go to the smaller k, then the
+// higher score. It returns false if no cutoff keeps a result or reaches the
+// target precision.
+func FitThreshold(results []QueryResult, ks []int, targetPrecision float64) (ThresholdFit, bool) {
+	var best ThresholdFit
+	found := false
+	better := func(c ThresholdFit) bool {
+		if targetPrecision > 0 {
+			if c.Precision < targetPrecision {
+				return false
+			}
+			return !found || c.Recall > best.Recall
+		}
+		return !found || c.F1 > best.F1
+	}
+
+	for _, k := range sortedKs(ks) {
+		for _, c := range thresholdSweep(results, k) {
+			if better(c) {
+				best, found = c, true
+			}
+		}
+	}
+	best.TargetPrecision = targetPrecision
+	return best, found
+}
+
+// scoredResult is one of a query's top-k results.
+type scoredResult struct {
+	query int
+	id    string
+	score float32
+	hit   bool
+}
+
+// thresholdSweep returns the fit at cutoff k for each distinct score of the
+// top-k results, highest first, lowering the threshold one score at a time.
+func thresholdSweep(results []QueryResult, k int) []ThresholdFit {
+	if len(results) == 0 {
+		return nil
+	}
+
+	var items []scoredResult
+	relevant := make([]map[string]struct{}, len(results))
+	var recall float64 // sum of per-query recall at the current threshold
+	for q, r := range results {
+		relevant[q] = make(map[string]struct{})
+		for _, id := range r.RelevantIDs {
+			relevant[q][id] = struct{}{}
+		}
+		if len(relevant[q]) == 0 {
+			// No relevant docs = perfect recall, as for RecallAtK; its
+			// results are neither hits nor misses
+			recall++
+			continue
+		}
+		for i, id := range firstK(r.RetrievedIDs, k) {
+			if i >= len(r.Scores) {
+				break
+			}
+			_, hit := relevant[q][id]
+			items = append(items, scoredResult{query: q, id: id, score: r.Scores[i], hit: hit})
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].score > items[j].score
+	})
+
+	n := float64(len(results))
+	found := make([]map[string]struct{}, len(results))
+	kept, hits := 0, 0
+	var fits []ThresholdFit
+	for i := 0; i < len(items); {
+		// Keep every result with this score
+		score := items[i].score
+		for ; i < len(items) && items[i].score == score; i++ {
+			it := items[i]
+			kept++
+			if !it.hit {
+				continue
+			}
+			hits++
+			if found[it.query] == nil {
+				found[it.query] = make(map[string]struct{})
+			}
+			if _, seen := found[it.query][it.id]; !seen {
+				found[it.query][it.id] = struct{}{}
+				recall += 1 / float64(len(relevant[it.query]))
+			}
+		}
+		precision := float64(hits) / float64(kept)
+		fits = append(fits, ThresholdFit{
+			K:          k,
+			MinScore:   float64(score),
+			Precision:  precision,
+			Recall:     recall / n,
+			F1:         f1(precision, recall/n),
+			AvgResults: float64(kept) / n,
+		})
+	}
+	return fits
+}
+
+// firstK returns the first k of ids.
+func firstK(ids []string, k int) []string {
+	if len(ids) > k {
+		return ids[:k]
+	}
+	return ids
+}
+
+// sortedKs returns ks in ascending order.
+func sortedKs(ks []int) []int {
+	sorted := append([]int(nil), ks...)
+	sort.Ints(sorted)
+	return sorted
+}
+
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
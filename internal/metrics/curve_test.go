@@ -0,0 +1,142 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestPrecisionAtK(t *testing.T) {
+	tests := []struct {
+		name      string
+		retrieved []string
+		relevant  []string
+		k         int
+		want      float64
+	}{
+		{"all relevant", []string{"a", "b"}, []string{"a", "b"}, 2, 1.0},
+		{"half", []string{"a", "x", "b", "y"}, []string{"a", "b"}, 4, 0.5},
+		{"chunks of one doc count each", []string{"a", "a", "x"}, []string{"a"}, 3, 2.0 / 3},
+		{"missing results are misses", []string{"a"}, []string{"a"}, 4, 0.25},
+		{"beyond k ignored", []string{"x", "a"}, []string{"a"}, 1, 0},
+		{"no relevant docs", []string{"x"}, nil, 3, 1.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrecisionAtK(tt.retrieved, tt.relevant, tt.k); !approx(got, tt.want) {
+				t.Errorf("PrecisionAtK() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurve(t *testing.T) {
+	results := []QueryResult{
+		{RetrievedIDs: []string{"a", "x", "b"}, RelevantIDs: []string{"a", "b"}},
+		{RetrievedIDs: []string{"x", "c", "y"}, RelevantIDs: []string{"c"}},
+	}
+	curve := Curve(results, []int{1, 3})
+	if len(curve) != 2 {
+		t.Fatalf("got %d points, want 2", len(curve))
+	}
+
+	at1, at3 := curve[0], curve[1]
+	if at1.K != 1 || !approx(at1.Recall, 0.25) || !approx(at1.Precision, 0.5) || !approx(at1.MRR, 0.5) {
+		t.Errorf("k=1: %+v, want recall 0.25, precision 0.5, mrr 0.5", at1)
+	}
+	if !approx(at3.Recall, 1) || !approx(at3.Precision, 0.5) || !approx(at3.MRR, 0.75) {
+		t.Errorf("k=3: %+v, want recall 1, precision 0.5, mrr 0.75", at3)
+	}
+	if !approx(at3.F1, 2*0.5*1/1.5) {
+		t.Errorf("k=3: F1 = %v, want %v", at3.F1, 2*0.5*1/1.5)
+	}
+	// Each point matches a search at that depth
+	for _, p := range curve {
+		m := Compute(truncate(results, p.K), p.K)
+		if !approx(p.Recall, m.RecallAtK) || !approx(p.NDCG, m.NDCGAtK) || !approx(p.MRR, m.MRR) {
+			t.Errorf("k=%d: curve %+v differs from Compute %+v", p.K, p, m)
+		}
+	}
+}
+
+// truncate returns the results cut to their top k.
+func truncate(results []QueryResult, k int) []QueryResult {
+	out := make([]QueryResult, len(results))
+	for i, r := range results {
+		r.RetrievedIDs = firstK(r.RetrievedIDs, k)
+		out[i] = r
+	}
+	return out
+}
+
+// scoredQueries has relevant results scoring at least 0.7 and irrelevant
+// ones below, except one irrelevant 0.75 in q2.
+func scoredQueries() []QueryResult {
+	return []QueryResult{
+		{
+			RetrievedIDs: []string{"a", "b", "x", "y"},
+			RelevantIDs:  []string{"a", "b"},
+			Scores:       []float32{0.9, 0.8, 0.5, 0.4},
+		},
+		{
+			RetrievedIDs: []string{"z", "c", "w", "v"},
+			RelevantIDs:  []string{"c"},
+			Scores:       []float32{0.75, 0.7, 0.3, 0.2},
+		},
+	}
+}
+
+func TestFitThreshold_F1(t *testing.T) {
+	fit, ok := FitThreshold(scoredQueries(), []int{1, 2, 3, 4}, 0)
+	if !ok {
+		t.Fatal("no fit found")
+	}
+	// k=2 with min_score 0.7 keeps a, b, z, c: precision 3/4, recall 1
+	if fit.K != 2 || !approx(fit.MinScore, float64(float32(0.7))) {
+		t.Errorf("fit = k=%d, min_score=%v; want k=2, min_score=0.7", fit.K, fit.MinScore)
+	}
+	if !approx(fit.Precision, 0.75) || !approx(fit.Recall, 1) || !approx(fit.AvgResults, 2) {
+		t.Errorf("fit = %+v, want precision 0.75, recall 1, 2 results per query", fit)
+	}
+	if fit.TargetPrecision != 0 {
+		t.Errorf("TargetPrecision = %v, want 0 for an F1 fit", fit.TargetPrecision)
+	}
+}
+
+func TestFitThreshold_TargetPrecision(t *testing.T) {
+	fit, ok := FitThreshold(scoredQueries(), []int{1, 2, 3, 4}, 1.0)
+	if !ok {
+		t.Fatal("no fit found")
+	}
+	// Perfect precision allows a and b but not z at 0.75
+	if fit.Precision != 1 || !approx(fit.Recall, 0.5) || !approx(fit.MinScore, float64(float32(0.8))) {
+		t.Errorf("fit = %+v, want precision 1, recall 0.5 at min_score 0.8", fit)
+	}
+	// Smallest k reaching it
+	if fit.K != 2 {
+		t.Errorf("fit.K = %d, want 2", fit.K)
+	}
+
+	none := []QueryResult{{RetrievedIDs: []string{"x"}, RelevantIDs: []string{"a"}, Scores: []float32{0.9}}}
+	if _, ok := FitThreshold(none, []int{1}, 0.5); ok {
+		t.Error("found a fit although no cutoff reaches the target precision")
+	}
+}
+
+func TestFitThreshold_NoRelevantDocs(t *testing.T) {
+	results := append(scoredQueries(), QueryResult{
+		RetrievedIDs: []string{"q", "r"},
+		Scores:       []float32{0.99, 0.98},
+	})
+	fit, ok := FitThreshold(results, []int{2}, 0)
+	if !ok {
+		t.Fatal("no fit found")
+	}
+	// The unlabeled query's results are neither hits nor misses
+	if !approx(fit.Precision, 0.75) || !approx(fit.Recall, 1) {
+		t.Errorf("fit = %+v, want precision 0.75 and recall 1", fit)
+	}
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+)
+
+// Graded metrics use a query's relevance grades: 0 is not relevant, higher
+// is more relevant. A query without grades is binary, grading each of its
+// relevant documents 1, so graded metrics stay comparable across query files.
+
+// grades returns the relevance grade of each of r's relevant documents.
+func grades(r QueryResult) map[string]float64 {
+	if r.Grades != nil {
+		return r.Grades
+	}
+	g := make(map[string]float64, len(r.RelevantIDs))
+	for _, id := range r.RelevantIDs {
+		g[id] = 1
+	}
+	return g
+}
+
+// gradeScale returns the top of r's grade scale, which ERR and graded
+// precision divide grades by: MaxGrade if set, else r's highest grade, at
+// least 1. It never depends on the other queries being scored, so resamples
+// and subsets of a query file keep each query's score.
+func gradeScale(r QueryResult) float64 {
+	if r.MaxGrade > 0 {
+		return r.MaxGrade
+	}
+	highest := 1.0
+	for _, g := range r.Grades {
+		highest = math.Max(highest, g)
+	}
+	return highest
+}
+
+// ndcg computes NDCG@k for r: graded when r has grades, binary otherwise.
+func ndcg(r QueryResult, k int) float64 {
+	if r.Grades != nil {
+		return GradedNDCGAtK(r.RetrievedIDs, r.Grades, k)
+	}
+	return NDCGAtK(r.RetrievedIDs, r.RelevantIDs, k)
+}
+
+// GradedNDCGAtK computes NDCG@k with graded gains of 2^grade - 1, so a
+// grade-3 document is worth seven grade-1 ones. Each document gains once:
+// further chunks of it add nothing.
+//
+// Returns 1.0 if no document has a positive grade.
+func GradedNDCGAtK(retrieved []string, grades map[string]float64, k int) float64 {
+	var ideal []float64
+	for _, g := range grades {
+		if g > 0 {
+			ideal = append(ideal, g)
+		}
+	}
+	if len(ideal) == 0 {
+		return 1.0 // No relevant docs = perfect NDCG
+	}
+
+	var dcg float64
+	seen := make(map[string]struct{})
+	for i, id := range firstK(retrieved, k) {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		if g := grades[id]; g > 0 {
+			dcg += gain(g) / math.Log2(float64(i+2))
+		}
+	}
+
+	// IDCG: the highest grades ranked first
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+	var idcg float64
+	for i, g := range firstK64(ideal, k) {
+		idcg += gain(g) / math.Log2(float64(i+2))
+	}
+	return dcg / idcg
+}
+
+// ERRAtK computes Expected Reciprocal Rank over the top k: the expected
+// 1/rank at which a user scanning down the list stops, satisfied by a
+// document with probability (2^grade - 1) / 2^maxGrade. Unlike MRR, a
+// barely relevant document near the top does not end the scan, so ERR
+// rewards putting the best answer first.
+//
+// Returns 1.0 if no document has a positive grade.
+func ERRAtK(retrieved []string, grades map[string]float64, maxGrade float64, k int) float64 {
+	relevant := false
+	for _, g := range grades {
+		if g > 0 {
+			relevant = true
+			break
+		}
+	}
+	if !relevant {
+		return 1.0 // No relevant docs = nothing to find, as for recall
+	}
+
+	var err float64
+	continuing := 1.0 // probability the user reaches this rank
+	seen := make(map[string]struct{})
+	for i, id := range firstK(retrieved, k) {
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		g := grades[id]
+		if g <= 0 {
+			continue
+		}
+		stop := gain(g) / math.Exp2(maxGrade)
+		err += continuing * stop / float64(i+1)
+		continuing *= 1 - stop
+	}
+	return err
+}
+
+// GradedPrecisionAtK computes precision with partial credit: each top-k
+// slot scores its document's grade / maxGrade, and missing results score 0.
+// With binary relevance it equals PrecisionAtK.
+//
+// Returns 1.0 if no document has a positive grade.
+func GradedPrecisionAtK(retrieved []string, grades map[string]float64, maxGrade float64, k int) float64 {
+	relevant := false
+	for _, g := range grades {
+		if g > 0 {
+			relevant = true
+			break
+		}
+	}
+	if !relevant {
+		return 1.0
+	}
+	if k <= 0 || maxGrade <= 0 {
+		return 0
+	}
+
+	var credit float64
+	for _, id := range firstK(retrieved, k) {
+		if g := grades[id]; g > 0 {
+			credit += math.Min(g, maxGrade) / maxGrade
+		}
+	}
+	return credit / float64(k)
+}
+
+// gain is the NDCG and ERR gain of a grade.
+func gain(grade float64) float64 {
+	return math.Exp2(grade) - 1
+}
+
+// firstK64 returns the first k of vs.
+func firstK64(vs []float64, k int) []float64 {
+	if len(vs) > k {
+		return vs[:k]
+	}
+	return vs
+}
@@ -0,0 +1,156 @@
+package metrics
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGradedNDCGAtK(t *testing.T) {
+	g := map[string]float64{"answer": 3, "related": 1}
+
+	// Ideal order scores 1
+	if got := GradedNDCGAtK([]string{"answer", "related"}, g, 2); !approx(got, 1) {
+		t.Errorf("ideal order: got %v, want 1", got)
+	}
+
+	// Swapping them costs more than binary NDCG would notice
+	swapped := GradedNDCGAtK([]string{"related", "answer"}, g, 2)
+	idcg := 7 + 1/math.Log2(3)
+	want := (1 + 7/math.Log2(3)) / idcg
+	if !approx(swapped, want) {
+		t.Errorf("swapped: got %v, want %v", swapped, want)
+	}
+	if binary := NDCGAtK([]string{"related", "answer"}, []string{"answer", "related"}, 2); binary != 1 {
+		t.Errorf("binary NDCG of swapped = %v, want 1", binary)
+	}
+
+	// A second chunk of the answer gains nothing
+	if got := GradedNDCGAtK([]string{"answer", "answer"}, g, 2); !approx(got, 7/idcg) {
+		t.Errorf("duplicate: got %v, want %v", got, 7/idcg)
+	}
+
+	// Grade 0 is judged not relevant
+	if got := GradedNDCGAtK([]string{"x"}, map[string]float64{"x": 0}, 3); got != 1 {
+		t.Errorf("no relevant docs: got %v, want 1", got)
+	}
+}
+
+func TestERRAtK(t *testing.T) {
+	g := map[string]float64{"answer": 3, "related": 1}
+
+	// Perfect answer first: stop with probability 7/8 at rank 1
+	first := ERRAtK([]string{"answer", "related"}, g, 3, 2)
+	want := 7.0/8 + (1.0/8)*(1.0/8)/2
+	if !approx(first, want) {
+		t.Errorf("answer first: got %v, want %v", first, want)
+	}
+
+	// A related doc first barely helps the user
+	second := ERRAtK([]string{"related", "answer"}, g, 3, 2)
+	want = 1.0/8 + (7.0/8)*(7.0/8)/2
+	if !approx(second, want) {
+		t.Errorf("related first: got %v, want %v", second, want)
+	}
+	if second >= first {
+		t.Errorf("ERR should prefer the answer first: %v >= %v", second, first)
+	}
+
+	if got := ERRAtK([]string{"x", "y"}, g, 3, 2); got != 0 {
+		t.Errorf("no hits: got %v, want 0", got)
+	}
+	if got := ERRAtK([]string{"related", "answer"}, g, 3, 1); !approx(got, 1.0/8) {
+		t.Errorf("k=1: got %v, want 1/8", got)
+	}
+	if got := ERRAtK(nil, nil, 3, 5); got != 1 {
+		t.Errorf("no relevant docs: got %v, want 1", got)
+	}
+}
+
+func TestGradedPrecisionAtK(t *testing.T) {
+	g := map[string]float64{"answer": 3, "related": 1}
+	got := GradedPrecisionAtK([]string{"answer", "related", "x", "y"}, g, 3, 4)
+	if want := (1 + 1.0/3) / 4; !approx(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// Binary grades match PrecisionAtK
+	retrieved := []string{"a", "x", "b"}
+	relevant := []string{"a", "b"}
+	binary := GradedPrecisionAtK(retrieved, grades(QueryResult{RelevantIDs: relevant}), 1, 3)
+	if p := PrecisionAtK(retrieved, relevant, 3); !approx(binary, p) {
+		t.Errorf("binary graded precision = %v, PrecisionAtK = %v", binary, p)
+	}
+}
+
+func TestCompute_Graded(t *testing.T) {
+	results := []QueryResult{
+		{
+			RetrievedIDs: []string{"related", "answer"},
+			RelevantIDs:  []string{"answer", "related"},
+			Grades:       map[string]float64{"answer": 3, "related": 1},
+			MaxGrade:     3,
+		},
+		// An ungraded query in the same file counts its relevant doc as grade 1 of 3
+		{RetrievedIDs: []string{"a"}, RelevantIDs: []string{"a"}, MaxGrade: 3},
+	}
+	m := Compute(results, 2)
+
+	wantNDCG := (GradedNDCGAtK(results[0].RetrievedIDs, results[0].Grades, 2) + 1) / 2
+	if !approx(m.NDCGAtK, wantNDCG) {
+		t.Errorf("NDCGAtK = %v, want %v", m.NDCGAtK, wantNDCG)
+	}
+	wantERR := (ERRAtK(results[0].RetrievedIDs, results[0].Grades, 3, 2) + 1.0/8) / 2
+	if !approx(m.ERRAtK, wantERR) {
+		t.Errorf("ERRAtK = %v, want %v", m.ERRAtK, wantERR)
+	}
+	wantGP := ((1.0/3+1)/2 + (1.0/3)/2) / 2
+	if !approx(m.GradedPrecisionAtK, wantGP) {
+		t.Errorf("GradedPrecisionAtK = %v, want %v", m.GradedPrecisionAtK, wantGP)
+	}
+	// Recall stays binary
+	if m.RecallAtK != 1 {
+		t.Errorf("RecallAtK = %v, want 1", m.RecallAtK)
+	}
+}
+
+func TestCompute_GradeScaleIsPerQuery(t *testing.T) {
+	hit := QueryResult{RetrievedIDs: []string{"a"}, RelevantIDs: []string{"a"}, Grades: map[string]float64{"a": 1}}
+	other := QueryResult{RetrievedIDs: []string{"b"}, RelevantIDs: []string{"b"}, Grades: map[string]float64{"b": 3}}
+
+	// Without a file scale, a grade-1 hit at rank 1 is on its own scale
+	alone := Compute([]QueryResult{hit}, 1)
+	if !approx(alone.ERRAtK, 0.5) {
+		t.Errorf("ERRAtK alone = %v, want 0.5", alone.ERRAtK)
+	}
+	// ... whatever else is in the slice, as in bootstrap resamples
+	mixed := Compute([]QueryResult{hit, other}, 1)
+	wantERR := (0.5 + gain(3)/math.Exp2(3)) / 2
+	if !approx(mixed.ERRAtK, wantERR) {
+		t.Errorf("ERRAtK with a grade-3 query = %v, want %v", mixed.ERRAtK, wantERR)
+	}
+
+	// With the file's scale set, the hit scores grade 1 of 3 either way
+	hit.MaxGrade, other.MaxGrade = 3, 3
+	if got := Compute([]QueryResult{hit}, 1).ERRAtK; !approx(got, 1.0/8) {
+		t.Errorf("ERRAtK on a 0-3 scale = %v, want 1/8", got)
+	}
+	if got := Compute([]QueryResult{hit, hit}, 1).GradedPrecisionAtK; !approx(got, 1.0/3) {
+		t.Errorf("GradedPrecisionAtK on a 0-3 scale = %v, want 1/3", got)
+	}
+}
+
+func TestGradedNDCGAtK_MatchesBinary(t *testing.T) {
+	relevant := []string{"a", "b"}
+	ones := map[string]float64{"a": 1, "b": 1}
+	for _, retrieved := range [][]string{
+		{"a", "a", "b"},
+		{"x", "b", "b", "a"},
+		{"a", "x"},
+	} {
+		binary := NDCGAtK(retrieved, relevant, 3)
+		graded := GradedNDCGAtK(retrieved, ones, 3)
+		if !approx(binary, graded) || binary > 1 {
+			t.Errorf("%v: binary NDCG %v, graded with grade 1 %v", retrieved, binary, graded)
+		}
+	}
+}
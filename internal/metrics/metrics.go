@@ -16,6 +16,9 @@ type Result struct {
 	Coverage     float64 `json:"coverage"`
 	Redundancy   float64 `json:"redundancy"`
 	DiversityAtK float64 `json:"diversity_at_k"` // Fraction of unique docs in top-K results
+	// Graded metrics; queries without grades count each relevant doc as grade 1
+	ERRAtK             float64 `json:"err_at_k"`              // Expected Reciprocal Rank
+	GradedPrecisionAtK float64 `json:"graded_precision_at_k"` // Precision with partial credit by grade
 	// Latency stats in milliseconds
 	LatencyP50 float64 `json:"latency_p50_ms,omitempty"`
 	LatencyP95 float64 `json:"latency_p95_ms,omitempty"`
@@ -31,6 +34,11 @@ type QueryResult struct {
 	RelevantIDs  []string  `json:"relevant_ids"`
 	Scores       []float32 `json:"scores"`
 	LatencyMs    float64   `json:"latency_ms,omitempty"` // Query latency in milliseconds
+	// Grades holds graded relevance by doc ID; nil means binary relevance.
+	Grades map[string]float64 `json:"grades,omitempty"`
+	// MaxGrade is the top of the grade scale of the query file, which ERR
+	// and graded precision divide by; 0 means the highest of Grades.
+	MaxGrade float64 `json:"max_grade,omitempty"`
 }
 
 // Compute calculates all metrics from query results.
@@ -40,6 +48,7 @@ func Compute(results []QueryResult, k int) Result {
 	}
 
 	var totalRecall, totalRR, totalNDCG, totalDiversity float64
+	var totalERR, totalGradedPrecision float64
 	allRetrieved := make(map[string]int)   // doc -> count
 	allRelevant := make(map[string]struct{})
 
@@ -55,8 +64,13 @@ func Compute(results []QueryResult, k int) Result {
 		// MRR: reciprocal rank of first relevant doc
 		totalRR += ReciprocalRank(r.RetrievedIDs, r.RelevantIDs)
 
-		// NDCG@k: normalized discounted cumulative gain
-		totalNDCG += NDCGAtK(r.RetrievedIDs, r.RelevantIDs, k)
+		// NDCG@k: normalized discounted cumulative gain, graded if judged
+		totalNDCG += ndcg(r, k)
+
+		// ERR@k and graded precision@k: credit by relevance grade
+		g, scale := grades(r), gradeScale(r)
+		totalERR += ERRAtK(r.RetrievedIDs, g, scale, k)
+		totalGradedPrecision += GradedPrecisionAtK(r.RetrievedIDs, g, scale, k)
 
 		// Diversity@k: fraction of unique docs in top-k
 		totalDiversity += DiversityAtK(r.RetrievedIDs, k)
@@ -83,16 +97,18 @@ func Compute(results []QueryResult, k int) Result {
 	latencyP50, latencyP95, latencyP99, latencyAvg := ComputeLatencyStats(results)
 
 	return Result{
-		RecallAtK:    totalRecall / n,
-		MRR:          totalRR / n,
-		NDCGAtK:      totalNDCG / n,
-		Coverage:     coverage,
-		Redundancy:   redundancy,
-		DiversityAtK: totalDiversity / n,
-		LatencyP50:   latencyP50,
-		LatencyP95:   latencyP95,
-		LatencyP99:   latencyP99,
-		LatencyAvg:   latencyAvg,
+		RecallAtK:          totalRecall / n,
+		MRR:                totalRR / n,
+		NDCGAtK:            totalNDCG / n,
+		Coverage:           coverage,
+		Redundancy:         redundancy,
+		DiversityAtK:       totalDiversity / n,
+		ERRAtK:             totalERR / n,
+		GradedPrecisionAtK: totalGradedPrecision / n,
+		LatencyP50:         latencyP50,
+		LatencyP95:         latencyP95,
+		LatencyP99:         latencyP99,
+		LatencyAvg:         latencyAvg,
 	}
 }
 
@@ -205,6 +221,8 @@ func DiversityAtK(retrieved []string, k int) float64 {
 // and IDCG is the ideal DCG (all relevant docs ranked first)
 //
 // Returns 1.0 for perfect ranking, 0.0 if no relevant docs retrieved.
+// Each relevant doc gains once: further chunks of it add nothing, as in
+// GradedNDCGAtK with every grade 1.
 // This metric is an industry standard (used in search engines, academic papers).
 func NDCGAtK(retrieved, relevant []string, k int) float64 {
 	if len(relevant) == 0 {
@@ -224,18 +242,24 @@ func NDCGAtK(retrieved, relevant []string, k int) float64 {
 
 	// Compute DCG with binary relevance (1 if relevant, 0 otherwise)
 	var dcg float64
+	found := make(map[string]struct{})
 	for i, id := range topK {
-		if _, ok := relevantSet[id]; ok {
-			// log2(rank+1) where rank is 1-indexed, so log2(i+2)
-			dcg += 1.0 / math.Log2(float64(i+2))
+		if _, ok := relevantSet[id]; !ok {
+			continue
+		}
+		if _, dup := found[id]; dup {
+			continue
 		}
+		found[id] = struct{}{}
+		// log2(rank+1) where rank is 1-indexed, so log2(i+2)
+		dcg += 1.0 / math.Log2(float64(i+2))
 	}
 
 	// Compute IDCG: ideal DCG if all relevant docs ranked first
 	// Number of relevant docs that could fit in top-K
 	idealK := k
-	if len(relevant) < idealK {
-		idealK = len(relevant)
+	if len(relevantSet) < idealK {
+		idealK = len(relevantSet)
 	}
 
 	var idcg float64
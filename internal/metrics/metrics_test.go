@@ -413,6 +413,15 @@ func TestNDCGAtK(t *testing.T) {
 			// NDCG = 1.131/1.631 = 0.693
 			expected:  0.693,
 		},
+		{
+			name:      "duplicate chunks gain once",
+			retrieved: []string{"a", "a", "b"},
+			relevant:  []string{"a", "b"},
+			k:         3,
+			// DCG = 1/log2(2) + 1/log2(4) = 1.5
+			// IDCG = 1.631
+			expected: 0.920,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,137 @@
+// Package tokenizer provides a dependency-free approximation of subword
+// tokenization for token counting and truncation.
+//
+// It does not reproduce any specific vocabulary. Instead it mimics the shape
+// of BPE/WordPiece tokenizers: whitespace attaches to the following word,
+// short words are one token, long words split into pieces, digits group in
+// threes, CJK characters count one token each, and punctuation counts per
+// symbol. On English prose and technical docs this lands within roughly ±15%
+// of cl100k_base, which is enough for limits, batching and cost estimates.
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// wordPieceRunes is the maximum number of letters in one word piece.
+	wordPieceRunes = 8
+
+	// digitPieceRunes matches cl100k_base, which groups digits in threes.
+	digitPieceRunes = 3
+
+	// symbolRunRunes is how many identical symbols (e.g. "----") merge into one token.
+	symbolRunRunes = 4
+)
+
+// Token is a byte span [Start, End) of the tokenized text.
+// Leading spaces are not part of a token's span.
+type Token struct {
+	Start int
+	End   int
+}
+
+// Tokenize splits text into approximate subword tokens.
+func Tokenize(text string) []Token {
+	var tokens []Token
+	i := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+
+		switch {
+		case r == '\n' || r == '\r':
+			// A run of line breaks is a single token
+			start := i
+			for i < len(text) && (text[i] == '\n' || text[i] == '\r') {
+				i++
+			}
+			tokens = append(tokens, Token{Start: start, End: i})
+
+		case unicode.IsSpace(r):
+			// Spaces and tabs merge into the next token
+			i += size
+
+		case isIdeographic(r):
+			tokens = append(tokens, Token{Start: i, End: i + size})
+			i += size
+
+		case unicode.IsLetter(r) || unicode.IsMark(r):
+			i = appendRun(&tokens, text, i, wordPieceRunes, func(r rune) bool {
+				return (unicode.IsLetter(r) || unicode.IsMark(r)) && !isIdeographic(r)
+			})
+
+		case unicode.IsDigit(r):
+			i = appendRun(&tokens, text, i, digitPieceRunes, unicode.IsDigit)
+
+		default:
+			i = appendRun(&tokens, text, i, symbolRunRunes, func(next rune) bool {
+				return next == r
+			})
+		}
+	}
+	return tokens
+}
+
+// appendRun consumes the run of runes matching pred starting at i and appends
+// it as pieces of at most pieceRunes runes. Returns the index after the run.
+func appendRun(tokens *[]Token, text string, i, pieceRunes int, pred func(rune) bool) int {
+	start := i
+	n := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !pred(r) {
+			break
+		}
+		if n == pieceRunes {
+			*tokens = append(*tokens, Token{Start: start, End: i})
+			start = i
+			n = 0
+		}
+		i += size
+		n++
+	}
+	*tokens = append(*tokens, Token{Start: start, End: i})
+	return i
+}
+
+// isIdeographic reports whether r belongs to a script tokenized per character.
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// Count returns the approximate number of tokens in text.
+func Count(text string) int {
+	return len(Tokenize(text))
+}
+
+// Truncate returns the longest prefix of text containing at most max tokens.
+// Returns text unchanged if it is already within the limit.
+func Truncate(text string, max int) string {
+	if max <= 0 {
+		return ""
+	}
+	tokens := Tokenize(text)
+	if len(tokens) <= max {
+		return text
+	}
+	return text[:tokens[max-1].End]
+}
+
+// Split breaks text into consecutive pieces of at most max tokens each.
+// Pieces are contiguous slices of text, so concatenating them yields text.
+func Split(text string, max int) []string {
+	tokens := Tokenize(text)
+	if max <= 0 || len(tokens) <= max {
+		return []string{text}
+	}
+
+	var pieces []string
+	start := 0
+	for i := max; i < len(tokens); i += max {
+		end := tokens[i].Start
+		pieces = append(pieces, text[start:end])
+		start = end
+	}
+	return append(pieces, text[start:])
+}
@@ -0,0 +1,94 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want int
+	}{
+		{"empty", "", 0},
+		{"whitespace only", "   \t ", 0},
+		{"short words", "How do I rotate a key", 6},
+		{"long word splits", "authentication", 2},
+		{"punctuation", "Hello, world!", 4},
+		{"digits group in threes", "1234567", 3},
+		{"newline run is one token", "a\n\n\nb", 3},
+		{"repeated symbols merge", "--------", 2},
+		{"cjk per character", "日本語", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.text); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize_SpansExcludeLeadingSpaces(t *testing.T) {
+	text := "one  two"
+	tokens := Tokenize(text)
+
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 tokens, got %d", len(tokens))
+	}
+	if got := text[tokens[1].Start:tokens[1].End]; got != "two" {
+		t.Errorf("second token = %q, want %q", got, "two")
+	}
+}
+
+func TestCount_ProseRatio(t *testing.T) {
+	// Typical prose should land around 1.1-1.5 tokens per word
+	text := "To rotate an API key, open the dashboard, select the project, and click " +
+		"Regenerate. Existing integrations keep working until the old key expires."
+	words := len(strings.Fields(text))
+	ratio := float64(Count(text)) / float64(words)
+
+	if ratio < 1.1 || ratio > 1.5 {
+		t.Errorf("tokens per word = %.2f, expected between 1.1 and 1.5", ratio)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	text := "alpha beta gamma delta"
+
+	if got := Truncate(text, 2); got != "alpha beta" {
+		t.Errorf("Truncate(2) = %q, want %q", got, "alpha beta")
+	}
+	if got := Truncate(text, 10); got != text {
+		t.Errorf("Truncate(10) = %q, want unchanged", got)
+	}
+	if got := Truncate(text, 0); got != "" {
+		t.Errorf("Truncate(0) = %q, want empty", got)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	text := "one two three four five six seven"
+
+	pieces := Split(text, 3)
+	if len(pieces) != 3 {
+		t.Fatalf("expected 3 pieces, got %d: %q", len(pieces), pieces)
+	}
+	if strings.Join(pieces, "") != text {
+		t.Errorf("pieces do not reassemble original text: %q", pieces)
+	}
+	for i, p := range pieces {
+		if n := Count(p); n > 3 {
+			t.Errorf("piece %d has %d tokens, want <= 3", i, n)
+		}
+	}
+}
+
+func TestSplit_WithinLimit(t *testing.T) {
+	pieces := Split("short text", 10)
+	if len(pieces) != 1 || pieces[0] != "short text" {
+		t.Errorf("Split within limit = %q, want single unchanged piece", pieces)
+	}
+}
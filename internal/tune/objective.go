@@ -0,0 +1,130 @@
+package tune
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+// Metric names a value of metrics.Result that tuning can optimize or
+// constrain.
+type Metric string
+
+const (
+	MetricRecall     Metric = "recall"
+	MetricMRR        Metric = "mrr"
+	MetricNDCG       Metric = "ndcg"
+	MetricERR        Metric = "err"
+	MetricCoverage   Metric = "coverage"
+	MetricDiversity  Metric = "diversity"
+	MetricLatencyP50 Metric = "latency_p50"
+	MetricLatencyP95 Metric = "latency_p95"
+	MetricLatencyP99 Metric = "latency_p99"
+	MetricLatencyAvg Metric = "latency_avg"
+)
+
+// metricNames lists the supported metrics, for errors.
+const metricNames = "recall, mrr, ndcg, err, coverage, diversity, latency_p50, latency_p95, latency_p99, latency_avg"
+
+// ParseMetric parses a metric name.
+func ParseMetric(s string) (Metric, error) {
+	m := Metric(strings.ToLower(strings.TrimSpace(s)))
+	switch m {
+	case MetricRecall, MetricMRR, MetricNDCG, MetricERR, MetricCoverage, MetricDiversity,
+		MetricLatencyP50, MetricLatencyP95, MetricLatencyP99, MetricLatencyAvg:
+		return m, nil
+	default:
+		return "", fmt.Errorf("unsupported metric: %s (supported: %s)", s, metricNames)
+	}
+}
+
+// Value returns the metric's value in r.
+func (m Metric) Value(r metrics.Result) float64 {
+	switch m {
+	case MetricRecall:
+		return r.RecallAtK
+	case MetricMRR:
+		return r.MRR
+	case MetricNDCG:
+		return r.NDCGAtK
+	case MetricERR:
+		return r.ERRAtK
+	case MetricCoverage:
+		return r.Coverage
+	case MetricDiversity:
+		return r.DiversityAtK
+	case MetricLatencyP50:
+		return r.LatencyP50
+	case MetricLatencyP95:
+		return r.LatencyP95
+	case MetricLatencyP99:
+		return r.LatencyP99
+	case MetricLatencyAvg:
+		return r.LatencyAvg
+	}
+	return 0
+}
+
+// Minimize reports whether lower values are better: true for latencies.
+func (m Metric) Minimize() bool {
+	return strings.HasPrefix(string(m), "latency_")
+}
+
+// better reports whether a is a better value of m than b.
+func (m Metric) better(a, b float64) bool {
+	if m.Minimize() {
+		return a < b
+	}
+	return a > b
+}
+
+// Constraint bounds a metric, e.g. latency_p95 < 200.
+type Constraint struct {
+	Metric Metric
+	Op     string // <, <=, > or >=
+	Value  float64
+}
+
+// ParseConstraint parses a constraint of the form <metric><op><value>,
+// e.g. "latency_p95<200" or "recall >= 0.8". Latencies are in milliseconds.
+func ParseConstraint(s string) (Constraint, error) {
+	// Two-character operators first, so "<=" is not read as "<"
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		i := strings.Index(s, op)
+		if i < 0 {
+			continue
+		}
+		m, err := ParseMetric(s[:i])
+		if err != nil {
+			return Constraint{}, fmt.Errorf("constraint %q: %w", s, err)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(s[i+len(op):]), 64)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("constraint %q: invalid value: %w", s, err)
+		}
+		return Constraint{Metric: m, Op: op, Value: v}, nil
+	}
+	return Constraint{}, fmt.Errorf("constraint %q: expected <metric><op><value> with op one of <, <=, >, >=", s)
+}
+
+// Satisfied reports whether r meets the constraint.
+func (c Constraint) Satisfied(r metrics.Result) bool {
+	v := c.Metric.Value(r)
+	switch c.Op {
+	case "<":
+		return v < c.Value
+	case "<=":
+		return v <= c.Value
+	case ">":
+		return v > c.Value
+	case ">=":
+		return v >= c.Value
+	}
+	return false
+}
+
+func (c Constraint) String() string {
+	return fmt.Sprintf("%s %s %g", c.Metric, c.Op, c.Value)
+}
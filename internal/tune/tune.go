@@ -0,0 +1,393 @@
+// Package tune searches a space of retrieval configs for the one that
+// optimizes an objective metric subject to constraints on others.
+//
+// Candidates are evaluated by a caller-supplied Evaluator, which runs a
+// config against the first n queries of a fixed query order. Grid search
+// evaluates every candidate on every query; random search a seeded sample
+// of them. Successive halving evaluates all candidates on a few queries,
+// keeps the best 1/eta, and repeats with eta times the queries until at most
+// eta remain, which are evaluated on every query. Pareto returns the
+// candidates no other beats on every one of a set of metrics.
+package tune
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+// Strategy selects how the space is searched.
+type Strategy string
+
+const (
+	StrategyGrid    Strategy = "grid"
+	StrategyRandom  Strategy = "random"
+	StrategyHalving Strategy = "halving"
+)
+
+// Defaults for the search.
+const (
+	// DefaultEta is the factor successive halving cuts candidates by.
+	DefaultEta = 3
+
+	// DefaultMinQueries is the fewest queries successive halving evaluates
+	// a candidate on; fewer rank candidates mostly by noise.
+	DefaultMinQueries = 10
+)
+
+// ParseStrategy parses a search strategy name.
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(strings.ToLower(strings.TrimSpace(s))) {
+	case StrategyGrid:
+		return StrategyGrid, nil
+	case StrategyRandom:
+		return StrategyRandom, nil
+	case StrategyHalving:
+		return StrategyHalving, nil
+	default:
+		return "", fmt.Errorf("unsupported strategy: %s (supported: grid, random, halving)", s)
+	}
+}
+
+// Evaluator computes the metrics of cfg over the first n queries.
+type Evaluator func(ctx context.Context, cfg config.SimConfig, n int) (metrics.Result, error)
+
+// Trial is the latest evaluation of a candidate.
+type Trial struct {
+	Config  config.SimConfig `json:"config"`
+	Metrics metrics.Result   `json:"metrics"`
+	// Queries is the number of queries evaluated: fewer than all for
+	// candidates successive halving dropped.
+	Queries int `json:"queries"`
+	// Rung is the last successive halving round the candidate reached.
+	Rung int `json:"rung,omitempty"`
+	// Violated lists the constraints the metrics do not meet.
+	Violated []string `json:"violated,omitempty"`
+	// Complete reports whether every query was evaluated.
+	Complete bool `json:"complete"`
+}
+
+// Feasible reports whether the trial meets every constraint.
+func (t Trial) Feasible() bool {
+	return len(t.Violated) == 0
+}
+
+// Option configures a Tuner.
+type Option func(*Tuner)
+
+// WithStrategy sets the search strategy (default grid).
+func WithStrategy(s Strategy) Option {
+	return func(t *Tuner) {
+		t.strategy = s
+	}
+}
+
+// WithConstraints sets the constraints a recommended config must meet.
+func WithConstraints(cs ...Constraint) Option {
+	return func(t *Tuner) {
+		t.constraints = append(t.constraints, cs...)
+	}
+}
+
+// WithTrials sets the number of candidates random search and successive
+// halving sample from the space (0 = all, for successive halving).
+func WithTrials(n int) Option {
+	return func(t *Tuner) {
+		t.trials = n
+	}
+}
+
+// WithSeed sets the seed of random sampling.
+func WithSeed(seed int64) Option {
+	return func(t *Tuner) {
+		t.seed = seed
+	}
+}
+
+// WithEta sets the factor successive halving cuts candidates by.
+func WithEta(eta int) Option {
+	return func(t *Tuner) {
+		t.eta = eta
+	}
+}
+
+// WithMinQueries sets the fewest queries successive halving evaluates a
+// candidate on.
+func WithMinQueries(n int) Option {
+	return func(t *Tuner) {
+		t.minQueries = n
+	}
+}
+
+// WithProgress sets a function called after each evaluation.
+func WithProgress(fn func(Trial)) Option {
+	return func(t *Tuner) {
+		t.progress = fn
+	}
+}
+
+// Tuner searches candidates for the best config by an objective.
+type Tuner struct {
+	objective   Metric
+	constraints []Constraint
+	strategy    Strategy
+	trials      int
+	seed        int64
+	eta         int
+	minQueries  int
+	progress    func(Trial)
+}
+
+// New creates a Tuner optimizing objective: maximized, or minimized for
+// latencies.
+func New(objective Metric, opts ...Option) (*Tuner, error) {
+	t := &Tuner{
+		objective:  objective,
+		strategy:   StrategyGrid,
+		eta:        DefaultEta,
+		minQueries: DefaultMinQueries,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if _, err := ParseMetric(string(objective)); err != nil {
+		return nil, err
+	}
+	if _, err := ParseStrategy(string(t.strategy)); err != nil {
+		return nil, err
+	}
+	if t.strategy == StrategyRandom && t.trials <= 0 {
+		return nil, fmt.Errorf("random search needs a positive number of trials")
+	}
+	if t.trials < 0 {
+		return nil, fmt.Errorf("trials cannot be negative, got %d", t.trials)
+	}
+	if t.eta < 2 {
+		return nil, fmt.Errorf("eta must be at least 2, got %d", t.eta)
+	}
+	if t.minQueries < 1 {
+		return nil, fmt.Errorf("min queries must be at least 1, got %d", t.minQueries)
+	}
+	return t, nil
+}
+
+// Run evaluates candidates with eval, given the number of queries, and
+// returns a trial per candidate evaluated, in candidate order. If eval
+// fails, Run returns the trials so far with the error.
+func (t *Tuner) Run(ctx context.Context, candidates []config.SimConfig, queries int, eval Evaluator) ([]Trial, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no candidates to evaluate")
+	}
+	if queries <= 0 {
+		return nil, fmt.Errorf("no queries to evaluate")
+	}
+
+	picked := candidates
+	if t.strategy != StrategyGrid && t.trials > 0 && t.trials < len(candidates) {
+		picked = t.sample(candidates)
+	}
+	trials := make([]Trial, len(picked))
+	for i, cfg := range picked {
+		trials[i].Config = cfg
+	}
+
+	if t.strategy != StrategyHalving {
+		for i := range trials {
+			if err := t.evaluate(ctx, &trials[i], queries, queries, eval); err != nil {
+				return evaluated(trials), err
+			}
+		}
+		return trials, nil
+	}
+
+	// Successive halving: count the cuts needed to reach eta candidates,
+	// then start from queries/eta^cuts
+	cuts := 0
+	for n := len(trials); n > t.eta; n = (n + t.eta - 1) / t.eta {
+		cuts++
+	}
+	budget := queries
+	for i := 0; i < cuts; i++ {
+		budget /= t.eta
+	}
+	budget = max(budget, min(t.minQueries, queries))
+
+	alive := make([]int, len(trials))
+	for i := range alive {
+		alive[i] = i
+	}
+	for rung := 0; ; rung++ {
+		if rung == cuts {
+			budget = queries
+		}
+		for _, i := range alive {
+			trials[i].Rung = rung
+			if err := t.evaluate(ctx, &trials[i], budget, queries, eval); err != nil {
+				return evaluated(trials), err
+			}
+		}
+		if rung == cuts {
+			return trials, nil
+		}
+
+		// Keep the best 1/eta by objective, feasible ones first
+		slices.SortStableFunc(alive, func(a, b int) int {
+			return t.compare(trials[a], trials[b])
+		})
+		alive = alive[:(len(alive)+t.eta-1)/t.eta]
+		slices.Sort(alive)
+		budget = min(budget*t.eta, queries)
+	}
+}
+
+// evaluate runs one trial on n of total queries.
+func (t *Tuner) evaluate(ctx context.Context, trial *Trial, n, total int, eval Evaluator) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m, err := eval(ctx, trial.Config, n)
+	if err != nil {
+		return fmt.Errorf("config %s: %w", trial.Config.Name, err)
+	}
+	trial.Metrics = m
+	trial.Queries = n
+	trial.Complete = n == total
+	trial.Violated = nil
+	for _, c := range t.constraints {
+		if !c.Satisfied(m) {
+			trial.Violated = append(trial.Violated, c.String())
+		}
+	}
+	if t.progress != nil {
+		t.progress(*trial)
+	}
+	return nil
+}
+
+// sample picks t.trials candidates at random, keeping their order.
+func (t *Tuner) sample(candidates []config.SimConfig) []config.SimConfig {
+	rng := rand.New(rand.NewSource(t.seed))
+	idx := rng.Perm(len(candidates))[:t.trials]
+	slices.Sort(idx)
+	picked := make([]config.SimConfig, len(idx))
+	for i, j := range idx {
+		picked[i] = candidates[j]
+	}
+	return picked
+}
+
+// compare orders trials best first: feasible before infeasible, then by
+// objective. Ties go to the faster trial by p95 latency, or, when latency
+// is the objective, to the one with the better NDCG.
+func (t *Tuner) compare(a, b Trial) int {
+	if a.Feasible() != b.Feasible() {
+		if a.Feasible() {
+			return -1
+		}
+		return 1
+	}
+	tiebreak := MetricLatencyP95
+	if t.objective.Minimize() {
+		tiebreak = MetricNDCG
+	}
+	for _, m := range []Metric{t.objective, tiebreak} {
+		va, vb := m.Value(a.Metrics), m.Value(b.Metrics)
+		switch {
+		case m.better(va, vb):
+			return -1
+		case m.better(vb, va):
+			return 1
+		}
+	}
+	return 0
+}
+
+// Best returns the complete, feasible trial with the best objective, or
+// false if there is none.
+func (t *Tuner) Best(trials []Trial) (Trial, bool) {
+	var best Trial
+	found := false
+	for _, tr := range trials {
+		if !tr.Complete || !tr.Feasible() {
+			continue
+		}
+		if !found || t.compare(tr, best) < 0 {
+			best, found = tr, true
+		}
+	}
+	return best, found
+}
+
+// Rank returns the trials sorted best first: complete before partial, then
+// as Best ranks them.
+func (t *Tuner) Rank(trials []Trial) []Trial {
+	ranked := slices.Clone(trials)
+	slices.SortStableFunc(ranked, func(a, b Trial) int {
+		if a.Complete != b.Complete {
+			if a.Complete {
+				return -1
+			}
+			return 1
+		}
+		return t.compare(a, b)
+	})
+	return ranked
+}
+
+// Pareto returns the complete, feasible trials that no other such trial
+// matches or beats on every metric and strictly beats on one, in input
+// order.
+func Pareto(trials []Trial, ms ...Metric) []Trial {
+	var pool []Trial
+	for _, tr := range trials {
+		if tr.Complete && tr.Feasible() {
+			pool = append(pool, tr)
+		}
+	}
+	var front []Trial
+	for i, a := range pool {
+		dominated := false
+		for j, b := range pool {
+			if i != j && dominates(b, a, ms) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			front = append(front, a)
+		}
+	}
+	return front
+}
+
+// dominates reports whether a is at least as good as b on every metric and
+// better on one.
+func dominates(a, b Trial, ms []Metric) bool {
+	strictly := false
+	for _, m := range ms {
+		va, vb := m.Value(a.Metrics), m.Value(b.Metrics)
+		if m.better(vb, va) {
+			return false
+		}
+		if m.better(va, vb) {
+			strictly = true
+		}
+	}
+	return strictly
+}
+
+// evaluated returns the trials that have been evaluated at least once.
+func evaluated(trials []Trial) []Trial {
+	var done []Trial
+	for _, tr := range trials {
+		if tr.Queries > 0 {
+			done = append(done, tr)
+		}
+	}
+	return done
+}
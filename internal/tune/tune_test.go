@@ -0,0 +1,254 @@
+package tune
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/metawake/ragtune/internal/config"
+	"github.com/metawake/ragtune/internal/metrics"
+)
+
+// sizes returns candidates with chunk sizes 100, 200, ... n*100.
+func sizes(n int) []config.SimConfig {
+	var cs []config.SimConfig
+	for i := 1; i <= n; i++ {
+		cs = append(cs, config.SimConfig{Name: "c", ChunkSize: i * 100, TopK: 5})
+	}
+	return cs
+}
+
+// peakEval scores chunk size 500 best, with latency growing with size.
+// It records the query counts it was asked for.
+type peakEval struct {
+	calls map[int][]int // chunk size → query counts
+}
+
+func (e *peakEval) eval(ctx context.Context, cfg config.SimConfig, n int) (metrics.Result, error) {
+	if e.calls == nil {
+		e.calls = make(map[int][]int)
+	}
+	e.calls[cfg.ChunkSize] = append(e.calls[cfg.ChunkSize], n)
+	d := float64(cfg.ChunkSize-500) / 1000
+	return metrics.Result{
+		NDCGAtK:    1 - d*d,
+		RecallAtK:  float64(cfg.ChunkSize) / 1000,
+		LatencyP95: float64(cfg.ChunkSize) / 10,
+	}, nil
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Constraint
+	}{
+		{"latency_p95<200", Constraint{MetricLatencyP95, "<", 200}},
+		{"recall >= 0.8", Constraint{MetricRecall, ">=", 0.8}},
+		{"NDCG>0.5", Constraint{MetricNDCG, ">", 0.5}},
+		{"latency_avg<=12.5", Constraint{MetricLatencyAvg, "<=", 12.5}},
+	}
+	for _, tt := range tests {
+		got, err := ParseConstraint(tt.in)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseConstraint(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"latency_p95", "speed<3", "recall>=high", "<0.5"} {
+		if _, err := ParseConstraint(bad); err == nil {
+			t.Errorf("ParseConstraint(%q) succeeded, want error", bad)
+		}
+	}
+
+	c := Constraint{MetricLatencyP95, "<", 200}
+	if !c.Satisfied(metrics.Result{LatencyP95: 150}) || c.Satisfied(metrics.Result{LatencyP95: 200}) {
+		t.Error("latency_p95 < 200 not applied as a strict bound")
+	}
+}
+
+func TestTuner_Grid(t *testing.T) {
+	e := &peakEval{}
+	tuner, err := New(MetricNDCG)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trials, err := tuner.Run(context.Background(), sizes(9), 40, e.eval)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(trials) != 9 {
+		t.Fatalf("got %d trials, want 9", len(trials))
+	}
+	for _, tr := range trials {
+		if !tr.Complete || tr.Queries != 40 {
+			t.Errorf("trial %d evaluated on %d queries, want all 40", tr.Config.ChunkSize, tr.Queries)
+		}
+	}
+	best, ok := tuner.Best(trials)
+	if !ok || best.Config.ChunkSize != 500 {
+		t.Errorf("best = %d (found %v), want 500", best.Config.ChunkSize, ok)
+	}
+}
+
+func TestTuner_Constraints(t *testing.T) {
+	tuner, err := New(MetricNDCG, WithConstraints(Constraint{MetricLatencyP95, "<", 35}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	trials, err := tuner.Run(context.Background(), sizes(9), 40, (&peakEval{}).eval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sizes of 400 and up exceed the latency bound; 300 is the best left
+	best, ok := tuner.Best(trials)
+	if !ok || best.Config.ChunkSize != 300 {
+		t.Errorf("best = %d (found %v), want 300", best.Config.ChunkSize, ok)
+	}
+	if trials[8].Feasible() || len(trials[8].Violated) != 1 {
+		t.Errorf("trial 900 violated = %v, want the latency bound", trials[8].Violated)
+	}
+
+	tuner, _ = New(MetricNDCG, WithConstraints(Constraint{MetricLatencyP95, "<", 1}))
+	trials, _ = tuner.Run(context.Background(), sizes(3), 40, (&peakEval{}).eval)
+	if _, ok := tuner.Best(trials); ok {
+		t.Error("Best found a trial although none is feasible")
+	}
+}
+
+func TestTuner_Random(t *testing.T) {
+	run := func(seed int64) []Trial {
+		tuner, err := New(MetricNDCG, WithStrategy(StrategyRandom), WithTrials(4), WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		trials, err := tuner.Run(context.Background(), sizes(20), 10, (&peakEval{}).eval)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return trials
+	}
+	a, b := run(7), run(7)
+	if len(a) != 4 {
+		t.Fatalf("got %d trials, want 4", len(a))
+	}
+	for i := range a {
+		if a[i].Config.ChunkSize != b[i].Config.ChunkSize {
+			t.Fatalf("same seed sampled %d and %d", a[i].Config.ChunkSize, b[i].Config.ChunkSize)
+		}
+		if i > 0 && a[i].Config.ChunkSize <= a[i-1].Config.ChunkSize {
+			t.Errorf("sample not in candidate order: %d after %d", a[i].Config.ChunkSize, a[i-1].Config.ChunkSize)
+		}
+	}
+
+	if _, err := New(MetricNDCG, WithStrategy(StrategyRandom)); err == nil {
+		t.Error("expected an error for random search without trials")
+	}
+}
+
+func TestTuner_Halving(t *testing.T) {
+	e := &peakEval{}
+	var progress int
+	tuner, err := New(MetricNDCG, WithStrategy(StrategyHalving), WithMinQueries(5), WithProgress(func(Trial) { progress++ }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 27 candidates, eta 3: 27 on 10 queries, 9 on 30, 3 on 90
+	trials, err := tuner.Run(context.Background(), sizes(27), 90, e.eval)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(trials) != 27 || progress != 39 {
+		t.Fatalf("got %d trials and %d evaluations, want 27 and 39", len(trials), progress)
+	}
+	complete := 0
+	for _, tr := range trials {
+		if tr.Complete {
+			complete++
+		}
+	}
+	if complete != 3 {
+		t.Errorf("%d trials evaluated on every query, want 3", complete)
+	}
+	if got := e.calls[500]; len(got) != 3 || got[0] != 10 || got[1] != 30 || got[2] != 90 {
+		t.Errorf("chunk size 500 evaluated on %v queries, want [10 30 90]", got)
+	}
+	if got := e.calls[2700]; len(got) != 1 || got[0] != 10 {
+		t.Errorf("chunk size 2700 evaluated on %v queries, want [10]", got)
+	}
+	best, ok := tuner.Best(trials)
+	if !ok || best.Config.ChunkSize != 500 {
+		t.Errorf("best = %d (found %v), want 500", best.Config.ChunkSize, ok)
+	}
+	if ranked := tuner.Rank(trials); ranked[0].Config.ChunkSize != 500 || !ranked[2].Complete || ranked[3].Complete {
+		t.Errorf("Rank does not put the 3 complete trials first, best first")
+	}
+}
+
+func TestTuner_HalvingMinQueries(t *testing.T) {
+	e := &peakEval{}
+	tuner, _ := New(MetricNDCG, WithStrategy(StrategyHalving))
+	if _, err := tuner.Run(context.Background(), sizes(27), 30, e.eval); err != nil {
+		t.Fatal(err)
+	}
+	// 30/27 queries would rank candidates by noise: start at the minimum
+	if got := e.calls[100]; got[0] != DefaultMinQueries {
+		t.Errorf("first rung evaluated %d queries, want %d", got[0], DefaultMinQueries)
+	}
+}
+
+func TestTuner_EvalError(t *testing.T) {
+	calls := 0
+	failing := func(ctx context.Context, cfg config.SimConfig, n int) (metrics.Result, error) {
+		calls++
+		if calls == 3 {
+			return metrics.Result{}, errors.New("store unavailable")
+		}
+		return metrics.Result{NDCGAtK: 0.5}, nil
+	}
+	tuner, _ := New(MetricNDCG)
+	trials, err := tuner.Run(context.Background(), sizes(5), 10, failing)
+	if err == nil {
+		t.Fatal("expected the evaluation error")
+	}
+	if len(trials) != 2 {
+		t.Errorf("got %d trials, want the 2 evaluated before the error", len(trials))
+	}
+}
+
+func TestPareto(t *testing.T) {
+	trial := func(name string, ndcg, p95 float64) Trial {
+		return Trial{Config: config.SimConfig{Name: name}, Complete: true, Metrics: metrics.Result{NDCGAtK: ndcg, LatencyP95: p95}}
+	}
+	trials := []Trial{
+		trial("fast", 0.6, 10),
+		trial("slow-worse", 0.5, 50), // dominated by fast
+		trial("balanced", 0.7, 20),
+		trial("best", 0.8, 40),
+		trial("tied", 0.8, 45), // dominated by best
+	}
+	partial := trial("partial", 0.9, 5)
+	partial.Complete = false
+	infeasible := trial("infeasible", 0.95, 1)
+	infeasible.Violated = []string{"recall >= 0.9"}
+	trials = append(trials, partial, infeasible)
+
+	front := Pareto(trials, MetricNDCG, MetricLatencyP95)
+	var names []string
+	for _, tr := range front {
+		names = append(names, tr.Config.Name)
+	}
+	want := []string{"fast", "balanced", "best"}
+	if len(names) != len(want) {
+		t.Fatalf("front = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("front = %v, want %v", names, want)
+			break
+		}
+	}
+}
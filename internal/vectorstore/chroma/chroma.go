@@ -8,13 +8,17 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/metawake/ragtune/internal/vectorstore"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Client)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Client)(nil)
+	_ vectorstore.Deleter = (*Client)(nil)
+)
 
 // Client implements vectorstore.Store for Chroma.
 type Client struct {
@@ -92,15 +96,26 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 
 		meta := map[string]interface{}{}
 		doc := ""
-		if p.Payload != nil {
-			if source, ok := p.Payload["source"].(string); ok {
-				meta["source"] = source
-			}
-			if text, ok := p.Payload["text"].(string); ok {
-				doc = text
+		for k, v := range p.Payload {
+			if k == "text" {
+				if text, ok := v.(string); ok {
+					doc = text
+				}
+				continue
 			}
-			if idx, ok := p.Payload["chunk_index"].(int); ok {
-				meta["chunk_index"] = idx
+			// Chroma metadata values must be strings, numbers or booleans;
+			// lists (e.g. front matter tags) are stored comma-separated
+			switch val := v.(type) {
+			case string, int, int64, float64, bool:
+				meta[k] = v
+			case []string:
+				meta[k] = strings.Join(val, ",")
+			case []interface{}:
+				items := make([]string, len(val))
+				for i, item := range val {
+					items[i] = fmt.Sprint(item)
+				}
+				meta[k] = strings.Join(items, ",")
 			}
 		}
 		metadatas[i] = meta
@@ -181,6 +196,26 @@ func (c *Client) Search(ctx context.Context, collection string, vector []float32
 	return results, nil
 }
 
+// Delete removes records by ID.
+func (c *Client) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	col, err := c.getCollection(ctx, collection)
+	if err != nil {
+		return fmt.Errorf("collection not found: %w", err)
+	}
+
+	path := fmt.Sprintf("/api/v2/tenants/%s/databases/%s/collections/%s/delete", c.tenant, c.database, col.ID)
+	_, err = c.doRequest(ctx, "POST", path, map[string]interface{}{"ids": ids})
+	if err != nil {
+		return fmt.Errorf("delete failed: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the number of points in a collection.
 func (c *Client) Count(ctx context.Context, collection string) (int64, error) {
 	col, err := c.getCollection(ctx, collection)
@@ -10,8 +10,11 @@ import (
 	"github.com/metawake/ragtune/internal/vectorstore"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Store)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Store)(nil)
+	_ vectorstore.Deleter = (*Store)(nil)
+)
 
 // Store is an in-memory mock implementation of vectorstore.Store.
 // Useful for testing without a real vector database.
@@ -143,6 +146,26 @@ func (s *Store) Search(ctx context.Context, collectionName string, vector []floa
 	return out, nil
 }
 
+// Delete removes points by ID; missing IDs are ignored.
+func (s *Store) Delete(ctx context.Context, collectionName string, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("store is closed")
+	}
+
+	coll, exists := s.collections[collectionName]
+	if !exists {
+		return fmt.Errorf("collection %q does not exist", collectionName)
+	}
+
+	for _, id := range ids {
+		delete(coll.points, id)
+	}
+	return nil
+}
+
 // Count returns the number of points in a collection.
 func (s *Store) Count(ctx context.Context, collectionName string) (int64, error) {
 	s.mu.RLock()
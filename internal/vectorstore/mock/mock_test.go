@@ -211,6 +211,42 @@ func TestStore_Count(t *testing.T) {
 	}
 }
 
+func TestStore_Delete(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	ctx := context.Background()
+
+	if err := s.EnsureCollection(ctx, "test", 3); err != nil {
+		t.Fatalf("EnsureCollection failed: %v", err)
+	}
+	points := []vectorstore.Point{
+		{ID: "p1", Vector: []float32{1, 0, 0}},
+		{ID: "p2", Vector: []float32{0, 1, 0}},
+		{ID: "p3", Vector: []float32{0, 0, 1}},
+	}
+	if err := s.Upsert(ctx, "test", points); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	// Missing IDs are ignored
+	if err := s.Delete(ctx, "test", []string{"p1", "p3", "missing"}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+
+	remaining, err := s.GetPoints("test")
+	if err != nil {
+		t.Fatalf("GetPoints failed: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "p2" {
+		t.Errorf("expected only p2 to remain, got %v", remaining)
+	}
+
+	if err := s.Delete(ctx, "nonexistent", []string{"p2"}); err == nil {
+		t.Error("expected error deleting from nonexistent collection")
+	}
+}
+
 func TestStore_Close(t *testing.T) {
 	s := New()
 
@@ -14,8 +14,11 @@ import (
 	pgvecpgx "github.com/pgvector/pgvector-go/pgx"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Client)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Client)(nil)
+	_ vectorstore.Deleter = (*Client)(nil)
+)
 
 // Client implements vectorstore.Store for PostgreSQL with pgvector extension.
 type Client struct {
@@ -145,6 +148,20 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 	return nil
 }
 
+// Delete removes points by ID.
+func (c *Client) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = ANY($1)", tableName(collection))
+	if _, err := c.pool.Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+
+	return nil
+}
+
 // Search performs similarity search and returns top-k results.
 // Uses cosine distance (1 - cosine_similarity) for ranking.
 func (c *Client) Search(ctx context.Context, collection string, vector []float32, topK int) ([]vectorstore.Result, error) {
@@ -14,8 +14,11 @@ import (
 	"github.com/metawake/ragtune/internal/vectorstore"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Client)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Client)(nil)
+	_ vectorstore.Deleter = (*Client)(nil)
+)
 
 // Client implements vectorstore.Store for Pinecone.
 type Client struct {
@@ -101,6 +104,30 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 	return nil
 }
 
+// Delete removes vectors by ID from the namespace, in batches of 1000.
+func (c *Client) Delete(ctx context.Context, collection string, ids []string) error {
+	// Pinecone accepts up to 1000 IDs per delete
+	batchSize := 1000
+	for i := 0; i < len(ids); i += batchSize {
+		end := i + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		body := deleteRequest{
+			IDs:       ids[i:end],
+			Namespace: collection,
+		}
+
+		_, err := c.doRequest(ctx, "POST", "/vectors/delete", body)
+		if err != nil {
+			return fmt.Errorf("delete failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // Search performs similarity search and returns top-k results.
 func (c *Client) Search(ctx context.Context, collection string, vector []float32, topK int) ([]vectorstore.Result, error) {
 	body := queryRequest{
@@ -248,8 +275,9 @@ type queryResponse struct {
 }
 
 type deleteRequest struct {
-	DeleteAll bool   `json:"deleteAll"`
-	Namespace string `json:"namespace,omitempty"`
+	IDs       []string `json:"ids,omitempty"`
+	DeleteAll bool     `json:"deleteAll,omitempty"`
+	Namespace string   `json:"namespace,omitempty"`
 }
 
 type indexStats struct {
@@ -11,8 +11,11 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Client)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Client)(nil)
+	_ vectorstore.Deleter = (*Client)(nil)
+)
 
 // Client implements vectorstore.Store for Qdrant.
 type Client struct {
@@ -109,6 +112,36 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 	return nil
 }
 
+// Delete removes points by ID, in batches of 100.
+func (c *Client) Delete(ctx context.Context, collection string, ids []string) error {
+	batchSize := 100
+	for i := 0; i < len(ids); i += batchSize {
+		end := i + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		pbIDs := make([]*pb.PointId, 0, end-i)
+		for _, id := range ids[i:end] {
+			pbIDs = append(pbIDs, &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: id}})
+		}
+
+		_, err := c.points.Delete(ctx, &pb.DeletePoints{
+			CollectionName: collection,
+			Wait:           boolPtr(true),
+			Points: &pb.PointsSelector{
+				PointsSelectorOneOf: &pb.PointsSelector_Points{
+					Points: &pb.PointsIdsList{Ids: pbIDs},
+				},
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete batch starting at %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
 // Search performs similarity search and returns top-k results.
 func (c *Client) Search(ctx context.Context, collection string, vector []float32, topK int) ([]vectorstore.Result, error) {
 	resp, err := c.points.Search(ctx, &pb.SearchPoints{
@@ -209,6 +242,22 @@ func toQdrantValue(v interface{}) *pb.Value {
 		return &pb.Value{Kind: &pb.Value_DoubleValue{DoubleValue: val}}
 	case bool:
 		return &pb.Value{Kind: &pb.Value_BoolValue{BoolValue: val}}
+	case nil:
+		return &pb.Value{Kind: &pb.Value_NullValue{}}
+	case []string:
+		values := make([]*pb.Value, len(val))
+		for i, item := range val {
+			values[i] = toQdrantValue(item)
+		}
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.ListValue{Values: values}}}
+	case []interface{}:
+		values := make([]*pb.Value, len(val))
+		for i, item := range val {
+			values[i] = toQdrantValue(item)
+		}
+		return &pb.Value{Kind: &pb.Value_ListValue{ListValue: &pb.ListValue{Values: values}}}
+	case map[string]interface{}:
+		return &pb.Value{Kind: &pb.Value_StructValue{StructValue: &pb.Struct{Fields: toQdrantPayload(val)}}}
 	default:
 		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: fmt.Sprintf("%v", val)}}
 	}
@@ -238,6 +287,14 @@ func fromQdrantValue(v *pb.Value) interface{} {
 		return val.DoubleValue
 	case *pb.Value_BoolValue:
 		return val.BoolValue
+	case *pb.Value_ListValue:
+		items := make([]interface{}, len(val.ListValue.GetValues()))
+		for i, item := range val.ListValue.GetValues() {
+			items[i] = fromQdrantValue(item)
+		}
+		return items
+	case *pb.Value_StructValue:
+		return fromQdrantPayload(val.StructValue.GetFields())
 	default:
 		return nil
 	}
@@ -0,0 +1,30 @@
+package qdrant
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPayloadRoundTrip(t *testing.T) {
+	payload := map[string]interface{}{
+		"text":     "body",
+		"chunk_id": 3,
+		"score":    0.5,
+		"draft":    false,
+		"tags":     []interface{}{"auth", "api"},
+		"owner":    map[string]interface{}{"team": "platform"},
+	}
+	got := fromQdrantPayload(toQdrantPayload(payload))
+
+	want := map[string]interface{}{
+		"text":     "body",
+		"chunk_id": int64(3),
+		"score":    0.5,
+		"draft":    false,
+		"tags":     []interface{}{"auth", "api"},
+		"owner":    map[string]interface{}{"team": "platform"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round trip = %v, want %v", got, want)
+	}
+}
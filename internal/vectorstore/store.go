@@ -43,6 +43,13 @@ type Store interface {
 	Close() error
 }
 
+// Deleter is implemented by stores that can remove individual points.
+// Incremental ingest uses it to drop chunks of changed or removed documents.
+type Deleter interface {
+	// Delete removes the points with the given IDs. Missing IDs are ignored.
+	Delete(ctx context.Context, collection string, ids []string) error
+}
+
 // Point represents a vector with metadata to be stored.
 type Point struct {
 	ID      string
@@ -0,0 +1,80 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Compile-time interface compliance checks.
+var (
+	_ Store   = (*timeoutStore)(nil)
+	_ Deleter = (*timeoutStore)(nil)
+)
+
+// timeoutStore bounds each call to the wrapped store with a timeout.
+type timeoutStore struct {
+	inner   Store
+	timeout time.Duration
+}
+
+// WithTimeout returns s with each call limited to timeout, so a hung
+// backend fails the call instead of blocking forever. A timeout of zero or
+// less returns s unchanged. Close is not limited.
+func WithTimeout(s Store, timeout time.Duration) Store {
+	if timeout <= 0 {
+		return s
+	}
+	return &timeoutStore{inner: s, timeout: timeout}
+}
+
+// EnsureCollection creates a collection if it doesn't exist.
+func (s *timeoutStore) EnsureCollection(ctx context.Context, name string, dim int) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.inner.EnsureCollection(ctx, name, dim)
+}
+
+// Upsert inserts or updates points in a collection.
+func (s *timeoutStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.inner.Upsert(ctx, collection, points)
+}
+
+// Search performs similarity search and returns top-k results.
+func (s *timeoutStore) Search(ctx context.Context, collection string, vector []float32, topK int) ([]Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.inner.Search(ctx, collection, vector, topK)
+}
+
+// Count returns the number of points in a collection.
+func (s *timeoutStore) Count(ctx context.Context, collection string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.inner.Count(ctx, collection)
+}
+
+// DeleteCollection removes a collection and all its data.
+func (s *timeoutStore) DeleteCollection(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.inner.DeleteCollection(ctx, name)
+}
+
+// Delete removes points by ID if the wrapped store is a Deleter.
+func (s *timeoutStore) Delete(ctx context.Context, collection string, ids []string) error {
+	d, ok := s.inner.(Deleter)
+	if !ok {
+		return fmt.Errorf("store does not support deleting points")
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return d.Delete(ctx, collection, ids)
+}
+
+// Close releases the wrapped store's resources.
+func (s *timeoutStore) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,60 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// hangingStore blocks every call until its context is done, like a stalled backend.
+type hangingStore struct{}
+
+func (hangingStore) EnsureCollection(ctx context.Context, name string, dim int) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (hangingStore) Upsert(ctx context.Context, collection string, points []Point) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (hangingStore) Search(ctx context.Context, collection string, vector []float32, topK int) ([]Result, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (hangingStore) Count(ctx context.Context, collection string) (int64, error) {
+	<-ctx.Done()
+	return 0, ctx.Err()
+}
+
+func (hangingStore) DeleteCollection(ctx context.Context, name string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (hangingStore) Close() error {
+	return nil
+}
+
+func TestWithTimeout(t *testing.T) {
+	if _, ok := WithTimeout(hangingStore{}, 0).(hangingStore); !ok {
+		t.Error("zero timeout should return the store unchanged")
+	}
+
+	store := WithTimeout(hangingStore{}, 10*time.Millisecond)
+	ctx := context.Background()
+	if err := store.Upsert(ctx, "docs", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Upsert err = %v, want deadline exceeded", err)
+	}
+	if _, err := store.Search(ctx, "docs", []float32{1}, 5); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Search err = %v, want deadline exceeded", err)
+	}
+
+	// hangingStore cannot delete points, and the wrapper says so
+	if err := store.(Deleter).Delete(ctx, "docs", []string{"a"}); err == nil {
+		t.Error("Delete should fail when the wrapped store is not a Deleter")
+	}
+}
@@ -9,18 +9,57 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/metawake/ragtune/internal/vectorstore"
 )
 
-// Compile-time interface compliance check.
-var _ vectorstore.Store = (*Client)(nil)
+// Compile-time interface compliance checks.
+var (
+	_ vectorstore.Store   = (*Client)(nil)
+	_ vectorstore.Deleter = (*Client)(nil)
+)
 
 // Client implements vectorstore.Store for Weaviate using REST API.
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+
+	mu     sync.Mutex
+	fields map[string][]string // Class name -> properties known to exist
+}
+
+// payloadProperty maps a payload key to a Weaviate class property.
+type payloadProperty struct {
+	key      string // Payload key
+	name     string // Property name
+	dataType string // "text", "text[]" or "int"
+}
+
+// payloadProperties are the payload keys persisted as class properties.
+// chunk_id is stored as chunk_index, the property name earlier releases
+// created. Other payload keys are not stored.
+var payloadProperties = []payloadProperty{
+	{"source", "source", "text"},
+	{"text", "text", "text"},
+	{"chunk_id", "chunk_index", "int"},
+	{"heading_path", "heading_path", "text"},
+	{"symbol", "symbol", "text"},
+	{"title", "title", "text"},
+	{"doc_hash", "doc_hash", "text"},
+	{"start_offset", "start_offset", "int"},
+	{"end_offset", "end_offset", "int"},
+	{"start_line", "start_line", "int"},
+	{"end_line", "end_line", "int"},
+	{"chunker", "chunker", "text"},
+	{"chunk_size", "chunk_size", "int"},
+	{"chunk_overlap", "chunk_overlap", "int"},
+	{"parent_id", "parent_id", "text"},
+	{"parent_text", "parent_text", "text"},
+	{"parent_size", "parent_size", "int"},
+	{"enrich_template", "enrich_template", "text"},
+	{"sources", "sources", "text[]"},
 }
 
 // New creates a new Weaviate client.
@@ -33,6 +72,7 @@ func New(ctx context.Context, host string, scheme string) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		fields: make(map[string][]string),
 	}
 
 	// Test connection
@@ -66,27 +106,28 @@ func className(collection string) string {
 }
 
 // EnsureCollection creates a class for the collection if it doesn't exist.
+// Classes created by earlier releases gain any missing payload properties.
 func (c *Client) EnsureCollection(ctx context.Context, name string, dim int) error {
 	class := className(name)
 
 	// Check if class exists
-	_, err := c.doRequest(ctx, "GET", "/v1/schema/"+class, nil)
+	existing, err := c.classFields(ctx, class)
 	if err == nil {
-		return nil // Class exists
+		return c.addMissingProperties(ctx, class, existing)
 	}
 
 	// Create class
+	properties := make([]map[string]interface{}, len(payloadProperties))
+	for i, p := range payloadProperties {
+		properties[i] = map[string]interface{}{"name": p.name, "dataType": []string{p.dataType}}
+	}
 	classObj := map[string]interface{}{
 		"class":       class,
 		"description": "RagTune collection: " + name,
 		"vectorIndexConfig": map[string]interface{}{
 			"distance": "cosine",
 		},
-		"properties": []map[string]interface{}{
-			{"name": "source", "dataType": []string{"text"}},
-			{"name": "text", "dataType": []string{"text"}},
-			{"name": "chunk_index", "dataType": []string{"int"}},
-		},
+		"properties": properties,
 	}
 
 	_, err = c.doRequest(ctx, "POST", "/v1/schema", classObj)
@@ -94,9 +135,62 @@ func (c *Client) EnsureCollection(ctx context.Context, name string, dim int) err
 		return fmt.Errorf("failed to create class: %w", err)
 	}
 
+	c.setFields(class, propertyNames(payloadProperties))
+	return nil
+}
+
+// addMissingProperties adds payload properties that an existing class lacks.
+func (c *Client) addMissingProperties(ctx context.Context, class string, existing []string) error {
+	fields := append([]string(nil), existing...)
+	for _, p := range payloadProperties {
+		if containsString(existing, p.name) {
+			continue
+		}
+		prop := map[string]interface{}{"name": p.name, "dataType": []string{p.dataType}}
+		if _, err := c.doRequest(ctx, "POST", "/v1/schema/"+class+"/properties", prop); err != nil {
+			return fmt.Errorf("failed to add property %s: %w", p.name, err)
+		}
+		fields = append(fields, p.name)
+	}
+	c.setFields(class, fields)
 	return nil
 }
 
+// classFields returns the property names of a class, fetching the schema
+// on first use. It fails if the class does not exist.
+func (c *Client) classFields(ctx context.Context, class string) ([]string, error) {
+	c.mu.Lock()
+	fields, ok := c.fields[class]
+	c.mu.Unlock()
+	if ok {
+		return fields, nil
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", "/v1/schema/"+class, nil)
+	if err != nil {
+		return nil, err
+	}
+	var schema struct {
+		Properties []struct {
+			Name string `json:"name"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(respBody, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	for _, p := range schema.Properties {
+		fields = append(fields, p.Name)
+	}
+	c.setFields(class, fields)
+	return fields, nil
+}
+
+func (c *Client) setFields(class string, fields []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fields[class] = fields
+}
+
 // Upsert inserts or updates points in a collection.
 func (c *Client) Upsert(ctx context.Context, collection string, points []vectorstore.Point) error {
 	if len(points) == 0 {
@@ -108,23 +202,10 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 	// Batch insert
 	objects := make([]map[string]interface{}, len(points))
 	for i, p := range points {
-		props := map[string]interface{}{}
-		if p.Payload != nil {
-			if source, ok := p.Payload["source"].(string); ok {
-				props["source"] = source
-			}
-			if text, ok := p.Payload["text"].(string); ok {
-				props["text"] = text
-			}
-			if idx, ok := p.Payload["chunk_index"].(int); ok {
-				props["chunk_index"] = idx
-			}
-		}
-
 		objects[i] = map[string]interface{}{
 			"class":      class,
 			"id":         p.ID,
-			"properties": props,
+			"properties": toProperties(p.Payload),
 			"vector":     p.Vector,
 		}
 	}
@@ -145,17 +226,27 @@ func (c *Client) Upsert(ctx context.Context, collection string, points []vectors
 func (c *Client) Search(ctx context.Context, collection string, vector []float32, topK int) ([]vectorstore.Result, error) {
 	class := className(collection)
 
+	// Only request payload properties the class has; older classes lack some
+	fields, err := c.classFields(ctx, class)
+	if err != nil {
+		return nil, fmt.Errorf("collection not found: %w", err)
+	}
+	var selected []string
+	for _, p := range payloadProperties {
+		if containsString(fields, p.name) {
+			selected = append(selected, p.name)
+		}
+	}
+
 	// GraphQL query for nearVector search
 	query := fmt.Sprintf(`{
 		Get {
 			%s(nearVector: {vector: %s}, limit: %d) {
 				_additional { id distance }
-				source
-				text
-				chunk_index
+				%s
 			}
 		}
-	}`, class, vectorToJSON(vector), topK)
+	}`, class, vectorToJSON(vector), topK, strings.Join(selected, "\n\t\t\t\t"))
 
 	body := map[string]interface{}{
 		"query": query,
@@ -184,36 +275,50 @@ func (c *Client) Search(ctx context.Context, collection string, vector []float32
 	for i, item := range items {
 		distance := float32(0)
 		id := ""
-		if item.Additional != nil {
-			if d, ok := item.Additional["distance"].(float64); ok {
+		if additional, ok := item["_additional"].(map[string]interface{}); ok {
+			if d, ok := additional["distance"].(float64); ok {
 				distance = float32(d)
 			}
-			if idVal, ok := item.Additional["id"].(string); ok {
+			if idVal, ok := additional["id"].(string); ok {
 				id = idVal
 			}
 		}
 
-		payload := map[string]interface{}{}
-		if item.Source != "" {
-			payload["source"] = item.Source
-		}
-		if item.Text != "" {
-			payload["text"] = item.Text
-		}
-		if item.ChunkIndex != 0 {
-			payload["chunk_index"] = item.ChunkIndex
-		}
-
 		results[i] = vectorstore.Result{
 			ID:      id,
 			Score:   1 - distance, // Convert distance to similarity
-			Payload: payload,
+			Payload: fromProperties(item),
 		}
 	}
 
 	return results, nil
 }
 
+// Delete removes objects by ID with a batch delete matching their IDs.
+func (c *Client) Delete(ctx context.Context, collection string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"match": map[string]interface{}{
+			"class": className(collection),
+			"where": map[string]interface{}{
+				"path":           []string{"id"},
+				"operator":       "ContainsAny",
+				"valueTextArray": ids,
+			},
+		},
+	}
+
+	_, err := c.doRequest(ctx, "DELETE", "/v1/batch/objects", body)
+	if err != nil {
+		return fmt.Errorf("batch delete failed: %w", err)
+	}
+
+	return nil
+}
+
 // Count returns the number of objects in a collection.
 func (c *Client) Count(ctx context.Context, collection string) (int64, error) {
 	class := className(collection)
@@ -260,6 +365,9 @@ func (c *Client) Count(ctx context.Context, collection string) (int64, error) {
 // DeleteCollection removes a class and all its data.
 func (c *Client) DeleteCollection(ctx context.Context, name string) error {
 	class := className(name)
+	c.mu.Lock()
+	delete(c.fields, class)
+	c.mu.Unlock()
 	_, err := c.doRequest(ctx, "DELETE", "/v1/schema/"+class, nil)
 	if err != nil {
 		// Ignore not found errors
@@ -314,6 +422,105 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	return respBody, nil
 }
 
+// toProperties converts a payload to class properties. Integers may arrive
+// as int, int64 or float64 depending on where the payload came from.
+func toProperties(payload map[string]interface{}) map[string]interface{} {
+	props := map[string]interface{}{}
+	for _, p := range payloadProperties {
+		v, ok := payload[p.key]
+		if !ok {
+			continue
+		}
+		switch p.dataType {
+		case "int":
+			if n, ok := toInt(v); ok {
+				props[p.name] = n
+			}
+		case "text[]":
+			if list := toStrings(v); len(list) > 0 {
+				props[p.name] = list
+			}
+		default:
+			if s, ok := v.(string); ok {
+				props[p.name] = s
+			}
+		}
+	}
+	return props
+}
+
+// fromProperties converts a GraphQL result object back to a payload.
+func fromProperties(item map[string]interface{}) map[string]interface{} {
+	payload := map[string]interface{}{}
+	for _, p := range payloadProperties {
+		v, ok := item[p.name]
+		if !ok || v == nil {
+			continue
+		}
+		switch p.dataType {
+		case "int":
+			if n, ok := toInt(v); ok {
+				payload[p.key] = n
+			}
+		case "text[]":
+			if list := toStrings(v); len(list) > 0 {
+				payload[p.key] = list
+			}
+		default:
+			if s, ok := v.(string); ok && s != "" {
+				payload[p.key] = s
+			}
+		}
+	}
+	return payload
+}
+
+// toStrings converts a list of strings, decoded from JSON or not.
+func toStrings(v interface{}) []string {
+	switch list := v.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	}
+	return 0, false
+}
+
+func propertyNames(props []payloadProperty) []string {
+	names := make([]string, len(props))
+	for i, p := range props {
+		names[i] = p.name
+	}
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 func vectorToJSON(vec []float32) string {
 	parts := make([]string, len(vec))
 	for i, v := range vec {
@@ -326,16 +533,9 @@ func vectorToJSON(vec []float32) string {
 
 type graphQLResponse struct {
 	Data struct {
-		Get map[string][]searchResult `json:"Get"`
+		Get map[string][]map[string]interface{} `json:"Get"`
 	} `json:"data"`
 	Errors []struct {
 		Message string `json:"message"`
 	} `json:"errors"`
 }
-
-type searchResult struct {
-	Additional map[string]interface{} `json:"_additional"`
-	Source     string                 `json:"source"`
-	Text       string                 `json:"text"`
-	ChunkIndex int                    `json:"chunk_index"`
-}
@@ -1,6 +1,13 @@
 package weaviate
 
-import "testing"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
 
 func TestClassName(t *testing.T) {
 	tests := []struct {
@@ -53,3 +60,100 @@ func TestVectorToJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestPropertiesRoundTrip(t *testing.T) {
+	payload := map[string]interface{}{
+		"text":       "body",
+		"source":     "docs/auth.md",
+		"chunk_id":   0,
+		"start_line": int64(120),
+		"end_line":   float64(141),
+		"doc_hash":   "abc",
+		"sources":    []string{"docs/auth.md", "docs/keys.md"},
+		"unknown":    "dropped",
+	}
+
+	props := toProperties(payload)
+	if props["chunk_index"] != 0 {
+		t.Errorf("chunk_id should be stored as chunk_index, got %v", props)
+	}
+	if _, ok := props["unknown"]; ok {
+		t.Error("unknown payload keys should not be stored")
+	}
+	if list, ok := props["sources"].([]string); !ok || len(list) != 2 {
+		t.Errorf("sources should be stored as a text array, got %v", props["sources"])
+	}
+
+	// GraphQL returns numbers as float64
+	item := map[string]interface{}{
+		"_additional": map[string]interface{}{"id": "x"},
+		"text":        "body",
+		"source":      "docs/auth.md",
+		"chunk_index": float64(0),
+		"start_line":  float64(120),
+		"end_line":    float64(141),
+		"doc_hash":    "abc",
+		"sources":     []interface{}{"docs/auth.md", "docs/keys.md"},
+		"symbol":      nil,
+	}
+	got := fromProperties(item)
+	want := map[string]interface{}{
+		"text":       "body",
+		"source":     "docs/auth.md",
+		"chunk_id":   0,
+		"start_line": 120,
+		"end_line":   141,
+		"doc_hash":   "abc",
+	}
+	if list, ok := got["sources"].([]string); !ok || len(list) != 2 || list[1] != "docs/keys.md" {
+		t.Errorf("payload[sources] = %v, want both sources", got["sources"])
+	}
+	delete(got, "sources")
+	if len(got) != len(want) {
+		t.Fatalf("fromProperties() = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("payload[%q] = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestEnsureCollection_AddsMissingProperties(t *testing.T) {
+	var added []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/schema/Ragtune_Old":
+			// A class created before provenance properties existed
+			fmt.Fprint(w, `{"class":"Ragtune_Old","properties":[{"name":"source"},{"name":"text"},{"name":"chunk_index"}]}`)
+		case r.Method == "POST" && r.URL.Path == "/v1/schema/Ragtune_Old/properties":
+			var prop struct {
+				Name string `json:"name"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&prop)
+			added = append(added, prop.Name)
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &Client{baseURL: srv.URL, httpClient: srv.Client(), fields: make(map[string][]string)}
+	if err := c.EnsureCollection(context.Background(), "old", 4); err != nil {
+		t.Fatalf("EnsureCollection failed: %v", err)
+	}
+
+	if len(added) != len(payloadProperties)-3 {
+		t.Errorf("added %d properties, want %d: %v", len(added), len(payloadProperties)-3, added)
+	}
+	for _, name := range []string{"source", "text", "chunk_index"} {
+		if containsString(added, name) {
+			t.Errorf("existing property %s was added again", name)
+		}
+	}
+	fields, _ := c.classFields(context.Background(), "Ragtune_Old")
+	if len(fields) != len(payloadProperties) {
+		t.Errorf("cached %d fields, want %d", len(fields), len(payloadProperties))
+	}
+}